@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fractal-analysis/fractal"
+)
+
+// Series lengths timed by -benchmark; lengths beyond the data are skipped.
+var benchSizes = []int{500, 5000, 50000}
+
+// Times each estimator on prefixes of data with testing.Benchmark and
+// prints the per-call cost next to the estimate, so speed can be weighed
+// against accuracy. Inputs are sliced before the timer starts.
+func runBenchmarks(data []fractal.MarketCandle, counter fractal.BoxCounter) {
+	prices := fractal.SeriesPrice.Values(data)
+	returns := fractal.SeriesReturns.Values(data)
+
+	estimators := []struct {
+		name   string
+		series []float64
+		fn     func([]float64) float64
+	}{
+		{"box", prices, func(s []float64) float64 { d, _ := counter.Fit(s); return d }},
+		{"higuchi", prices, fractal.HiguchiFractalDimension},
+		{"katz", prices, fractal.KatzFractalDimension},
+		{"petrosian", prices, fractal.PetrosianFractalDimension},
+		{"variogram", prices, fractal.VariogramFractalDimension},
+		{"wavelet", prices, fractal.WaveletFractalDimension},
+		{"dfa", returns, func(s []float64) float64 { return fractal.DFA(s, 1) }},
+	}
+
+	for _, size := range benchSizes {
+		if size > len(data) {
+			fmt.Printf("Go: Skipping n=%d, only %d candles\n", size, len(data))
+			continue
+		}
+		for _, e := range estimators {
+			series := e.series[:size]
+			var value float64
+			res := testing.Benchmark(func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					value = e.fn(series)
+				}
+			})
+			perOp := time.Duration(res.NsPerOp())
+			fmt.Printf("Go: %-9s n=%-6d %12v/op  value %.3f\n", e.name, size, perOp, value)
+		}
+	}
+}
@@ -10,7 +10,7 @@ if %errorlevel% neq 0 (
 )
 
 echo Building...
-go build -o fractal-analysis.exe main.go
+go build -o fractal-analysis.exe .
 if %errorlevel% neq 0 (
     echo Build failed
     pause
@@ -18,6 +18,6 @@ if %errorlevel% neq 0 (
 )
 
 echo Running...
-fractal-analysis.exe
+fractal-analysis.exe -v
 
 pause
\ No newline at end of file
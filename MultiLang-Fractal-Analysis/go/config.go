@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fractal-analysis/fractal"
+)
+
+// Run settings loaded by -config from a JSON object. Zero values and absent keys leave the flag defaults;
+// Seed and Gzip are pointers so that 0 and false can be given explicitly.
+type Config struct {
+	Generator     string     `json:"generator"`
+	N             int        `json:"n"`
+	Seed          *int64     `json:"seed"`
+	Windows       stringList `json:"windows"`
+	RollingWindow int        `json:"rolling_window"`
+	RollingStep   int        `json:"rolling_step"`
+	BoxSizes      stringList `json:"box_sizes"`
+	Methods       stringList `json:"methods"`
+	OutDir        string     `json:"outdir"`
+	Format        stringList `json:"format"`
+	Gzip          *bool      `json:"gzip"`
+}
+
+// A list given either as one comma-separated string or as an array.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(raw []byte) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		*l = nil
+		if s != "" {
+			*l = stringList{s}
+		}
+		return nil
+	}
+	var items []any
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return fmt.Errorf("want a string or a list, got %s", raw)
+	}
+	*l = make(stringList, len(items))
+	for i, item := range items {
+		(*l)[i] = fmt.Sprint(item)
+	}
+	return nil
+}
+
+func (l stringList) String() string { return strings.Join(l, ",") }
+
+// Reads a Config from the JSON file filename. Unknown keys are errors so
+// that typos don't pass silently.
+func loadConfig(filename string) (Config, error) {
+	var cfg Config
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".yaml", ".yml":
+		return cfg, fmt.Errorf("%s: %s files are not supported, write the config as JSON", filename, ext)
+	}
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return cfg, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.validate()
+}
+
+// Checks each setting the way the matching flag would be checked.
+func (c Config) validate() error {
+	var errs []error
+	if c.Generator != "" {
+		switch c.Generator {
+		case "fractal", "gbm", "fbm", "ou", "merton":
+		default:
+			errs = append(errs, fmt.Errorf("unknown generator %q (want fractal, gbm, fbm, ou or merton)", c.Generator))
+		}
+	}
+	if c.N < 0 {
+		errs = append(errs, fmt.Errorf("n must be positive, got %d", c.N))
+	}
+	if len(c.Windows) > 0 {
+		if _, err := parseWindows(c.Windows.String(), 0); err != nil {
+			errs = append(errs, fmt.Errorf("windows: %w", err))
+		}
+	}
+	if c.RollingWindow < 0 || c.RollingStep < 0 {
+		errs = append(errs, fmt.Errorf("rolling_window and rolling_step must be positive"))
+	}
+	if _, err := parseBoxSizes(c.BoxSizes.String()); err != nil {
+		errs = append(errs, fmt.Errorf("box_sizes: %w", err))
+	}
+	if _, err := fractal.ParseEstimators(c.Methods.String()); err != nil {
+		errs = append(errs, fmt.Errorf("methods: %w", err))
+	}
+	if len(c.Format) > 0 {
+		if _, err := parseFormats(c.Format.String()); err != nil {
+			errs = append(errs, fmt.Errorf("format: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// The flag values the config sets, keyed by flag name.
+func (c Config) flags() map[string]string {
+	set := map[string]string{}
+	put := func(name, value string) {
+		if value != "" {
+			set[name] = value
+		}
+	}
+	put("generator", c.Generator)
+	if c.N > 0 {
+		put("n", strconv.Itoa(c.N))
+	}
+	if c.Seed != nil {
+		put("seed", strconv.FormatInt(*c.Seed, 10))
+	}
+	put("windows", c.Windows.String())
+	if c.RollingWindow > 0 {
+		put("rolling-window", strconv.Itoa(c.RollingWindow))
+	}
+	if c.RollingStep > 0 {
+		put("rolling-step", strconv.Itoa(c.RollingStep))
+	}
+	put("box-sizes", c.BoxSizes.String())
+	put("method", c.Methods.String())
+	put("outdir", c.OutDir)
+	put("format", c.Format.String())
+	if c.Gzip != nil {
+		put("gzip", strconv.FormatBool(*c.Gzip))
+	}
+	return set
+}
+
+// Sets the config's flags that weren't given on the command line (or by
+// -replay), so explicit flags always win.
+func applyConfig(c Config) error {
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for name, value := range c.flags() {
+		if given[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("flag -%s: %w", name, err)
+		}
+	}
+	return nil
+}
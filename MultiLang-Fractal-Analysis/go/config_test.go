@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Writes content to name in a fresh temporary directory.
+func writeTemp(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]string // flags the config sets
+		wantErr string
+	}{
+		{
+			name: "every key",
+			file: "run.json",
+			content: `{"generator": "fbm", "n": 500, "seed": 0, "windows": ["0:50", "100:100"],
+				"rolling_window": 60, "rolling_step": 20, "box_sizes": "2,4,8",
+				"methods": ["higuchi", "katz"], "outdir": "out", "format": "csv,json", "gzip": false}`,
+			want: map[string]string{
+				"generator": "fbm", "n": "500", "seed": "0", "windows": "0:50,100:100",
+				"rolling-window": "60", "rolling-step": "20", "box-sizes": "2,4,8",
+				"method": "higuchi,katz", "outdir": "out", "format": "csv,json", "gzip": "false",
+			},
+		},
+		{
+			name:    "absent keys set nothing",
+			file:    "run.json",
+			content: `{"n": 300}`,
+			want:    map[string]string{"n": "300"},
+		},
+		{name: "unknown key", file: "run.json", content: `{"nn": 300}`, wantErr: `unknown field "nn"`},
+		{name: "bad generator", file: "run.json", content: `{"generator": "brownian"}`, wantErr: `unknown generator "brownian"`},
+		{name: "negative n", file: "run.json", content: `{"n": -1}`, wantErr: "n must be positive"},
+		{name: "yaml", file: "run.yaml", content: "n: 300\n", wantErr: "write the config as JSON"},
+		{name: "yml", file: "run.YML", content: "n: 300\n", wantErr: "write the config as JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(writeTemp(t, tt.file, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.flags(); !maps.Equal(got, tt.want) {
+				t.Errorf("flags %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Flags given on the command line win over the config; the rest take its
+// values.
+func TestApplyConfigFlagsWin(t *testing.T) {
+	saved := flag.CommandLine
+	t.Cleanup(func() { flag.CommandLine = saved })
+	flag.CommandLine = flag.NewFlagSet("fa", flag.ContinueOnError)
+	n := flag.Int("n", 1000, "")
+	generator := flag.String("generator", "fractal", "")
+	method := flag.String("method", "", "")
+	if err := flag.CommandLine.Parse([]string{"-n", "250"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(writeTemp(t, "run.json", `{"n": 500, "generator": "ou", "methods": "katz"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if *n != 250 || *generator != "ou" || *method != "katz" {
+		t.Errorf("n %d, generator %q, method %q; want 250, ou, katz", *n, *generator, *method)
+	}
+}
@@ -0,0 +1,43 @@
+package fractal
+
+import "math"
+
+// Autocorrelation returns the sample autocorrelation of series at lags
+// 0..maxLag, capped at len(series)-1. It uses the biased estimator, which
+// divides every lag's autocovariance by n rather than n-lag, so the
+// estimates stay bounded and shrink smoothly at large lags. A constant
+// series has no defined autocorrelation and yields NaN at every lag.
+func Autocorrelation(series []float64, maxLag int) []float64 {
+	n := len(series)
+	if n == 0 || maxLag < 0 {
+		return nil
+	}
+	if maxLag > n-1 {
+		maxLag = n - 1
+	}
+
+	mean := 0.0
+	for _, v := range series {
+		mean += v
+	}
+	mean /= float64(n)
+
+	variance := 0.0
+	for _, v := range series {
+		variance += (v - mean) * (v - mean)
+	}
+
+	acf := make([]float64, maxLag+1)
+	for lag := range acf {
+		if variance == 0 {
+			acf[lag] = math.NaN()
+			continue
+		}
+		cov := 0.0
+		for t := lag; t < n; t++ {
+			cov += (series[t] - mean) * (series[t-lag] - mean)
+		}
+		acf[lag] = cov / variance
+	}
+	return acf
+}
@@ -0,0 +1,58 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAutocorrelation(t *testing.T) {
+	const n = 5000
+	noise := whiteNoise(45, n)
+	acf := Autocorrelation(noise, 10)
+	if len(acf) != 11 || acf[0] != 1 {
+		t.Fatalf("white noise: %v, want 11 lags from 1", acf)
+	}
+	for lag := 1; lag <= 10; lag++ {
+		if math.Abs(acf[lag]) > 3/math.Sqrt(n) {
+			t.Errorf("white noise lag %d: %v, want near 0", lag, acf[lag])
+		}
+	}
+
+	// AR(1) decays geometrically as phi^lag
+	const phi = 0.7
+	ar := make([]float64, n)
+	for i := 1; i < n; i++ {
+		ar[i] = phi*ar[i-1] + noise[i]
+	}
+	acf = Autocorrelation(ar, 8)
+	for lag := range acf {
+		if want := math.Pow(phi, float64(lag)); math.Abs(acf[lag]-want) > 0.05 {
+			t.Errorf("AR(1) lag %d: %v, want %v", lag, acf[lag], want)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		series  []float64
+		maxLag  int
+		wantLen int
+		allNaN  bool
+	}{
+		{"lag capped at n-1", []float64{1, 2, 3}, 10, 3, false},
+		{"constant", []float64{4, 4, 4, 4}, 2, 3, true},
+		{"empty", nil, 2, 0, false},
+		{"negative lag", []float64{1, 2}, -1, 0, false},
+	}
+	for _, tt := range tests {
+		got := Autocorrelation(tt.series, tt.maxLag)
+		if len(got) != tt.wantLen {
+			t.Errorf("%s: %d lags, want %d", tt.name, len(got), tt.wantLen)
+		}
+		for _, v := range got {
+			if math.IsNaN(v) != tt.allNaN {
+				t.Errorf("%s: %v", tt.name, got)
+				break
+			}
+		}
+	}
+}
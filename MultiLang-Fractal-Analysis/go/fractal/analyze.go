@@ -0,0 +1,306 @@
+package fractal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Window is a contiguous range of candles to analyse.
+type Window struct {
+	Start int
+	Size  int
+}
+
+// Series names the candle field a dimension is estimated from.
+type Series string
+
+const (
+	SeriesPrice      Series = "price"
+	SeriesReturns    Series = "returns"
+	SeriesVolatility Series = "volatility"
+)
+
+// ParseSeries validates a series name.
+func ParseSeries(name string) (Series, error) {
+	switch s := Series(name); s {
+	case SeriesPrice, SeriesReturns, SeriesVolatility:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown series %q (want price, returns or volatility)", name)
+}
+
+// Values extracts the series from data; an empty Series selects prices.
+func (s Series) Values(data []MarketCandle) []float64 {
+	values := make([]float64, len(data))
+	for i, c := range data {
+		switch s {
+		case SeriesReturns:
+			values[i] = c.Returns
+		case SeriesVolatility:
+			values[i] = c.Volatility
+		default:
+			values[i] = c.Price
+		}
+	}
+	return values
+}
+
+// Analyzer computes per-window estimates on a bounded pool of workers.
+type Analyzer struct {
+	Counter BoxCounter
+	Workers int    // <= 0 uses runtime.NumCPU()
+	Series  Series // series the dimension is measured on, default price
+	// HighLow measures price windows on each bar's low-high range
+	// (BoxCounter.FitRange) instead of the close.
+	HighLow bool
+	// LacunaritySizes are the gliding-box sizes reported per window; nil
+	// uses DefaultLacunaritySizes.
+	LacunaritySizes []int
+	// EntropyPoints caps the returns, taken from the end of the window,
+	// fed to the O(n²) entropy estimators; <= 0 uses DefaultEntropyPoints.
+	EntropyPoints int
+	// Bootstrap is the number of block-bootstrap replicates behind each
+	// window's dimension interval; 0 skips them.
+	Bootstrap int
+	// BlockSize is the bootstrap block length; <= 0 picks n^(1/3).
+	BlockSize int
+	// Surrogates is the number of phase-randomized surrogates each window's
+	// dimension is tested against; 0 skips the test.
+	Surrogates int
+	// Seed seeds the bootstrap and surrogate replicates, one NewRand
+	// stream each.
+	Seed int64
+	// Estimators are additional dimension methods reported per window in
+	// FractalResult.Methods.
+	Estimators []Estimator
+	// Detrend removes each window's trend from the series its dimension,
+	// lacunarity and Estimators see, and from every bootstrap replicate and
+	// surrogate; returns-based estimates are unchanged.
+	Detrend DetrendMethod
+}
+
+// RunAnalysis analyses windows of data with the default Analyzer settings.
+func RunAnalysis(ctx context.Context, data []MarketCandle, windows []Window) ([]FractalResult, error) {
+	return Analyzer{}.Run(ctx, data, windows)
+}
+
+// Analyze is Run without cancellation.
+func (a Analyzer) Analyze(data []MarketCandle, windows []Window) []FractalResult {
+	results, _ := a.Run(context.Background(), data, windows)
+	return results
+}
+
+// Run computes the dimension and lacunarity of a.Series plus the Hurst
+// exponent, DFA alpha and entropies of returns for each window. Sample and
+// approximate entropy only see the last EntropyPoints returns.
+// Windows starting outside data are skipped and windows running past the
+// end are truncated. Results are sorted by WindowStart, then WindowEnd, so
+// output order does not depend on scheduling. If ctx is cancelled the
+// workers stop at the next box size or window and Run returns ctx.Err()
+// with no results. With Bootstrap set, each window also gets a
+// block-bootstrap interval for its dimension, and with Surrogates a
+// nonlinearity test of it.
+func (a Analyzer) Run(ctx context.Context, data []MarketCandle, windows []Window) ([]FractalResult, error) {
+	results, err := a.run(ctx, windows, func(w Window) (FractalResult, bool, error) {
+		if w.Start < 0 || w.Start >= len(data) || w.Size <= 0 {
+			return FractalResult{}, false, nil
+		}
+		size := w.Size
+		if w.Start+size > len(data) {
+			size = len(data) - w.Start
+		}
+
+		window := data[w.Start : w.Start+size]
+		returns := SeriesReturns.Values(window)
+
+		values := Detrend(a.Series.Values(window), a.Detrend)
+		var fd, r2 float64
+		var degenerate bool
+		var err error
+		if a.HighLow && (a.Series == "" || a.Series == SeriesPrice) {
+			lows := make([]float64, len(window))
+			highs := make([]float64, len(window))
+			for j, c := range window {
+				lows[j], highs[j] = c.Low, c.High
+			}
+			lows, highs = detrendRange(lows, highs, a.Detrend)
+			fd, r2, degenerate, err = a.Counter.fitRange(ctx, lows, highs)
+		} else {
+			fd, r2, degenerate, err = a.Counter.fitRange(ctx, values, values)
+		}
+		if err != nil {
+			return FractalResult{}, false, err
+		}
+
+		recent := returns
+		if limit := a.entropyPoints(); len(recent) > limit {
+			recent = recent[len(recent)-limit:]
+		}
+		tolerance := DefaultEntropyR * stdDev(recent)
+
+		return FractalResult{
+			WindowStart:   w.Start,
+			WindowEnd:     w.Start + size - 1,
+			Dimension:     fd,
+			R2:            r2,
+			Hurst:         HurstRS(returns),
+			HurstAggVar:   HurstAggVar(returns),
+			DFA:           DFA(returns, 1),
+			SampleEntropy: SampleEntropy(recent, DefaultEntropyM, tolerance),
+			ApproxEntropy: ApproximateEntropy(recent, DefaultEntropyM, tolerance),
+			PermEntropy:   PermutationEntropy(returns, DefaultPermutationOrder, 1),
+			LempelZiv:     LempelZivComplexity(SeriesPrice.Values(window)),
+			Lacunarity:    LacunarityProfile(values, a.lacunaritySizes()),
+			BootstrapMean: math.NaN(),
+			BootstrapP05:  math.NaN(),
+			BootstrapP95:  math.NaN(),
+			SurrogateMean: math.NaN(),
+			SurrogateP:    math.NaN(),
+			Methods:       a.methods(values),
+			Degenerate:    degenerate,
+		}, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if a.Bootstrap > 0 {
+		if err := a.bootstrap(ctx, data, results); err != nil {
+			return nil, err
+		}
+	}
+	if a.Surrogates > 0 {
+		if err := a.surrogates(ctx, data, results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func (a Analyzer) methods(values []float64) []MethodDimension {
+	if len(a.Estimators) == 0 {
+		return nil
+	}
+	out := make([]MethodDimension, len(a.Estimators))
+	for i, e := range a.Estimators {
+		d, err := e.Dimension(values)
+		if err != nil {
+			d = math.NaN()
+		}
+		out[i] = MethodDimension{e.Name(), d}
+	}
+	return out
+}
+
+func (a Analyzer) lacunaritySizes() []int {
+	if a.LacunaritySizes == nil {
+		return DefaultLacunaritySizes
+	}
+	return a.LacunaritySizes
+}
+
+func (a Analyzer) entropyPoints() int {
+	if a.EntropyPoints <= 0 {
+		return DefaultEntropyPoints
+	}
+	return a.EntropyPoints
+}
+
+// Feeds windows to the worker pool and collects whatever fn accepts, each
+// stamped with the time fn took.
+func (a Analyzer) run(ctx context.Context, windows []Window, fn func(Window) (FractalResult, bool, error)) ([]FractalResult, error) {
+	out, err := pool(ctx, a.Workers, windows, func(w Window) (FractalResult, bool, error) {
+		start := time.Now()
+		r, ok, err := fn(w)
+		r.ComputeMicros = time.Since(start).Microseconds()
+		return r, ok, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].WindowStart != out[j].WindowStart {
+			return out[i].WindowStart < out[j].WindowStart
+		}
+		return out[i].WindowEnd < out[j].WindowEnd
+	})
+	return out, nil
+}
+
+// Runs fn over jobs on up to workers goroutines (<= 0 uses
+// runtime.NumCPU()) and collects, in completion order, the results fn
+// accepts. The first error fn returns stops the remaining jobs and is
+// returned with no results, as errgroup does; jobs still queued when ctx
+// is cancelled are dropped and ctx.Err() is returned.
+func pool[J, R any](ctx context.Context, workers int, jobs []J, fn func(J) (R, bool, error)) ([]R, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	queue := make(chan J)
+	results := make(chan R, workers)
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failed sync.Once
+	var firstErr error
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				if ctx.Err() != nil {
+					continue // drain remaining jobs
+				}
+				r, ok, err := fn(job)
+				if err != nil {
+					failed.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				if ok {
+					results <- r
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(queue)
+			wg.Wait()
+			close(results)
+		}()
+		for _, job := range jobs {
+			select {
+			case queue <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var out []R
+	for r := range results {
+		out = append(out, r)
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
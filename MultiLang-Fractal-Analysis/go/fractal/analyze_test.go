@@ -0,0 +1,120 @@
+package fractal
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolCompletesEveryJob(t *testing.T) {
+	jobs := make([]int, 10000)
+	for i := range jobs {
+		jobs[i] = i
+	}
+	for _, workers := range []int{0, 1, 7, 64} {
+		out, err := pool(context.Background(), workers, jobs, func(j int) (int, bool, error) {
+			return j, j%2 == 0, nil
+		})
+		if err != nil {
+			t.Fatalf("%d workers: %v", workers, err)
+		}
+		if len(out) != len(jobs)/2 {
+			t.Errorf("%d workers: %d results, want %d", workers, len(out), len(jobs)/2)
+		}
+	}
+}
+
+func TestPoolReturnsFirstError(t *testing.T) {
+	jobs := make([]int, 1000)
+	for i := range jobs {
+		jobs[i] = i
+	}
+	failure := errors.New("window failed")
+	var ran atomic.Int64
+	out, err := pool(context.Background(), 4, jobs, func(j int) (int, bool, error) {
+		ran.Add(1)
+		if j == 10 {
+			return 0, false, failure
+		}
+		return j, true, nil
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("got error %v, want %v", err, failure)
+	}
+	if out != nil {
+		t.Errorf("got %d results alongside the error, want none", len(out))
+	}
+	if n := ran.Load(); n == int64(len(jobs)) {
+		t.Error("every job ran after the failure")
+	}
+}
+
+func TestRunManyWindows(t *testing.T) {
+	data := GenerateSeries(NewRand(1, 0), 2000, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	windows := rollingWindows(len(data), 100, 5)
+	results, err := Analyzer{Workers: 8}.Run(context.Background(), data, windows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(windows) {
+		t.Fatalf("got %d results, want %d", len(results), len(windows))
+	}
+	for i, r := range results {
+		if r.WindowStart != windows[i].Start {
+			t.Fatalf("result %d starts at %d, want %d", i, r.WindowStart, windows[i].Start)
+		}
+	}
+}
+
+// Returns centred on zero with both signs normalize like any other series:
+// the dimension is finite, in range, and unchanged when the returns are
+// scaled up and shifted clear of zero.
+func TestRunOnMixedSignReturns(t *testing.T) {
+	noise := whiteNoise(19, 1000)
+	centred, shifted := make([]MarketCandle, len(noise)), make([]MarketCandle, len(noise))
+	for i, v := range noise {
+		centred[i] = MarketCandle{Price: 100, Returns: 0.01 * v}
+		shifted[i] = MarketCandle{Price: 100, Returns: 5 + v}
+	}
+	windows := []Window{{0, 500}, {500, 500}}
+	a := Analyzer{Series: SeriesReturns}
+	results, others := a.Analyze(centred, windows), a.Analyze(shifted, windows)
+	for i, r := range results {
+		if r.Degenerate || math.IsNaN(r.Dimension) || r.Dimension < 0 || r.Dimension > 2 {
+			t.Errorf("window %d: dimension %v, degenerate %v, want a fitted one in [0, 2]", i, r.Dimension, r.Degenerate)
+		}
+		if math.Abs(r.Dimension-others[i].Dimension) > 1e-9 {
+			t.Errorf("window %d: dimension %v, %v once scaled and shifted, want the same", i, r.Dimension, others[i].Dimension)
+		}
+	}
+}
+
+// Cancelling mid-run stops the workers promptly, with the context's error
+// and no partial results.
+func TestRunCancelledMidRun(t *testing.T) {
+	data := GenerateSeries(NewRand(18, 0), 50000, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	windows := rollingWindows(len(data), 2000, 1) // far longer than the test waits
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	results, err := Analyzer{Workers: 4}.Run(ctx, data, windows)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if results != nil {
+		t.Errorf("got %d results after cancelling, want none", len(results))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v to stop", elapsed)
+	}
+
+	if _, _, err := (BoxCounter{}).FitContext(ctx, SeriesPrice.Values(data[:1000])); !errors.Is(err, context.Canceled) {
+		t.Errorf("FitContext on a cancelled context: error %v, want %v", err, context.Canceled)
+	}
+}
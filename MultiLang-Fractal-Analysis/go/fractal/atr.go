@@ -0,0 +1,46 @@
+package fractal
+
+import "math"
+
+// DefaultATRWindow is Wilder's original smoothing period.
+const DefaultATRWindow = 14
+
+// TrueRange returns each candle's true range: the widest of High-Low and
+// the gaps from the previous Close to High and Low. The first candle has
+// no previous close and uses High-Low alone. Candles read without OHLC
+// columns are flat, which reduces this to the close-to-close move.
+func TrueRange(data []MarketCandle) []float64 {
+	tr := make([]float64, len(data))
+	for i, c := range data {
+		tr[i] = c.High - c.Low
+		if i > 0 {
+			prev := data[i-1].Close
+			tr[i] = math.Max(tr[i], math.Max(math.Abs(c.High-prev), math.Abs(c.Low-prev)))
+		}
+	}
+	return tr
+}
+
+// AverageTrueRange returns Wilder's ATR: the mean true range of the first
+// window candles, then ATR_i = (ATR_{i-1}·(window-1) + TR_i) / window.
+// The first window-1 candles get NaN, as does everything if window < 1.
+func AverageTrueRange(data []MarketCandle, window int) []float64 {
+	atr := make([]float64, len(data))
+	for i := range atr {
+		atr[i] = math.NaN()
+	}
+	if window < 1 || len(data) < window {
+		return atr
+	}
+
+	tr := TrueRange(data)
+	seed := 0.0
+	for _, v := range tr[:window] {
+		seed += v
+	}
+	atr[window-1] = seed / float64(window)
+	for i := window; i < len(data); i++ {
+		atr[i] = (atr[i-1]*float64(window-1) + tr[i]) / float64(window)
+	}
+	return atr
+}
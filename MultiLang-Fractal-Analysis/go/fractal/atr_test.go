@@ -0,0 +1,54 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrueRange(t *testing.T) {
+	data := []MarketCandle{
+		{High: 11, Low: 9, Close: 10},
+		{High: 12, Low: 10.5, Close: 12}, // High - prev close 2 beats High-Low 1.5
+		{High: 15, Low: 14, Close: 14.5}, // gap up: High - prev close 3
+		{High: 14, Low: 11, Close: 11},   // High-Low 3, |Low - prev close| 3.5
+	}
+	want := []float64{2, 2, 3, 3.5}
+	for i, got := range TrueRange(data) {
+		if math.Abs(got-want[i]) > 1e-12 {
+			t.Errorf("candle %d: true range %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+// After a volatile start, bars of constant range 2 pull Wilder's ATR to 2.
+func TestAverageTrueRangeConverges(t *testing.T) {
+	data := make([]MarketCandle, 300)
+	for i := range data {
+		spread := 1.0
+		if i < 20 {
+			spread = 5
+		}
+		data[i] = MarketCandle{High: 100 + spread, Low: 100 - spread, Close: 100}
+	}
+	atr := AverageTrueRange(data, DefaultATRWindow)
+	for i := 0; i < DefaultATRWindow-1; i++ {
+		if !math.IsNaN(atr[i]) {
+			t.Fatalf("candle %d: ATR %v before a full window, want NaN", i, atr[i])
+		}
+	}
+	if atr[DefaultATRWindow-1] != 10 {
+		t.Errorf("seed ATR %v, want the mean true range 10", atr[DefaultATRWindow-1])
+	}
+	for i := 21; i < len(data); i++ {
+		if atr[i] > atr[i-1] {
+			t.Fatalf("candle %d: ATR %v rose from %v on a narrower range", i, atr[i], atr[i-1])
+		}
+	}
+	if got := atr[len(atr)-1]; math.Abs(got-2) > 1e-6 {
+		t.Errorf("final ATR %v, want 2", got)
+	}
+
+	if atr := AverageTrueRange(data[:5], DefaultATRWindow); !math.IsNaN(atr[4]) {
+		t.Errorf("fewer candles than the window: ATR %v, want NaN", atr[4])
+	}
+}
@@ -0,0 +1,47 @@
+package fractal
+
+import "math"
+
+// BollingerBands returns the rolling mean of Price over the last window
+// candles, inclusive, and the bands k rolling standard deviations of Price
+// above and below it. That is the spread of the price level, in price
+// units, not the return volatility held in Volatility. The first window-1
+// candles have no full window and get NaN, as does everything if window
+// < 1.
+func BollingerBands(data []MarketCandle, window int, k float64) (mid, upper, lower []float64) {
+	mid = make([]float64, len(data))
+	upper = make([]float64, len(data))
+	lower = make([]float64, len(data))
+	for i := range data {
+		mid[i], upper[i], lower[i] = math.NaN(), math.NaN(), math.NaN()
+	}
+	if window < 1 || len(data) < window {
+		return mid, upper, lower
+	}
+
+	// Sliding mean and sum of squared deviations, as in
+	// ComputeRollingVolatility
+	mean, m2 := 0.0, 0.0
+	for j := 0; j < window; j++ {
+		delta := data[j].Price - mean
+		mean += delta / float64(j+1)
+		m2 += delta * (data[j].Price - mean)
+	}
+
+	for i := window - 1; i < len(data); i++ {
+		if i >= window {
+			in, out := data[i].Price, data[i-window].Price
+			prevMean := mean
+			mean += (in - out) / float64(window)
+			m2 += (in - out) * (in - mean + out - prevMean)
+			if m2 < 0 {
+				m2 = 0
+			}
+		}
+		std := math.Sqrt(m2 / float64(window)) // population std, as Bollinger defined it
+		mid[i] = mean
+		upper[i] = mean + k*std
+		lower[i] = mean - k*std
+	}
+	return mid, upper, lower
+}
@@ -0,0 +1,48 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBollingerBands(t *testing.T) {
+	data := GenerateSeries(NewRand(53, 0), 1000, 100)
+	for _, window := range []int{1, 20, 200} {
+		mid, upper, lower := BollingerBands(data, window, 2)
+		for i := range data {
+			if i < window-1 {
+				if !math.IsNaN(mid[i]) || !math.IsNaN(upper[i]) || !math.IsNaN(lower[i]) {
+					t.Errorf("window %d: candle %d has bands before a full window", window, i)
+				}
+				continue
+			}
+			if !(upper[i] >= mid[i] && mid[i] >= lower[i]) {
+				t.Errorf("window %d: candle %d bands %v >= %v >= %v out of order", window, i, upper[i], mid[i], lower[i])
+			}
+		}
+
+		// The sliding mean and std match the last window recomputed
+		prices := SeriesPrice.Values(data[len(data)-window:])
+		m := mean(prices)
+		m2, _, _ := centralMoments(prices)
+		last := len(data) - 1
+		if math.Abs(mid[last]-m) > 1e-9 || math.Abs(upper[last]-(m+2*math.Sqrt(m2))) > 1e-9 {
+			t.Errorf("window %d: last bands %v, %v, want %v, %v", window, mid[last], upper[last], m, m+2*math.Sqrt(m2))
+		}
+	}
+
+	// Price std, not return volatility: a constant price has zero width
+	flat := make([]MarketCandle, 10)
+	for i := range flat {
+		flat[i].Price = 50
+		flat[i].Volatility = 0.3
+	}
+	mid, upper, lower := BollingerBands(flat, 5, 2)
+	if mid[9] != 50 || upper[9] != 50 || lower[9] != 50 {
+		t.Errorf("constant price: bands %v, %v, %v, want all 50", upper[9], mid[9], lower[9])
+	}
+
+	if mid, _, _ := BollingerBands(flat, 20, 2); !math.IsNaN(mid[9]) {
+		t.Errorf("window longer than the data: mid %v, want NaN", mid[9])
+	}
+}
@@ -0,0 +1,139 @@
+package fractal
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// BlockBootstrap returns a moving-block bootstrap replicate of series:
+// blocks of the given length starting at uniformly drawn offsets, joined
+// and cut to len(series). Keeping blocks contiguous preserves the
+// autocorrelation within them, which plain resampling would destroy.
+// block is clamped to [1, len(series)].
+func BlockBootstrap(rng *rand.Rand, series []float64, block int) []float64 {
+	n := len(series)
+	if n == 0 {
+		return nil
+	}
+	if block < 1 {
+		block = 1
+	}
+	if block > n {
+		block = n
+	}
+
+	out := make([]float64, 0, n+block)
+	for len(out) < n {
+		start := rng.Intn(n - block + 1)
+		out = append(out, series[start:start+block]...)
+	}
+	return out[:n]
+}
+
+// Defaults the block length to n^(1/3), the usual rate for the moving
+// block bootstrap of a variance-like statistic.
+func bootstrapBlock(size, n int) int {
+	if size > 0 {
+		return size
+	}
+	return int(math.Max(1, math.Round(math.Cbrt(float64(n)))))
+}
+
+// One replicate to compute: which result it belongs to and its draw.
+type bootstrapJob struct {
+	result, replicate int
+}
+
+type bootstrapDraw struct {
+	result    int
+	dimension float64
+}
+
+// Applies draw to the increments of a price path and rebuilds the path
+// from its first value, or to values directly when increments is false.
+// A path cannot be resampled itself without tearing it.
+func replicateSeries(values []float64, increments bool, draw func([]float64) []float64) []float64 {
+	if !increments || len(values) < 2 {
+		return draw(values)
+	}
+	steps := make([]float64, len(values)-1)
+	for i := range steps {
+		steps[i] = values[i+1] - values[i]
+	}
+	steps = draw(steps)
+	path := make([]float64, len(values))
+	path[0] = values[0]
+	for i, d := range steps {
+		path[i+1] = path[i] + d
+	}
+	return path
+}
+
+// Fits the dimension of a replicate of window, drawn by draw as
+// replicateSeries describes, the way Run fits the window itself: detrended
+// by a.Detrend and, with HighLow on a price window, box-counted over bars
+// that keep their low and high offsets from the close in place around the
+// replicated closes, since the draws reorder increments, not bars.
+func (a Analyzer) fitReplicate(ctx context.Context, window []MarketCandle, draw func([]float64) []float64) (float64, error) {
+	price := a.Series == "" || a.Series == SeriesPrice
+	replicate := replicateSeries(a.Series.Values(window), price, draw)
+	if !(a.HighLow && price) {
+		d, _, err := a.Counter.FitContext(ctx, Detrend(replicate, a.Detrend))
+		return d, err
+	}
+
+	lows := make([]float64, len(window))
+	highs := make([]float64, len(window))
+	for i, c := range window {
+		lows[i] = replicate[i] + c.Low - c.Price
+		highs[i] = replicate[i] + c.High - c.Price
+	}
+	lows, highs = detrendRange(lows, highs, a.Detrend)
+	d, _, err := a.Counter.FitRangeContext(ctx, lows, highs)
+	return d, err
+}
+
+// Fills the bootstrap fields of results, spreading every window's
+// replicates over the worker pool. Price windows resample their
+// increments and returns and volatility their values, as replicateSeries
+// describes, and each replicate is fitted as fitReplicate does.
+func (a Analyzer) bootstrap(ctx context.Context, data []MarketCandle, results []FractalResult) error {
+	var jobs []bootstrapJob
+	for i := range results {
+		for b := 0; b < a.Bootstrap; b++ {
+			jobs = append(jobs, bootstrapJob{i, b})
+		}
+	}
+
+	draws, err := pool(ctx, a.Workers, jobs, func(job bootstrapJob) (bootstrapDraw, bool, error) {
+		r := results[job.result]
+		rng := NewRand(a.Seed, 1+job.result*a.Bootstrap+job.replicate)
+		d, err := a.fitReplicate(ctx, data[r.WindowStart:r.WindowEnd+1], func(x []float64) []float64 {
+			return BlockBootstrap(rng, x, bootstrapBlock(a.BlockSize, len(x)))
+		})
+		if err != nil {
+			return bootstrapDraw{}, false, err
+		}
+		return bootstrapDraw{job.result, d}, true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	dims := make([][]float64, len(results))
+	for _, d := range draws {
+		dims[d.result] = append(dims[d.result], d.dimension)
+	}
+	for i, ds := range dims {
+		if len(ds) == 0 {
+			continue
+		}
+		sort.Float64s(ds)
+		results[i].BootstrapMean = mean(ds)
+		results[i].BootstrapP05 = Percentile(ds, 5)
+		results[i].BootstrapP95 = Percentile(ds, 95)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package fractal
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// On a GBM path, whose increments the block bootstrap can resample without
+// bias, the 5-95% interval holds the point estimate, and quadrupling B
+// barely moves it once B is in the hundreds.
+func TestBootstrapInterval(t *testing.T) {
+	data := GenerateGBM(NewRand(63, 0), 6000, 100, 0, 0.01)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	windows := []Window{{0, 2000}, {2000, 2000}, {4000, 2000}}
+	run := func(b int) []FractalResult {
+		results, err := Analyzer{Bootstrap: b, Seed: 63}.Run(context.Background(), data, windows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	fewer, more := run(200), run(800)
+	for i, r := range more {
+		if !(r.BootstrapP05 <= r.Dimension && r.Dimension <= r.BootstrapP95) {
+			t.Errorf("window %d: dimension %v outside [%v, %v]", i, r.Dimension, r.BootstrapP05, r.BootstrapP95)
+		}
+		if !(r.BootstrapP05 < r.BootstrapMean && r.BootstrapMean < r.BootstrapP95) {
+			t.Errorf("window %d: mean %v outside [%v, %v]", i, r.BootstrapMean, r.BootstrapP05, r.BootstrapP95)
+		}
+		f := fewer[i]
+		if math.Abs(f.BootstrapP05-r.BootstrapP05) > 0.03 || math.Abs(f.BootstrapP95-r.BootstrapP95) > 0.03 {
+			t.Errorf("window %d: interval [%v, %v] at B 200, [%v, %v] at 800, want it settled", i, f.BootstrapP05, f.BootstrapP95, r.BootstrapP05, r.BootstrapP95)
+		}
+	}
+
+	for _, r := range run(0) {
+		if !math.IsNaN(r.BootstrapMean) || !math.IsNaN(r.BootstrapP05) || !math.IsNaN(r.BootstrapP95) {
+			t.Errorf("no bootstrap: %v, [%v, %v], want NaN", r.BootstrapMean, r.BootstrapP05, r.BootstrapP95)
+		}
+	}
+}
+
+func TestBlockBootstrap(t *testing.T) {
+	series := make([]float64, 100)
+	for i := range series {
+		series[i] = float64(i)
+	}
+	for _, block := range []int{0, 1, 7, 100, 500} {
+		out := BlockBootstrap(NewRand(63, 0), series, block)
+		if len(out) != len(series) {
+			t.Fatalf("block %d: %d values, want %d", block, len(out), len(series))
+		}
+		// Blocks are contiguous: values step by 1 except where a block starts
+		size := max(1, min(block, len(series)))
+		for i := 1; i < len(out); i++ {
+			if i%size != 0 && out[i] != out[i-1]+1 {
+				t.Errorf("block %d: %v follows %v inside a block", block, out[i], out[i-1])
+				break
+			}
+		}
+	}
+	if BlockBootstrap(NewRand(63, 0), nil, 5) != nil {
+		t.Error("empty series: want nil")
+	}
+}
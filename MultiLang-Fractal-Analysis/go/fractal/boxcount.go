@@ -0,0 +1,470 @@
+package fractal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// DefaultBoxSizes is the box-size schedule used when a BoxCounter has none.
+var DefaultBoxSizes = []int{1, 2, 3, 4, 5, 8, 10, 16, 20, 25, 32}
+
+// DefaultMinPointsPerBox is the average load, in points per occupied box,
+// a box size needs to be fitted when a BoxCounter sets none.
+const DefaultMinPointsPerBox = 5.0
+
+// Box sizes a fit keeps however lightly loaded, so the regression always
+// has a few scales to span.
+const minFitSizes = 4
+
+// BoxCounter configures box-counting dimension estimation. The zero value
+// uses DefaultBoxSizes.
+type BoxCounter struct {
+	Sizes []int // box sizes in increasing order
+	// Overlap slides each column of boxes one candle at a time instead of
+	// tiling disjoint columns, so structure split across a column boundary
+	// is still counted: of the bs tilings that start a column at each
+	// offset, the densest is counted, which is never below the disjoint
+	// count. It visits every point twice per size (entering and leaving
+	// the column) plus once more for the tilings' leading partial columns,
+	// each time over the rows it spans, so expect two to three times the
+	// disjoint cost.
+	Overlap bool
+	Slope   SlopeMethod // log-log regression, default SlopeOLS
+	// Normalize picks how prices are scaled onto the grid, default
+	// NormalizeMinMax. ClipQuantile is the tail NormalizeRobust clips at,
+	// DefaultClipQuantile when zero.
+	Normalize    Normalization
+	ClipQuantile float64
+	Impl         BoxImpl // disjoint box bookkeeping, default BoxMap
+	// MinPointsPerBox fits only the box sizes whose occupied boxes hold
+	// at least this many points on average, DefaultMinPointsPerBox when
+	// zero, since at finer scales nearly every point sits in a box of its
+	// own and the count tracks the series length, not its shape. If fewer
+	// than four sizes qualify, the qualifying run is widened by its
+	// neighbours (from the most loaded size when none do).
+	MinPointsPerBox float64
+}
+
+// BoxImpl selects how disjoint box counting records occupied boxes. Both
+// give identical counts.
+type BoxImpl string
+
+const (
+	BoxMap  BoxImpl = "map"  // a set of box keys
+	BoxGrid BoxImpl = "grid" // a flat column-by-row grid, reused across sizes
+)
+
+// ParseBoxImpl validates a box-counting implementation name.
+func ParseBoxImpl(name string) (BoxImpl, error) {
+	switch i := BoxImpl(name); i {
+	case BoxMap, BoxGrid:
+		return i, nil
+	}
+	return "", fmt.Errorf("unknown box implementation %q (want map or grid)", name)
+}
+
+// BoxCountingFractalDimension estimates the fractal dimension of a price
+// path by counting occupied boxes on the min-max normalized series.
+func BoxCountingFractalDimension(prices []float64) float64 {
+	d, _ := BoxCountingFit(prices)
+	return d
+}
+
+// BoxCountingFit is BoxCountingFractalDimension that also returns the R² of
+// the log-log regression, so poorly scaling windows can be discarded.
+// Degenerate inputs report a dimension of 1.0 with R² 0.
+func BoxCountingFit(prices []float64) (dimension, r2 float64) {
+	return BoxCounter{}.Fit(prices)
+}
+
+// Fit estimates the box-counting dimension and its R² with b's settings.
+func (b BoxCounter) Fit(prices []float64) (dimension, r2 float64) {
+	dimension, r2, _ = b.FitContext(context.Background(), prices)
+	return dimension, r2
+}
+
+// FitContext is Fit that checks ctx between box sizes and returns its
+// error if it is cancelled part way through.
+func (b BoxCounter) FitContext(ctx context.Context, prices []float64) (dimension, r2 float64, err error) {
+	dimension, r2, _, err = b.fitRange(ctx, prices, prices)
+	return dimension, r2, err
+}
+
+// FitRange estimates the dimension from each bar's full low-high range
+// instead of a single price: every box the bar spans vertically within its
+// column counts as occupied.
+func (b BoxCounter) FitRange(lows, highs []float64) (dimension, r2 float64) {
+	dimension, r2, _, _ = b.fitRange(context.Background(), lows, highs)
+	return dimension, r2
+}
+
+// FitRangeContext is FitRange with cancellation, as FitContext.
+func (b BoxCounter) FitRangeContext(ctx context.Context, lows, highs []float64) (dimension, r2 float64, err error) {
+	dimension, r2, _, err = b.fitRange(ctx, lows, highs)
+	return dimension, r2, err
+}
+
+// Single-price fitting passes the same slice as lows and highs. degenerate
+// reports that the input gave no slope and 1.0 was substituted.
+func (b BoxCounter) fitRange(ctx context.Context, lows, highs []float64) (dimension, r2 float64, degenerate bool, err error) {
+	if len(lows) < 4 || len(highs) != len(lows) {
+		return 1.0, 0, true, nil
+	}
+
+	normLow, normHigh, ok := b.normalise(lows, highs)
+	if !ok {
+		return 1.0, 0, true, nil
+	}
+
+	counted, err := b.boxCounts(ctx, normLow, normHigh)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	var logInv, logCount []float64
+	for _, c := range b.resolved(counted, len(lows)-1) {
+		logInv = append(logInv, math.Log(1.0/float64(c.size)))
+		logCount = append(logCount, math.Log(c.count))
+	}
+	if len(logInv) < 3 {
+		return 1.0, 0, true, nil
+	}
+
+	dimension, r2, ok = b.Slope.Fit(logInv, logCount)
+	if !ok {
+		return 1.0, 0, true, nil
+	}
+	return dimension, r2, false, nil
+}
+
+// Occupied boxes at each of b's sizes, in size order, over the normalised
+// points; sizes no point occupies are left out.
+func (b BoxCounter) boxCounts(ctx context.Context, normLow, normHigh []float64) ([]boxCount, error) {
+	boxSizes := b.Sizes
+	if len(boxSizes) == 0 {
+		boxSizes = DefaultBoxSizes
+	}
+	var counted []boxCount
+	var grid []bool // BoxGrid cells, grown to the largest size's need
+
+	// Rows spanned by point i; empty for skipped points
+	rows := func(i, bs int) (lo, hi int) {
+		if math.IsNaN(normLow[i]) {
+			return 0, -1
+		}
+		return boxRow(normLow[i], bs), boxRow(normHigh[i], bs)
+	}
+
+	for _, bs := range boxSizes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var count float64
+		switch {
+		case b.Overlap:
+			count = float64(glidingBoxCount(len(normLow)-1, bs, rows))
+		case b.Impl == BoxGrid:
+			m := len(normLow) - 1
+			if cells := (m + bs - 1) / bs * bs; len(grid) < cells {
+				grid = make([]bool, cells)
+			}
+			count = float64(gridBoxCount(grid, m, bs, rows))
+		default:
+			boxes := make(map[int64]struct{})
+			for i := 0; i < len(normLow)-1; i++ {
+				x := i / bs
+				lo, hi := rows(i, bs)
+				for y := lo; y <= hi; y++ {
+					boxes[int64(x)<<32|int64(y)] = struct{}{}
+				}
+			}
+			count = float64(len(boxes))
+		}
+
+		if count > 0 {
+			counted = append(counted, boxCount{bs, count})
+		}
+	}
+	return counted, nil
+}
+
+// Min-max normalizes lows and highs onto [0,1] by their joint range,
+// skipping NaN or infinite points so one bad value can't stretch the
+// range; those keep their column but come back NaN and occupy no box. ok
+// is false when the finite points have no range.
+func normaliseRange(lows, highs []float64) (normLow, normHigh []float64, ok bool) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for i := range lows {
+		if !finitePoint(lows, highs, i) {
+			continue
+		}
+		if lows[i] < min {
+			min = lows[i]
+		}
+		if highs[i] > max {
+			max = highs[i]
+		}
+	}
+	return scaleRange(lows, highs, min, max)
+}
+
+// Maps the band [min, max] onto [0,1], clipping values outside it to the
+// nearest edge, with non-finite points as for normaliseRange.
+func scaleRange(lows, highs []float64, min, max float64) (normLow, normHigh []float64, ok bool) {
+	rang := max - min
+	if !(rang > 0) {
+		return nil, nil, false
+	}
+
+	clip := func(v float64) float64 { return math.Max(0, math.Min(1, v)) }
+	normLow = make([]float64, len(lows))
+	normHigh = make([]float64, len(highs))
+	for i := range lows {
+		if !finitePoint(lows, highs, i) {
+			normLow[i], normHigh[i] = math.NaN(), math.NaN()
+			continue
+		}
+		normLow[i] = clip((lows[i] - min) / rang)
+		normHigh[i] = clip((highs[i] - min) / rang)
+	}
+	return normLow, normHigh, true
+}
+
+// Whether point i has a finite low and high.
+func finitePoint(lows, highs []float64, i int) bool {
+	return !math.IsNaN(lows[i]) && !math.IsInf(lows[i], 0) &&
+		!math.IsNaN(highs[i]) && !math.IsInf(highs[i], 0)
+}
+
+// Row of a normalized value in a grid of bs rows.
+func boxRow(v float64, bs int) int {
+	y := int(v * float64(bs))
+	if y >= bs {
+		y = bs - 1 // the maximum price lands exactly on the top edge
+	}
+	return y
+}
+
+// Counts the boxes of size bs the first m points occupy, with one cell per
+// box of each column of grid, laid out column by column and cleared first.
+func gridBoxCount(grid []bool, m, bs int, rows func(i, bs int) (lo, hi int)) int {
+	cells := grid[:(m+bs-1)/bs*bs]
+	clear(cells)
+	count := 0
+	for i := 0; i < m; i++ {
+		column := cells[i/bs*bs : i/bs*bs+bs]
+		lo, hi := rows(i, bs)
+		for y := lo; y <= hi; y++ {
+			if !column[y] {
+				column[y] = true
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Counts the occupied boxes of each of the bs ways to tile the first m
+// candles with bs-wide columns, the tiling at offset o opening with a
+// partial column of the first o candles, and returns the largest. The
+// column starting at every candle is slid along by tracking per-row
+// occupancy as candles enter and leave it, and each position's count is
+// credited to the tiling it belongs to. Offset 0 is the disjoint tiling
+// over the same points, so the result is never below the disjoint count.
+func glidingBoxCount(m, bs int, rows func(i, bs int) (lo, hi int)) int {
+	tilings := make([]int, bs)
+
+	// The partial column each offset opens with
+	lead := make([]bool, bs)
+	leading := 0
+	for o := 1; o < bs; o++ {
+		if o-1 < m {
+			lo, hi := rows(o-1, bs)
+			for y := lo; y <= hi; y++ {
+				if !lead[y] {
+					lead[y] = true
+					leading++
+				}
+			}
+		}
+		tilings[o] = leading
+	}
+
+	hits := make([]int, bs)
+	occupied := 0
+	update := func(i, delta int) {
+		lo, hi := rows(i, bs)
+		for y := lo; y <= hi; y++ {
+			if delta > 0 && hits[y] == 0 {
+				occupied++
+			}
+			hits[y] += delta
+			if delta < 0 && hits[y] == 0 {
+				occupied--
+			}
+		}
+	}
+
+	for i := 0; i < bs && i < m; i++ {
+		update(i, 1)
+	}
+	for j := 0; j < m; j++ {
+		tilings[j%bs] += occupied
+		update(j, -1)
+		if j+bs < m {
+			update(j+bs, 1)
+		}
+	}
+	return slices.Max(tilings)
+}
+
+// LogSpacedBoxSizes returns up to count geometrically spaced integer box
+// sizes from min to max, rounded and deduplicated so the result is
+// strictly increasing. Invalid bounds yield nil.
+func LogSpacedBoxSizes(min, max, count int) []int {
+	if min < 1 || max < min || count < 1 {
+		return nil
+	}
+	if count == 1 || min == max {
+		return []int{min}
+	}
+
+	ratio := math.Pow(float64(max)/float64(min), 1/float64(count-1))
+	sizes := make([]int, 0, count)
+	for i := 0; i < count; i++ {
+		bs := int(math.Round(float64(min) * math.Pow(ratio, float64(i))))
+		if bs > max {
+			bs = max
+		}
+		if len(sizes) == 0 || bs > sizes[len(sizes)-1] {
+			sizes = append(sizes, bs)
+		}
+	}
+	return sizes
+}
+
+// LinearSlope returns the ordinary least-squares slope of y against x.
+// ok is false, with a zero slope, when the slices differ in length, hold
+// fewer than two points, or x has no spread, so no line is defined.
+func LinearSlope(x, y []float64) (slope float64, ok bool) {
+	if len(x) != len(y) || len(x) < 2 {
+		return 0, false
+	}
+
+	n := float64(len(x))
+	var sx, sy, sxx, sxy float64
+
+	for i := 0; i < len(x); i++ {
+		sx += x[i]
+		sy += y[i]
+		sxx += x[i] * x[i]
+		sxy += x[i] * y[i]
+	}
+
+	d := n*sxx - sx*sx
+	if math.Abs(d) < 1e-12 {
+		return 0, false
+	}
+
+	return (n*sxy - sx*sy) / d, true
+}
+
+// LinearFit returns the least-squares slope of y against x together with
+// the coefficient of determination R² of the fit, with LinearSlope's ok.
+func LinearFit(x, y []float64) (slope, r2 float64, ok bool) {
+	slope, ok = LinearSlope(x, y)
+	if !ok {
+		return 0, 0, false
+	}
+
+	n := float64(len(x))
+	var sx, sy float64
+	for i := range x {
+		sx += x[i]
+		sy += y[i]
+	}
+	intercept := sy/n - slope*sx/n
+	return slope, rSquared(x, y, slope, intercept), true
+}
+
+// Coefficient of determination of the line intercept + slope*x.
+func rSquared(x, y []float64, slope, intercept float64) float64 {
+	my := 0.0
+	for _, v := range y {
+		my += v
+	}
+	my /= float64(len(y))
+
+	var ssRes, ssTot float64
+	for i := range x {
+		res := y[i] - (intercept + slope*x[i])
+		dev := y[i] - my
+		ssRes += res * res
+		ssTot += dev * dev
+	}
+
+	if ssTot < 1e-24 {
+		return 1 // flat data: the fitted line passes through every point
+	}
+	return 1 - ssRes/ssTot
+}
+
+// The occupied boxes counted at one box size.
+type boxCount struct {
+	size  int
+	count float64
+}
+
+// The counts, in size order, that a fit over the m boxed points of an
+// (m+1)-point series uses: the sizes whose boxes average at least
+// MinPointsPerBox points, or DefaultMinPointsPerBox when that is unset,
+// widened by their neighbours to minFitSizes.
+func (b BoxCounter) resolved(counted []boxCount, m int) []boxCount {
+	minPoints := b.MinPointsPerBox
+	if minPoints <= 0 {
+		minPoints = DefaultMinPointsPerBox
+	}
+
+	load := func(i int) float64 { return float64(m) / counted[i].count }
+	keep := make([]bool, len(counted))
+	n := 0
+	for i := range counted {
+		if load(i) >= minPoints {
+			keep[i] = true
+			n++
+		}
+	}
+	if n == 0 && len(counted) > 0 {
+		best := 0
+		for i := range counted {
+			if load(i) > load(best) {
+				best = i
+			}
+		}
+		keep[best] = true
+		n++
+	}
+	for n < minFitSizes && n < len(counted) {
+		// The most loaded size next to one already kept
+		next := -1
+		for i := range counted {
+			beside := (i > 0 && keep[i-1]) || (i+1 < len(counted) && keep[i+1])
+			if !keep[i] && beside && (next < 0 || load(i) > load(next)) {
+				next = i
+			}
+		}
+		keep[next] = true
+		n++
+	}
+
+	kept := make([]boxCount, 0, n)
+	for i, c := range counted {
+		if keep[i] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,381 @@
+package fractal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+// A seeded Gaussian random walk of n points.
+func randomWalk(seed int64, n int) []float64 {
+	rng := NewRand(seed, 0)
+	walk := make([]float64, n)
+	p := 100.0
+	for i := range walk {
+		p += rng.NormFloat64()
+		walk[i] = p
+	}
+	return walk
+}
+
+// The box sizes a fit of series with b uses.
+func fitSizes(t *testing.T, b BoxCounter, series []float64) []int {
+	t.Helper()
+	lows, highs, ok := b.normalise(series, series)
+	if !ok {
+		t.Fatal("series has no range")
+	}
+	counted, err := b.boxCounts(context.Background(), lows, highs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sizes []int
+	for _, c := range b.resolved(counted, len(series)-1) {
+		sizes = append(sizes, c.size)
+	}
+	return sizes
+}
+
+// The box counts of two 9-point series with their maximum at the end,
+// worked by hand. Points 0-7 are counted; the last only closes the final
+// increment. In the first the maximum is point 7, whose normalised 1.0
+// must land in the top row (bs-1) rather than a row bs above the grid,
+// where at size 2 it would add a sixth box.
+func TestBoxCountMaximumAtLastIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []float64
+		want   []boxCount
+	}{
+		{
+			// Rows at size 2: 0 0 | 0 0 | 0 1 | 1 1, the last clamped from 2
+			// Rows at size 4: 0 1 0 1 | 1 2 2 3, the last clamped from 4
+			name:   "last counted point",
+			prices: []float64{0, 2, 1, 3, 2, 4, 5, 8, 5},
+			want:   []boxCount{{1, 8}, {2, 5}, {4, 5}},
+		},
+		{
+			// Rows at size 2: 0 0 | 0 0 | 0 1 | 1 1
+			// Rows at size 4: 0 1 0 1 | 1 2 2 3
+			name:   "final price",
+			prices: []float64{0, 2, 1, 3, 2, 4, 5, 6, 8},
+			want:   []boxCount{{1, 8}, {2, 5}, {4, 5}},
+		},
+	}
+	for _, tt := range tests {
+		for _, impl := range []BoxImpl{BoxMap, BoxGrid} {
+			b := BoxCounter{Sizes: []int{1, 2, 4}, Impl: impl}
+			norm, _, _ := b.normalise(tt.prices, tt.prices)
+			got, err := b.boxCounts(context.Background(), norm, norm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%s, %s: counts %v, want %v", tt.name, impl, got, tt.want)
+			}
+		}
+	}
+}
+
+// The integer box keys count what the original "x,y" string keys did, so
+// the dimension on a fixed series is unchanged.
+func TestBoxKeysMatchStringKeys(t *testing.T) {
+	walk := randomWalk(3, 1000)
+	b := BoxCounter{}
+	norm, _, _ := b.normalise(walk, walk)
+	counted, err := b.boxCounts(context.Background(), norm, norm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stringCounts := map[int]int{}
+	for i, bs := range DefaultBoxSizes {
+		boxes := map[string]bool{}
+		for j := 0; j < len(norm)-1; j++ {
+			boxes[fmt.Sprintf("%d,%d", j/bs, boxRow(norm[j], bs))] = true
+		}
+		if got := counted[i]; got.size != bs || got.count != float64(len(boxes)) {
+			t.Errorf("size %d: count %v, string keys count %d", bs, got.count, len(boxes))
+		}
+		stringCounts[bs] = len(boxes)
+	}
+	var logInv, logCount []float64
+	for _, bs := range fitSizes(t, b, walk) {
+		logInv = append(logInv, math.Log(1.0/float64(bs)))
+		logCount = append(logCount, math.Log(float64(stringCounts[bs])))
+	}
+	want, _ := LinearSlope(logInv, logCount)
+	if got := BoxCountingFractalDimension(walk); got != want {
+		t.Errorf("dimension %v, string keys give %v", got, want)
+	}
+}
+
+// Which of DefaultBoxSizes survive on a 200-point random walk. Its boxes
+// average at most 4.6 points at any size (10 is the most loaded), so a
+// threshold of 5 keeps none and the fit widens from size 10 to its
+// neighbours rather than falling back to the coarsest sizes.
+func TestBoxSizesSurviving200Points(t *testing.T) {
+	walk := randomWalk(1, 200)
+	tests := []struct {
+		minPoints float64
+		want      []int
+	}{
+		{0, []int{8, 10, 16, 20}}, // DefaultMinPointsPerBox
+		{2, []int{3, 4, 5, 8, 10, 16, 20, 25, 32}},
+		{3, []int{4, 5, 8, 10, 16, 20, 25, 32}},
+		{4, []int{8, 10, 16, 20, 25}},
+		{5, []int{8, 10, 16, 20}},
+	}
+	for _, tt := range tests {
+		got := fitSizes(t, BoxCounter{MinPointsPerBox: tt.minPoints}, walk)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("MinPointsPerBox %g: sizes %v, want %v", tt.minPoints, got, tt.want)
+		}
+	}
+}
+
+func TestResolvedWidensFromSurvivors(t *testing.T) {
+	// Loads over m = 120 points: 1, 2, 3, 6, 4, 2
+	counted := []boxCount{{1, 120}, {2, 60}, {4, 40}, {8, 20}, {16, 30}, {32, 60}}
+	tests := []struct {
+		name string
+		b    BoxCounter
+		want []int
+	}{
+		{"default of 5", BoxCounter{}, []int{2, 4, 8, 16}},
+		{"enough survivors", BoxCounter{MinPointsPerBox: 2}, []int{2, 4, 8, 16, 32}},
+		{"one survivor widened", BoxCounter{MinPointsPerBox: 5}, []int{2, 4, 8, 16}},
+		{"none survive", BoxCounter{MinPointsPerBox: 10}, []int{2, 4, 8, 16}},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, c := range tt.b.resolved(counted, 120) {
+			got = append(got, c.size)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: sizes %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+}
+
+func TestRenyiD0MatchesFit(t *testing.T) {
+	walk := randomWalk(2, 2000)
+	for _, minPoints := range []float64{0, 2} {
+		b := BoxCounter{MinPointsPerBox: minPoints}
+		d, _ := b.Fit(walk)
+		d0 := b.RenyiDimensions(walk, []float64{0})[0]
+		if math.Abs(d-d0) > 1e-12 {
+			t.Errorf("MinPointsPerBox %g: D0 %v, Fit %v", minPoints, d0, d)
+		}
+	}
+}
+
+// Every size's overlapping count is at least its disjoint count, and the
+// two agree at size 1, where there is only one tiling.
+func TestOverlapCountsAtLeastDisjoint(t *testing.T) {
+	walk := randomWalk(4, 1000)
+	lows := make([]float64, len(walk))
+	highs := make([]float64, len(walk))
+	for i, p := range walk {
+		lows[i], highs[i] = p-0.7, p+0.7
+	}
+	sizes := []int{1, 2, 3, 5, 8, 13, 21, 34, 55}
+	for _, tt := range []struct {
+		name        string
+		lows, highs []float64
+	}{
+		{"close", walk, walk},
+		{"high-low", lows, highs},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			disjoint := BoxCounter{Sizes: sizes}
+			overlap := BoxCounter{Sizes: sizes, Overlap: true}
+			normLow, normHigh, _ := disjoint.normalise(tt.lows, tt.highs)
+			want, _ := disjoint.boxCounts(context.Background(), normLow, normHigh)
+			got, _ := overlap.boxCounts(context.Background(), normLow, normHigh)
+			for i := range want {
+				if got[i].count < want[i].count {
+					t.Errorf("size %d: overlapping count %v below disjoint %v", sizes[i], got[i].count, want[i].count)
+				}
+			}
+			if got[0].count != want[0].count {
+				t.Errorf("size 1: overlapping count %v, want the disjoint %v", got[0].count, want[0].count)
+			}
+			if got[len(got)-1].count == want[len(want)-1].count {
+				t.Errorf("size %d: overlapping count equals the disjoint one", sizes[len(sizes)-1])
+			}
+		})
+	}
+}
+
+// The gliding count matches the densest tiling counted box by box.
+func TestGlidingBoxCountBruteForce(t *testing.T) {
+	walk := randomWalk(5, 300)
+	norm, _, _ := BoxCounter{}.normalise(walk, walk)
+	rows := func(i, bs int) (lo, hi int) { return boxRow(norm[i], bs), boxRow(norm[i], bs) }
+	m := len(norm) - 1
+	for _, bs := range []int{1, 2, 7, 16, 40, 299, 400} {
+		want := 0
+		for o := 0; o < bs; o++ {
+			boxes := map[[2]int]bool{}
+			for i := 0; i < m; i++ {
+				column := (i - o + bs) / bs // the partial column before o is 0
+				y, _ := rows(i, bs)
+				boxes[[2]int{column, y}] = true
+			}
+			want = max(want, len(boxes))
+		}
+		if got := glidingBoxCount(m, bs, rows); got != want {
+			t.Errorf("size %d: gliding count %d, want %d", bs, got, want)
+		}
+	}
+}
+
+func TestLinearFitR2(t *testing.T) {
+	scattered := whiteNoise(14, 200)
+	x := make([]float64, len(scattered))
+	for i := range x {
+		x[i] = float64(i)
+	}
+	tests := []struct {
+		name     string
+		x, y     []float64
+		min, max float64
+	}{
+		{"collinear", []float64{1, 2, 3, 4, 5}, []float64{3, 5, 7, 9, 11}, 1 - 1e-12, 1},
+		{"collinear falling", []float64{0, 1, 2}, []float64{1, 0.5, 0}, 1 - 1e-12, 1},
+		{"flat", []float64{1, 2, 3}, []float64{4, 4, 4}, 1, 1},
+		{"scattered", x, scattered, 0, 0.05},
+	}
+	for _, tt := range tests {
+		_, r2, ok := LinearFit(tt.x, tt.y)
+		if !ok || r2 < tt.min || r2 > tt.max {
+			t.Errorf("%s: R² %v (ok %v), want in [%v, %v]", tt.name, r2, ok, tt.min, tt.max)
+		}
+	}
+
+	// The box-counting fit of a long random walk, where enough sizes carry
+	// five points a box, is close to a line, and reports its R²
+	if _, r2 := BoxCountingFit(randomWalk(14, 10000)); r2 < 0.8 {
+		t.Errorf("random walk: box-counting R² %v, want above 0.8", r2)
+	}
+}
+
+func TestLogSpacedBoxSizes(t *testing.T) {
+	tests := []struct {
+		min, max, count int
+		want            []int // nil checks only the schedule's shape
+	}{
+		{1, 64, 12, nil},
+		{1, 1000, 30, nil},
+		{1, 8, 20, []int{1, 2, 3, 4, 5, 6, 7, 8}}, // rounding collapses repeats
+		{2, 32, 5, []int{2, 4, 8, 16, 32}},
+		{5, 5, 3, []int{5}},
+		{4, 100, 1, []int{4}},
+	}
+	for _, tt := range tests {
+		got := LogSpacedBoxSizes(tt.min, tt.max, tt.count)
+		if tt.want != nil && !slices.Equal(got, tt.want) {
+			t.Errorf("LogSpacedBoxSizes(%d, %d, %d) = %v, want %v", tt.min, tt.max, tt.count, got, tt.want)
+		}
+		last := tt.max
+		if tt.count == 1 {
+			last = tt.min
+		}
+		if len(got) == 0 || len(got) > tt.count || got[0] != tt.min || got[len(got)-1] != last {
+			t.Errorf("LogSpacedBoxSizes(%d, %d, %d) = %v, want up to %d sizes from min to max", tt.min, tt.max, tt.count, got, tt.count)
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i] <= got[i-1] {
+				t.Errorf("LogSpacedBoxSizes(%d, %d, %d) = %v, not strictly increasing", tt.min, tt.max, tt.count, got)
+				break
+			}
+		}
+	}
+
+	for _, bad := range [][3]int{{0, 10, 5}, {10, 5, 5}, {1, 10, 0}} {
+		if got := LogSpacedBoxSizes(bad[0], bad[1], bad[2]); got != nil {
+			t.Errorf("LogSpacedBoxSizes%v = %v, want nil", bad, got)
+		}
+	}
+}
+
+func TestLinearSlope(t *testing.T) {
+	tests := []struct {
+		name      string
+		x, y      []float64
+		wantSlope float64
+		wantOK    bool
+	}{
+		{"perfect line", []float64{0, 1, 2, 3}, []float64{1, 3, 5, 7}, 2, true},
+		{"negative slope", []float64{-1, 0, 1}, []float64{2, 1, 0}, -1, true},
+		{"two points", []float64{1, 3}, []float64{0, 1}, 0.5, true},
+		{"vertical x", []float64{2, 2, 2}, []float64{1, 2, 3}, 0, false},
+		{"single point", []float64{1}, []float64{1}, 0, false},
+		{"empty", nil, nil, 0, false},
+		{"mismatched lengths", []float64{0, 1, 2}, []float64{0, 1}, 0, false},
+	}
+	for _, tt := range tests {
+		slope, ok := LinearSlope(tt.x, tt.y)
+		if ok != tt.wantOK || math.Abs(slope-tt.wantSlope) > 1e-12 {
+			t.Errorf("%s: slope %v ok %v, want %v %v", tt.name, slope, ok, tt.wantSlope, tt.wantOK)
+		}
+	}
+}
+
+// The grid counts exactly the boxes the map does, on closes and on bar
+// ranges, with skipped points and on every size schedule.
+func TestBoxImplsAgree(t *testing.T) {
+	walk := randomWalk(97, 5000)
+	gappy := append([]float64(nil), walk[:777]...)
+	gappy[10], gappy[400] = math.NaN(), math.Inf(1)
+	width := whiteNoise(97, len(walk))
+	lows, highs := make([]float64, len(walk)), make([]float64, len(walk))
+	for i, p := range walk {
+		lows[i], highs[i] = p-math.Abs(width[i]), p+math.Abs(width[i])
+	}
+
+	tests := []struct {
+		name        string
+		lows, highs []float64
+		sizes       []int
+	}{
+		{"walk", walk, walk, nil},
+		{"short walk", walk[:37], walk[:37], nil},
+		{"odd length, log-spaced sizes", walk[:4999], walk[:4999], LogSpacedBoxSizes(1, 1000, 15)},
+		{"skipped points", gappy, gappy, nil},
+		{"bar ranges", lows, highs, nil},
+	}
+	for _, tt := range tests {
+		counts := map[BoxImpl][]boxCount{}
+		dims := map[BoxImpl]float64{}
+		for _, impl := range []BoxImpl{BoxMap, BoxGrid} {
+			b := BoxCounter{Sizes: tt.sizes, Impl: impl}
+			normLow, normHigh, _ := b.normalise(tt.lows, tt.highs)
+			got, err := b.boxCounts(context.Background(), normLow, normHigh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[impl] = got
+			dims[impl], _ = b.FitRange(tt.lows, tt.highs)
+		}
+		if !slices.Equal(counts[BoxMap], counts[BoxGrid]) || len(counts[BoxMap]) == 0 {
+			t.Errorf("%s: grid counts %v, map counts %v", tt.name, counts[BoxGrid], counts[BoxMap])
+		}
+		if dims[BoxMap] != dims[BoxGrid] {
+			t.Errorf("%s: grid dimension %v, map dimension %v", tt.name, dims[BoxGrid], dims[BoxMap])
+		}
+	}
+
+	for _, name := range []string{"map", "grid"} {
+		if impl, err := ParseBoxImpl(name); err != nil || string(impl) != name {
+			t.Errorf("ParseBoxImpl(%q) = %q, %v", name, impl, err)
+		}
+	}
+	if _, err := ParseBoxImpl("tree"); err == nil {
+		t.Error("ParseBoxImpl(tree): no error")
+	}
+}
@@ -0,0 +1,101 @@
+package fractal
+
+import "math"
+
+// DefaultChangePenalty is the ChangePoints penalty in units of log n,
+// about what BIC charges for a change in mean at an unknown location.
+const DefaultChangePenalty = 2.0
+
+// Shortest segment ChangePoints will split off.
+const minChangeSegment = 2
+
+// ChangePoints segments series into runs of constant mean with PELT
+// (Killick et al., 2012) and returns the index at which each new run
+// starts, in order. A segment costs its squared deviations from its mean
+// over the variance of the whole series, and each change point adds
+// penalty·log n, so a larger penalty finds fewer, larger shifts. It is
+// meant for the rolling dimension series, to flag where the market's
+// fractal character moves. NaN values are skipped and never start a run;
+// a series with fewer than four finite values, or no variation, has none.
+func ChangePoints(series []float64, penalty float64) []int {
+	var idx []int
+	var x []float64
+	for i, v := range series {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			idx = append(idx, i)
+			x = append(x, v)
+		}
+	}
+	n := len(x)
+	scale := variance(x)
+	if n < 2*minChangeSegment || !(scale > 0) {
+		return nil
+	}
+
+	// Prefix sums give any segment's cost in constant time
+	sum := make([]float64, n+1)
+	sumSq := make([]float64, n+1)
+	for i, v := range x {
+		sum[i+1] = sum[i] + v
+		sumSq[i+1] = sumSq[i] + v*v
+	}
+	cost := func(s, t int) float64 {
+		d := sum[t] - sum[s]
+		return (sumSq[t] - sumSq[s] - d*d/float64(t-s)) / scale
+	}
+
+	beta := penalty * math.Log(float64(n))
+	best := make([]float64, n+1) // optimal cost of x[:t]
+	last := make([]int, n+1)     // start of the last segment in that optimum
+	best[0] = -beta
+	candidates := []int{0}
+	for t := 1; t <= n; t++ {
+		best[t] = math.Inf(1)
+		for _, s := range candidates {
+			if t-s < minChangeSegment {
+				continue
+			}
+			if c := best[s] + cost(s, t) + beta; c < best[t] {
+				best[t], last[t] = c, s
+			}
+		}
+		if math.IsInf(best[t], 1) {
+			continue
+		}
+
+		// A start that can't beat the optimum now never will
+		kept := candidates[:0]
+		for _, s := range candidates {
+			if t-s < minChangeSegment || best[s]+cost(s, t) <= best[t] {
+				kept = append(kept, s)
+			}
+		}
+		candidates = append(kept, t)
+	}
+
+	var points []int
+	for t := last[n]; t > 0; t = last[t] {
+		points = append(points, idx[t])
+	}
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points
+}
+
+// The means of the finite values of series between consecutive change
+// points: one per segment, the first before points[0].
+func segmentMeans(series []float64, points []int) []float64 {
+	bounds := append(append([]int{0}, points...), len(series))
+	means := make([]float64, len(bounds)-1)
+	for i := range means {
+		var finite []float64
+		for _, v := range series[bounds[i]:bounds[i+1]] {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				finite = append(finite, v)
+			}
+		}
+		means[i] = mean(finite)
+	}
+	return means
+}
@@ -0,0 +1,63 @@
+package fractal
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// Levels with noise of the given standard deviation, each run of values
+// at one level.
+func steps(seed int64, sd float64, levels []float64, run int) []float64 {
+	noise := whiteNoise(seed, len(levels)*run)
+	series := make([]float64, len(noise))
+	for i := range series {
+		series[i] = levels[i/run] + sd*noise[i]
+	}
+	return series
+}
+
+func TestChangePoints(t *testing.T) {
+	withNaN := steps(100, 0.02, []float64{1.4, 1.6}, 60)
+	withNaN[30], withNaN[61] = math.NaN(), math.NaN()
+
+	tests := []struct {
+		name    string
+		series  []float64
+		penalty float64
+		want    []int // true change locations
+		slack   int   // how far off each may be found
+	}{
+		{"clean step", steps(100, 0, []float64{1.4, 1.6}, 60), DefaultChangePenalty, []int{60}, 0},
+		{"noisy step", steps(100, 0.02, []float64{1.4, 1.6}, 60), DefaultChangePenalty, []int{60}, 2},
+		{"up and back", steps(101, 0.02, []float64{1.5, 1.3, 1.5}, 40), DefaultChangePenalty, []int{40, 80}, 2},
+		{"NaNs skipped", withNaN, DefaultChangePenalty, []int{60}, 2},
+		{"no step", steps(102, 0.02, []float64{1.5}, 120), DefaultChangePenalty, nil, 0},
+		{"penalty above the step", steps(100, 0.02, []float64{1.4, 1.45}, 60), 100, nil, 0},
+		{"constant", steps(100, 0, []float64{1.5}, 50), DefaultChangePenalty, nil, 0},
+		{"too short", []float64{1, 2, 3}, DefaultChangePenalty, nil, 0},
+	}
+	for _, tt := range tests {
+		got := ChangePoints(tt.series, tt.penalty)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: change points %v, want near %v", tt.name, got, tt.want)
+			continue
+		}
+		for i, p := range got {
+			if d := p - tt.want[i]; d < -tt.slack || d > tt.slack || math.IsNaN(tt.series[p]) {
+				t.Errorf("%s: change points %v, want within %d of %v", tt.name, got, tt.slack, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSegmentMeans(t *testing.T) {
+	series := []float64{1, 3, math.NaN(), 10, 20, 30}
+	if got := segmentMeans(series, []int{3}); !slices.Equal(got, []float64{2, 20}) {
+		t.Errorf("segment means %v, want [2 20]", got)
+	}
+	if got := segmentMeans(series[:2], nil); !slices.Equal(got, []float64{2}) {
+		t.Errorf("one segment: means %v, want [2]", got)
+	}
+}
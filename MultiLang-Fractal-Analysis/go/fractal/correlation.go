@@ -0,0 +1,91 @@
+package fractal
+
+import (
+	"math"
+	"sort"
+)
+
+// CorrelationDimension estimates the Grassberger–Procaccia correlation
+// dimension of series after time-delay embedding in embedDim dimensions
+// with the given delay. The correlation integral C(r), the fraction of
+// vector pairs closer than r, is evaluated at radii log-spaced between the
+// 1st and 50th percentiles of the pairwise distances, which keeps the fit
+// inside the scaling region for most series; the result is the slope of
+// log C(r) against log r. It returns NaN when too few vectors can be
+// built or the points are all coincident.
+//
+// Every pair of vectors is compared, so time and memory are O(n²) in
+// len(series); subsample long series before calling.
+func CorrelationDimension(series []float64, embedDim, delay int) float64 {
+	vectors := Embed(series, embedDim, delay)
+	if len(vectors) < 10 {
+		return math.NaN()
+	}
+
+	dists := make([]float64, 0, len(vectors)*(len(vectors)-1)/2)
+	for i := range vectors {
+		for j := i + 1; j < len(vectors); j++ {
+			sum := 0.0
+			for k := range vectors[i] {
+				d := vectors[i][k] - vectors[j][k]
+				sum += d * d
+			}
+			dists = append(dists, math.Sqrt(sum))
+		}
+	}
+	sort.Float64s(dists)
+
+	// Skip any run of zero distances so the smallest radius is positive
+	first := sort.SearchFloat64s(dists, math.SmallestNonzeroFloat64)
+	if first >= len(dists) {
+		return math.NaN()
+	}
+	rMin := dists[first+(len(dists)-first)/100]
+	rMax := dists[len(dists)/2]
+	if rMin <= 0 || rMax <= rMin {
+		return math.NaN()
+	}
+
+	const radii = 12
+	var logR, logC []float64
+	step := math.Log(rMax/rMin) / (radii - 1)
+	for i := 0; i < radii; i++ {
+		r := rMin * math.Exp(float64(i)*step)
+		pairs := sort.SearchFloat64s(dists, r)
+		if pairs == 0 {
+			continue
+		}
+		logR = append(logR, math.Log(r))
+		logC = append(logC, math.Log(float64(pairs)/float64(len(dists))))
+	}
+
+	if len(logR) < 2 {
+		return math.NaN()
+	}
+	d, ok := LinearSlope(logR, logC)
+	if !ok {
+		return math.NaN()
+	}
+	return d
+}
+
+// Embed performs time-delay embedding: vector i is series[i], series[i+delay], ...,
+// series[i+(dim-1)*delay]. Returns nil for invalid parameters.
+func Embed(series []float64, dim, delay int) [][]float64 {
+	if dim < 1 || delay < 1 {
+		return nil
+	}
+	count := len(series) - (dim-1)*delay
+	if count <= 0 {
+		return nil
+	}
+	vectors := make([][]float64, count)
+	for i := range vectors {
+		v := make([]float64, dim)
+		for k := range v {
+			v[k] = series[i+k*delay]
+		}
+		vectors[i] = v
+	}
+	return vectors
+}
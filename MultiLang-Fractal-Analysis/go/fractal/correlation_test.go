@@ -0,0 +1,52 @@
+package fractal
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestCorrelationDimension(t *testing.T) {
+	sine := make([]float64, 800)
+	for i := range sine {
+		sine[i] = math.Sin(0.15 * float64(i)) // an irrational period, so no point repeats
+	}
+	tests := []struct {
+		name     string
+		series   []float64
+		min, max float64
+	}{
+		{"sine", sine, 0.8, 1.3},                       // a closed curve
+		{"white noise", whiteNoise(29, 800), 2.3, 3.2}, // fills the 3 embedding dimensions
+	}
+	for _, tt := range tests {
+		d := CorrelationDimension(tt.series, 3, 10)
+		if !(d >= tt.min && d <= tt.max) {
+			t.Errorf("%s: correlation dimension %v, want in [%v, %v]", tt.name, d, tt.min, tt.max)
+		}
+	}
+
+	if d := CorrelationDimension(make([]float64, 100), 3, 1); !math.IsNaN(d) {
+		t.Errorf("constant series: %v, want NaN", d)
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	series := []float64{0, 1, 2, 3, 4, 5}
+	tests := []struct {
+		dim, delay int
+		want       [][]float64
+	}{
+		{1, 1, [][]float64{{0}, {1}, {2}, {3}, {4}, {5}}},
+		{2, 2, [][]float64{{0, 2}, {1, 3}, {2, 4}, {3, 5}}},
+		{3, 2, [][]float64{{0, 2, 4}, {1, 3, 5}}},
+		{4, 2, nil}, // needs 7 points
+		{0, 1, nil},
+		{2, 0, nil},
+	}
+	for _, tt := range tests {
+		if got := Embed(series, tt.dim, tt.delay); !slices.EqualFunc(got, tt.want, slices.Equal) {
+			t.Errorf("Embed(dim %d, delay %d) = %v, want %v", tt.dim, tt.delay, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package fractal
+
+import "math"
+
+// AlignByTimestamp restricts each series to the timestamps present in all
+// of them, keeping the order of the first. The returned candles are
+// copies; their Returns and Volatility still describe the unaligned
+// series and need recomputing.
+func AlignByTimestamp(series [][]MarketCandle) [][]MarketCandle {
+	if len(series) == 0 {
+		return nil
+	}
+
+	// Count in how many series each instant appears, once per series
+	seen := make(map[int64]int)
+	for _, data := range series {
+		inSeries := make(map[int64]bool, len(data))
+		for _, c := range data {
+			key := c.Timestamp.UnixNano()
+			if !inSeries[key] {
+				inSeries[key] = true
+				seen[key]++
+			}
+		}
+	}
+
+	aligned := make([][]MarketCandle, len(series))
+	for i, data := range series {
+		taken := make(map[int64]bool)
+		for _, c := range data {
+			key := c.Timestamp.UnixNano()
+			if seen[key] == len(series) && !taken[key] {
+				taken[key] = true
+				aligned[i] = append(aligned[i], c)
+			}
+		}
+	}
+
+	// Later series may list the common instants in another order
+	order := make(map[int64]int, len(aligned[0]))
+	for j, c := range aligned[0] {
+		order[c.Timestamp.UnixNano()] = j
+	}
+	for _, data := range aligned[1:] {
+		sorted := make([]MarketCandle, len(data))
+		for _, c := range data {
+			sorted[order[c.Timestamp.UnixNano()]] = c
+		}
+		copy(data, sorted)
+	}
+	return aligned
+}
+
+// ReturnCorrelations aligns the series by timestamp and returns the
+// Pearson correlation matrix of their returns of the given kind. Pairs
+// where either series has no spread are NaN, as is everything when
+// fewer than three timestamps are shared.
+func ReturnCorrelations(series [][]MarketCandle, kind ReturnKind) [][]float64 {
+	aligned := AlignByTimestamp(series)
+	returns := make([][]float64, len(aligned))
+	for i, data := range aligned {
+		ComputeReturns(data, kind)
+		// The first aligned candle has no return
+		if len(data) > 1 {
+			returns[i] = SeriesReturns.Values(data[1:])
+		}
+	}
+	return CorrelationMatrix(returns)
+}
+
+// CorrelationMatrix returns the pairwise Pearson correlations of
+// equal-length series.
+func CorrelationMatrix(series [][]float64) [][]float64 {
+	m := make([][]float64, len(series))
+	for i := range m {
+		m[i] = make([]float64, len(series))
+	}
+	for i := range series {
+		for j := i; j < len(series); j++ {
+			r := Pearson(series[i], series[j])
+			m[i][j], m[j][i] = r, r
+		}
+	}
+	return m
+}
+
+// Pearson returns the sample correlation of x and y over the pairs where
+// neither is NaN. It is NaN when they differ in length, fewer than two
+// pairs remain or either side has no spread.
+func Pearson(x, y []float64) float64 {
+	if len(y) != len(x) {
+		return math.NaN()
+	}
+	var xs, ys []float64
+	for i := range x {
+		if !math.IsNaN(x[i]) && !math.IsNaN(y[i]) {
+			xs, ys = append(xs, x[i]), append(ys, y[i])
+		}
+	}
+	if len(xs) < 2 {
+		return math.NaN()
+	}
+	x, y = xs, ys
+
+	mx, my := mean(x), mean(y)
+
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return math.NaN()
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
@@ -0,0 +1,76 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// A scaled copy of a series is perfectly correlated with it even when the
+// two only share part of their timestamps; an independent one is not.
+func TestReturnCorrelations(t *testing.T) {
+	a := GenerateSeries(NewRand(56, 0), 1000, 100)
+	var b []MarketCandle
+	for i, c := range a {
+		if i%7 == 3 {
+			continue // missing from b
+		}
+		c.Price *= 2.5
+		b = append(b, c)
+	}
+	extra := a[len(a)-1]
+	extra.Timestamp = extra.Timestamp.Add(time.Hour) // only in b
+	b = append(b, extra)
+	b[0], b[1] = b[1], b[0] // listed out of order
+	independent := GenerateSeries(NewRand(56, 1), 1000, 100)
+	for i := range independent {
+		independent[i].Timestamp = a[i].Timestamp
+	}
+
+	aligned := AlignByTimestamp([][]MarketCandle{a, b, independent})
+	want := len(b) - 1 // all but the extra candle
+	for i, data := range aligned {
+		if len(data) != want {
+			t.Fatalf("aligned series %d: %d candles, want %d", i, len(data), want)
+		}
+		for j := range data {
+			if !data[j].Timestamp.Equal(aligned[0][j].Timestamp) {
+				t.Fatalf("aligned series %d: candle %d at %v, want %v", i, j, data[j].Timestamp, aligned[0][j].Timestamp)
+			}
+		}
+	}
+
+	for _, kind := range []ReturnKind{SimpleReturns, LogReturns} {
+		m := ReturnCorrelations([][]MarketCandle{a, b, independent}, kind)
+		if math.Abs(m[0][1]-1) > 1e-9 || m[0][1] != m[1][0] {
+			t.Errorf("%s: scaled copy correlation %v, %v, want 1", kind, m[0][1], m[1][0])
+		}
+		if math.Abs(m[0][0]-1) > 1e-12 {
+			t.Errorf("%s: self correlation %v, want 1", kind, m[0][0])
+		}
+		if math.Abs(m[0][2]) > 0.1 {
+			t.Errorf("%s: independent correlation %v, want near 0", kind, m[0][2])
+		}
+	}
+}
+
+func TestPearson(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name string
+		x, y []float64
+		want float64
+	}{
+		{"perfect", []float64{1, 2, 3}, []float64{2, 4, 6}, 1},
+		{"inverse", []float64{1, 2, 3}, []float64{3, 2, 1}, -1},
+		{"NaN pair skipped", []float64{1, nan, 2, 3}, []float64{1, 9, 2, 3}, 1},
+		{"no spread", []float64{1, 1, 1}, []float64{1, 2, 3}, nan},
+		{"lengths differ", []float64{1, 2}, []float64{1}, nan},
+	}
+	for _, tt := range tests {
+		got := Pearson(tt.x, tt.y)
+		if math.IsNaN(tt.want) != math.IsNaN(got) || !math.IsNaN(got) && math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package fractal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const timestampLayout = "2006-01-02 15:04:05"
+
+// ReadMarketCSV reads candles in the schema written by WriteMarketCSV or
+// any column order and common alias of it, such as time,close,vol; header
+// names are matched case-insensitively and unknown columns are ignored. A
+// timestamp and a price or close column are required. Volume is optional
+// and defaults to 0. Returns and Volatility are optional; if either column
+// is missing both are recomputed. Files without Open/High/Low/Close
+// columns load as flat bars at Price.
+// A ".gz" filename is decompressed. Timestamps are detected as described
+// for ParseTimestamp.
+func ReadMarketCSV(filename string) ([]MarketCandle, error) {
+	return MarketCSVReader{}.Read(filename)
+}
+
+// MarketCSVReader reads market CSVs as ReadMarketCSV does, with options.
+type MarketCSVReader struct {
+	// TimeFormat is the timestamp layout passed to ParseTimestamp; empty
+	// detects it.
+	TimeFormat string
+	// Limit keeps only the first Limit rows, or the last with Tail; <= 0
+	// reads them all. Either way the file is streamed, so only the kept
+	// rows are held in memory, and Returns and Volatility are recomputed
+	// so the first kept candle starts fresh.
+	Limit int
+	Tail  bool
+	// VolWindow is the rolling window those recomputed volatilities use;
+	// <= 0 uses DefaultVolWindow.
+	VolWindow int
+}
+
+// Read reads candles from filename. If any kept timestamp fails to parse,
+// they all become sequential hourly timestamps from the Unix epoch, with
+// a warning logged, so the series can still be analysed in order.
+func (r MarketCSVReader) Read(filename string) ([]MarketCandle, error) {
+	file, err := openInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%s: missing header", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	cols, hasStats, hasOHLC, err := candleColumns(header)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	// Rows are parsed as they stream past, but timestamps only once the
+	// kept rows are known, so a bad one among dropped rows doesn't matter
+	type row struct {
+		candle MarketCandle
+		time   string
+		line   int
+	}
+	var rows []row
+	next := 0 // oldest row once the tail buffer has filled
+	for {
+		if r.Limit > 0 && !r.Tail && len(rows) == r.Limit {
+			break
+		}
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue // blank line
+		}
+
+		candle, err := parseCandle(record, cols, hasStats, hasOHLC)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", filename, line, err)
+		}
+		kept := row{candle, record[cols["Timestamp"]], line}
+		if r.Limit > 0 && r.Tail && len(rows) == r.Limit {
+			rows[next] = kept
+			next = (next + 1) % r.Limit
+			continue
+		}
+		rows = append(rows, kept)
+	}
+	rows = append(rows[next:], rows[:next]...)
+
+	data := make([]MarketCandle, len(rows))
+	var badTime error
+	for i, kept := range rows {
+		data[i] = kept.candle
+		if badTime == nil {
+			if data[i].Timestamp, err = ParseTimestamp(kept.time, r.TimeFormat); err != nil {
+				badTime = fmt.Errorf("line %d: %w", kept.line, err)
+			}
+		}
+	}
+
+	if badTime != nil {
+		slog.Warn("unparseable timestamp, using sequential hourly timestamps", "file", filename, "error", badTime)
+		start := time.Unix(0, 0).UTC()
+		for i := range data {
+			data[i].Timestamp = start.Add(time.Duration(i) * time.Hour)
+		}
+	}
+
+	if !hasStats || r.Limit > 0 {
+		window := r.VolWindow
+		if window <= 0 {
+			window = DefaultVolWindow
+		}
+		ComputeReturnsAndVol(data, window)
+	}
+	return data, nil
+}
+
+// Maps a market CSV header to candle fields, reporting whether it carries
+// both Returns and Volatility and all of Open, High, Low and Close.
+func candleColumns(header []string) (cols map[string]int, withStats, withOHLC bool, err error) {
+	cols = mapColumns(header)
+	if _, ok := cols["Price"]; !ok {
+		return nil, false, false, fmt.Errorf("no price column (want a header such as %s, or %s)", aliasList("Price"), aliasList("Close"))
+	}
+	if _, ok := cols["Timestamp"]; !ok {
+		return nil, false, false, fmt.Errorf("no timestamp column (want a header such as %s)", aliasList("Timestamp"))
+	}
+	_, hasReturns := cols["Returns"]
+	_, hasVol := cols["Volatility"]
+	withOHLC = true
+	for _, name := range []string{"Open", "High", "Low", "Close"} {
+		if _, ok := cols[name]; !ok {
+			withOHLC = false
+		}
+	}
+	return cols, hasReturns && hasVol, withOHLC, nil
+}
+
+// CandleDecoder parses market CSV records one at a time under a header
+// read separately, for rows that arrive singly such as those appended to
+// a live file. Columns are matched as by ReadMarketCSV, but as there is
+// no series to renumber an unparseable timestamp is an error, and Returns
+// and Volatility are only what the row holds.
+type CandleDecoder struct {
+	cols                map[string]int
+	withStats, withOHLC bool
+	timeFormat          string
+}
+
+// NewCandleDecoder returns a decoder for rows under header, parsing
+// timestamps with layout as ParseTimestamp does.
+func NewCandleDecoder(header []string, layout string) (*CandleDecoder, error) {
+	cols, withStats, withOHLC, err := candleColumns(header)
+	if err != nil {
+		return nil, err
+	}
+	return &CandleDecoder{cols, withStats, withOHLC, layout}, nil
+}
+
+// Decode parses one record.
+func (d *CandleDecoder) Decode(record []string) (MarketCandle, error) {
+	c, err := parseCandle(record, d.cols, d.withStats, d.withOHLC)
+	if err != nil {
+		return c, err
+	}
+	c.Timestamp, err = ParseTimestamp(strings.TrimSpace(record[d.cols["Timestamp"]]), d.timeFormat)
+	return c, err
+}
+
+func parseCandle(record []string, cols map[string]int, withStats, withOHLC bool) (MarketCandle, error) {
+	field := func(name string) (string, error) {
+		i := cols[name]
+		if i >= len(record) {
+			return "", fmt.Errorf("missing %s field", name)
+		}
+		return strings.TrimSpace(record[i]), nil
+	}
+	number := func(name string) (float64, error) {
+		raw, err := field(name)
+		if err != nil {
+			return 0, err
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return v, nil
+	}
+
+	// The caller parses Timestamp, which may fall back rather than fail
+	var c MarketCandle
+	if _, err := field("Timestamp"); err != nil {
+		return c, err
+	}
+	var err error
+	if c.Price, err = number("Price"); err != nil {
+		return c, err
+	}
+	if _, ok := cols["Volume"]; ok {
+		if c.Volume, err = number("Volume"); err != nil {
+			return c, err
+		}
+	}
+	if withStats {
+		if c.Returns, err = number("Returns"); err != nil {
+			return c, err
+		}
+		if c.Volatility, err = number("Volatility"); err != nil {
+			return c, err
+		}
+	}
+	if !withOHLC {
+		c.Open, c.High, c.Low, c.Close = c.Price, c.Price, c.Price, c.Price
+		return c, nil
+	}
+	for _, f := range []struct {
+		name string
+		dst  *float64
+	}{{"Open", &c.Open}, {"High", &c.High}, {"Low", &c.Low}, {"Close", &c.Close}} {
+		if *f.dst, err = number(f.name); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+// WriteMarketCSV writes one row per candle. Like every CSV writer here it
+// gzip-compresses the output when filename ends in ".gz".
+func WriteMarketCSV(data []MarketCandle, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Header
+	writer.Write([]string{"Timestamp", "Price", "Volume", "Returns", "Volatility", "Open", "High", "Low", "Close"})
+
+	// Data
+	for _, candle := range data {
+		record := []string{
+			candle.Timestamp.Format(timestampLayout),
+			fmt.Sprintf("%.6f", candle.Price),
+			fmt.Sprintf("%.2f", candle.Volume),
+			fmt.Sprintf("%.6f", candle.Returns),
+			fmt.Sprintf("%.6f", candle.Volatility),
+			fmt.Sprintf("%.6f", candle.Open),
+			fmt.Sprintf("%.6f", candle.High),
+			fmt.Sprintf("%.6f", candle.Low),
+			fmt.Sprintf("%.6f", candle.Close),
+		}
+		writer.Write(record)
+	}
+
+	return finishCSV(writer, file)
+}
@@ -0,0 +1,210 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Writes content to name in a temporary directory and returns its path.
+func writeTempCSV(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadMarketCSVHeaders(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 3, 1, hour, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name    string
+		content string
+		prices  []float64
+		volumes []float64
+		ohlc    bool
+	}{
+		{
+			name:    "canonical order",
+			content: "Timestamp,Price,Volume\n2024-03-01 00:00:00,100,10\n2024-03-01 01:00:00,101,11\n",
+			prices:  []float64{100, 101},
+			volumes: []float64{10, 11},
+		},
+		{
+			name:    "aliases reordered, unknown column ignored",
+			content: "vol,exchange,close,time\n10,XNYS,100,2024-03-01 00:00:00\n11,XNYS,101,2024-03-01 01:00:00\n",
+			prices:  []float64{100, 101},
+			volumes: []float64{10, 11},
+		},
+		{
+			name:    "case and separators",
+			content: "Close Price,TIME\n100,2024-03-01 00:00:00\n101,2024-03-01 01:00:00\n",
+			prices:  []float64{100, 101},
+			volumes: []float64{0, 0},
+		},
+		{
+			name:    "OHLC in any order",
+			content: "Low,High,Date Time,Close,Open\n99,102,2024-03-01 00:00:00,100,101\n100,103,2024-03-01 01:00:00,101,100\n",
+			prices:  []float64{100, 101},
+			volumes: []float64{0, 0},
+			ohlc:    true,
+		},
+	}
+	for _, tt := range tests {
+		data, err := ReadMarketCSV(writeTempCSV(t, "in.csv", tt.content))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(data) != len(tt.prices) {
+			t.Errorf("%s: %d candles, want %d", tt.name, len(data), len(tt.prices))
+			continue
+		}
+		for i, c := range data {
+			if c.Price != tt.prices[i] || c.Close != tt.prices[i] || c.Volume != tt.volumes[i] || !c.Timestamp.Equal(at(i)) {
+				t.Errorf("%s: candle %d %+v, want price %v volume %v at %v", tt.name, i, c, tt.prices[i], tt.volumes[i], at(i))
+			}
+			if flat := c.High == c.Price && c.Low == c.Price; flat == tt.ohlc {
+				t.Errorf("%s: candle %d high %v low %v, want OHLC %v", tt.name, i, c.High, c.Low, tt.ohlc)
+			}
+		}
+	}
+
+	for _, bad := range []struct{ content, errors string }{
+		{"time,volume\n2024-03-01,10\n", "no price column"},
+		{"close,volume\n100,10\n", "no timestamp column"},
+		{"", "missing header"},
+	} {
+		_, err := ReadMarketCSV(writeTempCSV(t, "in.csv", bad.content))
+		if err == nil || !strings.Contains(err.Error(), bad.errors) {
+			t.Errorf("header %q: error %v, want %q", bad.content, err, bad.errors)
+		}
+	}
+}
+
+// A file from before the OHLC columns loads every candle flat at its price,
+// so a low-high fit of it is the close fit; with the columns the fit
+// follows each bar's range.
+func TestReadMarketCSVWithoutOHLC(t *testing.T) {
+	walk := randomWalk(23, 400)
+	var old, ohlc strings.Builder
+	old.WriteString("Timestamp,Price,Volume,Returns,Volatility\n")
+	ohlc.WriteString("Timestamp,Open,High,Low,Close,Volume\n")
+	for i, v := range walk {
+		at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Hour).Format("2006-01-02 15:04:05")
+		price := 100 + v
+		spread := 0.5 * float64(i%7)
+		fmt.Fprintf(&old, "%s,%v,10,0,0\n", at, price)
+		fmt.Fprintf(&ohlc, "%s,%v,%v,%v,%v,10\n", at, price, price+spread, price-spread, price)
+	}
+
+	data, err := ReadMarketCSV(writeTempCSV(t, "old.csv", old.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range data {
+		if c.Open != c.Price || c.High != c.Price || c.Low != c.Price || c.Close != c.Price {
+			t.Fatalf("candle %d: open %v high %v low %v close %v, want all %v", i, c.Open, c.High, c.Low, c.Close, c.Price)
+		}
+	}
+	windows := []Window{{0, len(data)}}
+	closeFit := Analyzer{}.Analyze(data, windows)[0].Dimension
+	if d := (Analyzer{HighLow: true}).Analyze(data, windows)[0].Dimension; d != closeFit {
+		t.Errorf("flat candles: low-high dimension %v, want the close dimension %v", d, closeFit)
+	}
+
+	data, err = ReadMarketCSV(writeTempCSV(t, "ohlc.csv", ohlc.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lows, highs := make([]float64, len(data)), make([]float64, len(data))
+	for i, c := range data {
+		lows[i], highs[i] = c.Low, c.High
+	}
+	want, _ := BoxCounter{}.FitRange(lows, highs)
+	d := (Analyzer{HighLow: true}).Analyze(data, windows)[0].Dimension
+	if d != want || d == closeFit {
+		t.Errorf("OHLC candles: low-high dimension %v, want FitRange's %v, not the close dimension %v", d, want, closeFit)
+	}
+}
+
+// A malformed row fails naming its line; blank lines after the last row
+// are skipped.
+func TestMarketCSVReaderRows(t *testing.T) {
+	content := "Timestamp,Price\n2024-03-01 00:00:00,100\n2024-03-01 01:00:00,abc\n2024-03-01 02:00:00,102\n"
+	_, err := MarketCSVReader{}.Read(writeTempCSV(t, "in.csv", content))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("malformed price: error %v, want one naming line 3", err)
+	}
+
+	for _, trailing := range []string{"\n", "   \n", "\n\n"} {
+		content := "Timestamp,Price\n2024-03-01 00:00:00,100\n2024-03-01 01:00:00,101\n" + trailing
+		data, err := MarketCSVReader{}.Read(writeTempCSV(t, "in.csv", content))
+		if err != nil || len(data) != 2 {
+			t.Errorf("trailing %q: %d candles, %v, want 2 and no error", trailing, len(data), err)
+		}
+	}
+}
+
+// Limit keeps exactly the first or last rows of a file that carries its own
+// returns, recomputed so the first kept candle starts at 0.
+func TestMarketCSVReaderLimit(t *testing.T) {
+	data := GenerateSeries(NewRand(73, 0), 1000, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	path := filepath.Join(t.TempDir(), "market_data.csv")
+	if err := WriteMarketCSV(data, path); err != nil {
+		t.Fatal(err)
+	}
+	all, err := ReadMarketCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		limit int
+		tail  bool
+		first int // index in all of the first kept candle
+		n     int
+	}{
+		{100, false, 0, 100},
+		{100, true, 900, 100},
+		{1, true, 999, 1},
+		{5000, false, 0, 1000},
+		{5000, true, 0, 1000},
+		{0, true, 0, 1000},
+	}
+	for _, tt := range tests {
+		got, err := MarketCSVReader{Limit: tt.limit, Tail: tt.tail}.Read(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tt.n {
+			t.Errorf("limit %d tail %v: %d candles, want %d", tt.limit, tt.tail, len(got), tt.n)
+			continue
+		}
+		if !got[0].Timestamp.Equal(all[tt.first].Timestamp) || !got[len(got)-1].Timestamp.Equal(all[tt.first+tt.n-1].Timestamp) {
+			t.Errorf("limit %d tail %v: candles from %v to %v, want rows %d to %d", tt.limit, tt.tail, got[0].Timestamp, got[len(got)-1].Timestamp, tt.first, tt.first+tt.n-1)
+		}
+		if tt.limit <= 0 {
+			continue // stored returns, as written
+		}
+		if got[0].Returns != 0 || got[0].Volatility != 0 {
+			t.Errorf("limit %d tail %v: first candle returns %v vol %v, want both 0", tt.limit, tt.tail, got[0].Returns, got[0].Volatility)
+		}
+		if tt.n > 1 && math.Abs(got[1].Returns-(got[1].Price-got[0].Price)/got[0].Price) > 1e-12 {
+			t.Errorf("limit %d tail %v: second candle returns %v, want recomputed from its prices", tt.limit, tt.tail, got[1].Returns)
+		}
+	}
+
+	// A bad timestamp among the dropped rows doesn't fall back to hourly ones
+	content := "Timestamp,Price\nnot a time,100\n2024-03-01 00:00:00,101\n2024-03-01 01:00:00,102\n"
+	got, err := MarketCSVReader{Limit: 2, Tail: true}.Read(writeTempCSV(t, "in.csv", content))
+	if err != nil || len(got) != 2 || !got[0].Timestamp.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("tail past a bad timestamp: %v, %v", got, err)
+	}
+}
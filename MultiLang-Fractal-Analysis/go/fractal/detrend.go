@@ -0,0 +1,94 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+)
+
+// DetrendMethod selects the trend removed from a series before its
+// dimension is estimated, so a steady drift isn't mistaken for structure.
+type DetrendMethod string
+
+const (
+	DetrendNone   DetrendMethod = "none"
+	DetrendLinear DetrendMethod = "linear" // subtract the OLS line
+	DetrendLog    DetrendMethod = "log"    // subtract the OLS line of log prices
+)
+
+// ParseDetrendMethod validates a detrending method name.
+func ParseDetrendMethod(name string) (DetrendMethod, error) {
+	switch m := DetrendMethod(name); m {
+	case DetrendNone, DetrendLinear, DetrendLog:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown detrend method %q (want none, linear or log)", name)
+}
+
+// Detrend returns the residuals of series about its least-squares line
+// against the index, fitted with LinearSlope over the finite points. With
+// DetrendLog the line is fitted to and removed from the log of series, so
+// an exponential trend goes too; series must then be positive. NaN points
+// stay NaN. A series with fewer than two finite points is returned
+// without the line, and DetrendNone (or an empty method) returns series
+// itself.
+func Detrend(series []float64, method DetrendMethod) []float64 {
+	if method != DetrendLinear && method != DetrendLog {
+		return series
+	}
+	out := transformed(series, method)
+	if slope, intercept, ok := trendLine(out); ok {
+		for i := range out {
+			out[i] -= intercept + slope*float64(i)
+		}
+	}
+	return out
+}
+
+// Detrends the lows and highs of a range with one line, fitted to their
+// midpoints, so each bar keeps its extent.
+func detrendRange(lows, highs []float64, method DetrendMethod) (detrendedLows, detrendedHighs []float64) {
+	if method != DetrendLinear && method != DetrendLog {
+		return lows, highs
+	}
+	lo, hi := transformed(lows, method), transformed(highs, method)
+	mid := make([]float64, len(lo))
+	for i := range mid {
+		mid[i] = (lo[i] + hi[i]) / 2
+	}
+	if slope, intercept, ok := trendLine(mid); ok {
+		for i := range lo {
+			trend := intercept + slope*float64(i)
+			lo[i] -= trend
+			hi[i] -= trend
+		}
+	}
+	return lo, hi
+}
+
+// A copy of series, logged for DetrendLog.
+func transformed(series []float64, method DetrendMethod) []float64 {
+	out := make([]float64, len(series))
+	for i, v := range series {
+		if method == DetrendLog {
+			v = math.Log(v)
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// The least-squares line of the finite values against their indices.
+func trendLine(values []float64) (slope, intercept float64, ok bool) {
+	var x, y []float64
+	for i, v := range values {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			x = append(x, float64(i))
+			y = append(y, v)
+		}
+	}
+	slope, ok = LinearSlope(x, y)
+	if !ok {
+		return 0, 0, false
+	}
+	return slope, mean(y) - slope*mean(x), true
+}
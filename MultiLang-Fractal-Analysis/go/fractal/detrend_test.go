@@ -0,0 +1,83 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDetrendRemovesTrend(t *testing.T) {
+	n := 500
+	line := make([]float64, n)
+	growth := make([]float64, n)
+	for i := range line {
+		line[i] = 3 + 0.7*float64(i)
+		growth[i] = 100 * math.Exp(0.01*float64(i))
+	}
+	tests := []struct {
+		name   string
+		series []float64
+		method DetrendMethod
+	}{
+		{"linear trend", line, DetrendLinear},
+		{"exponential trend on log prices", growth, DetrendLog},
+	}
+	for _, tt := range tests {
+		for i, v := range Detrend(tt.series, tt.method) {
+			if math.Abs(v) > 1e-9 {
+				t.Errorf("%s: residual %v at %d, want ~0", tt.name, v, i)
+				break
+			}
+		}
+	}
+
+	if got := Detrend(line, DetrendNone); &got[0] != &line[0] {
+		t.Error("DetrendNone copied the series")
+	}
+	withGap := append([]float64(nil), line...)
+	withGap[10] = math.NaN()
+	if got := Detrend(withGap, DetrendLinear); !math.IsNaN(got[10]) || math.Abs(got[11]) > 1e-9 {
+		t.Errorf("NaN point: got %v, %v around it, want NaN then ~0", got[10], got[11])
+	}
+}
+
+// A strong drift makes a random walk look like a line; removing it leaves
+// the walk's own roughness and a clearly different dimension.
+func TestDetrendChangesDimension(t *testing.T) {
+	walk := randomWalk(1, 2000)
+	for i := range walk {
+		walk[i] += 2 * float64(i)
+	}
+	raw, _ := BoxCountingFit(walk)
+	detrended, _ := BoxCountingFit(Detrend(walk, DetrendLinear))
+	if raw-detrended < 0.2 {
+		t.Errorf("dimension %v raw and %v detrended, want detrending to lower it by 0.2 or more", raw, detrended)
+	}
+}
+
+// Replicates and surrogates are detrended as the window is, so with a strong
+// drift they move with the point estimate.
+func TestDetrendAppliesToReplicates(t *testing.T) {
+	walk := randomWalk(3, 1000)
+	data := make([]MarketCandle, len(walk))
+	for i, p := range walk {
+		p += 2 * float64(i)
+		data[i] = MarketCandle{Timestamp: time.Unix(int64(i)*3600, 0), Price: p, Low: p - 0.5, High: p + 0.5}
+	}
+	windows := []Window{{0, len(data)}}
+	for _, highLow := range []bool{false, true} {
+		results := map[DetrendMethod]FractalResult{}
+		for _, method := range []DetrendMethod{DetrendNone, DetrendLinear} {
+			a := Analyzer{Workers: 1, HighLow: highLow, Bootstrap: 20, Surrogates: 20, Seed: 1, Detrend: method}
+			results[method] = a.Analyze(data, windows)[0]
+		}
+		raw, detrended := results[DetrendNone], results[DetrendLinear]
+		if raw.BootstrapMean-detrended.BootstrapMean < 0.2 || raw.SurrogateMean-detrended.SurrogateMean < 0.2 {
+			t.Errorf("HighLow %v: replicate means %v and %v barely move when detrended to %v and %v", highLow,
+				raw.BootstrapMean, raw.SurrogateMean, detrended.BootstrapMean, detrended.SurrogateMean)
+		}
+		if d := detrended; d.Dimension < d.BootstrapP05 || d.Dimension > d.BootstrapP95 {
+			t.Errorf("HighLow %v: detrended dimension %v outside its interval [%v, %v]", highLow, d.Dimension, d.BootstrapP05, d.BootstrapP95)
+		}
+	}
+}
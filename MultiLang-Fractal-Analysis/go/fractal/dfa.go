@@ -0,0 +1,231 @@
+package fractal
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultMFDFAQs are the moment orders reported by the MFDFA output.
+var DefaultMFDFAQs = []float64{-4, -3, -2, -1, 0, 1, 2, 3, 4}
+
+// DFA performs detrended fluctuation analysis, returning the scaling exponent alpha of
+// the RMS fluctuation F(n) ~ n^alpha. For a stationary (noise-like) input
+// alpha estimates the Hurst exponent directly: 0.5 is uncorrelated, above
+// is persistent, below anti-persistent. Integrated (random-walk) inputs
+// come out near H+1. order is the detrending polynomial degree; values
+// below 1 fall back to linear detrending.
+func DFA(series []float64, order int) float64 {
+	sizes, variances := detrendedVariances(series, order)
+
+	var logN, logF []float64
+	for i, n := range sizes {
+		sum := 0.0
+		for _, v := range variances[i] {
+			sum += v
+		}
+		f := math.Sqrt(sum / float64(len(variances[i])))
+		if f > 0 {
+			logN = append(logN, math.Log(float64(n)))
+			logF = append(logF, math.Log(f))
+		}
+	}
+
+	if len(logN) < 2 {
+		return 0.5
+	}
+
+	alpha, ok := LinearSlope(logN, logF)
+	if !ok {
+		return 0.5
+	}
+	return alpha
+}
+
+// MFDFA performs multifractal DFA, returning the generalized Hurst exponent
+// h(q) for each moment order q. The q-th order fluctuation averages the
+// per-box variances raised to q/2 (a logarithmic average for q = 0), so
+// h(2) equals DFA's alpha. A monofractal series has a flat h(q); the spread
+// between negative and positive q measures multifractality. Series too
+// short to analyse report 0.5 for every q.
+func MFDFA(series []float64, qs []float64, order int) map[float64]float64 {
+	sizes, variances := detrendedVariances(series, order)
+
+	hq := make(map[float64]float64, len(qs))
+	for _, q := range qs {
+		var logN, logF []float64
+		for i, n := range sizes {
+			sum, count := 0.0, 0
+			for _, v := range variances[i] {
+				if v <= 0 {
+					continue // a perfectly fitted box has no defined moment for q < 0
+				}
+				if q == 0 {
+					sum += math.Log(v)
+				} else {
+					sum += math.Pow(v, q/2)
+				}
+				count++
+			}
+			if count == 0 {
+				continue
+			}
+
+			var logFq float64
+			if q == 0 {
+				logFq = 0.5 * sum / float64(count)
+			} else {
+				logFq = math.Log(sum/float64(count)) / q
+			}
+			logN = append(logN, math.Log(float64(n)))
+			logF = append(logF, logFq)
+		}
+
+		if len(logN) < 2 {
+			hq[q] = 0.5
+			continue
+		}
+		h, ok := LinearSlope(logN, logF)
+		if !ok {
+			h = 0.5
+		}
+		hq[q] = h
+	}
+	return hq
+}
+
+// Multifractality returns the width h(qmin) - h(qmax) of an MFDFA spectrum.
+func Multifractality(hq map[float64]float64) float64 {
+	qs := sortedQs(hq)
+	if len(qs) < 2 {
+		return 0
+	}
+	return hq[qs[0]] - hq[qs[len(qs)-1]]
+}
+
+func sortedQs(hq map[float64]float64) []float64 {
+	qs := make([]float64, 0, len(hq))
+	for q := range hq {
+		qs = append(qs, q)
+	}
+	sort.Float64s(qs)
+	return qs
+}
+
+// Shared DFA core: integrates the mean-subtracted series, then for a range
+// of logarithmically spaced box sizes detrends each non-overlapping box
+// with a polynomial and records its mean squared residual.
+func detrendedVariances(series []float64, order int) (sizes []int, variances [][]float64) {
+	if order < 1 {
+		order = 1
+	}
+	if len(series) < 16 {
+		return nil, nil
+	}
+
+	// Integrated profile of the mean-subtracted series
+	m := mean(series)
+	profile := make([]float64, len(series))
+	cum := 0.0
+	for i, v := range series {
+		cum += v - m
+		profile[i] = cum
+	}
+
+	// Logarithmically spaced box sizes, large enough to fit the polynomial
+	minBox := order + 3
+	if minBox < 4 {
+		minBox = 4
+	}
+	last := 0
+	for size := float64(minBox); int(size) <= len(series)/4; size *= 1.25 {
+		n := int(size)
+		if n == last {
+			continue
+		}
+		last = n
+
+		boxes := len(series) / n
+		x := make([]float64, n)
+		for j := range x {
+			x[j] = float64(j) / float64(n)
+		}
+
+		v := make([]float64, boxes)
+		for b := 0; b < boxes; b++ {
+			seg := profile[b*n : (b+1)*n]
+			coef := polyFit(x, seg, order)
+			ss := 0.0
+			for j, y := range seg {
+				dev := y - polyEval(coef, x[j])
+				ss += dev * dev
+			}
+			v[b] = ss / float64(n)
+		}
+
+		sizes = append(sizes, n)
+		variances = append(variances, v)
+	}
+	return sizes, variances
+}
+
+// Least-squares polynomial fit, coefficients from constant term upward.
+// Solves the normal equations with Gaussian elimination.
+func polyFit(x, y []float64, order int) []float64 {
+	m := order + 1
+	a := make([][]float64, m)
+	for i := range a {
+		a[i] = make([]float64, m+1)
+	}
+
+	for k := range x {
+		pows := make([]float64, 2*m-1)
+		pows[0] = 1
+		for p := 1; p < len(pows); p++ {
+			pows[p] = pows[p-1] * x[k]
+		}
+		for i := 0; i < m; i++ {
+			for j := 0; j < m; j++ {
+				a[i][j] += pows[i+j]
+			}
+			a[i][m] += pows[i] * y[k]
+		}
+	}
+
+	for col := 0; col < m; col++ {
+		pivot := col
+		for r := col + 1; r < m; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		if math.Abs(a[col][col]) < 1e-12 {
+			continue
+		}
+		for r := 0; r < m; r++ {
+			if r == col {
+				continue
+			}
+			factor := a[r][col] / a[col][col]
+			for c := col; c <= m; c++ {
+				a[r][c] -= factor * a[col][c]
+			}
+		}
+	}
+
+	coef := make([]float64, m)
+	for i := 0; i < m; i++ {
+		if math.Abs(a[i][i]) >= 1e-12 {
+			coef[i] = a[i][m] / a[i][i]
+		}
+	}
+	return coef
+}
+
+func polyEval(coef []float64, x float64) float64 {
+	y := 0.0
+	for i := len(coef) - 1; i >= 0; i-- {
+		y = y*x + coef[i]
+	}
+	return y
+}
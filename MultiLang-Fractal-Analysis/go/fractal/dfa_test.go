@@ -0,0 +1,74 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// Seeded unit-variance white noise.
+func whiteNoise(seed int64, n int) []float64 {
+	rng := NewRand(seed, 0)
+	noise := make([]float64, n)
+	for i := range noise {
+		noise[i] = rng.NormFloat64()
+	}
+	return noise
+}
+
+func TestDFA(t *testing.T) {
+	tests := []struct {
+		name   string
+		series []float64
+		want   float64
+	}{
+		{"white noise", whiteNoise(1, 4096), 0.5},
+		{"random walk", randomWalk(1, 4096), 1.5},
+	}
+	for _, tt := range tests {
+		for _, order := range []int{1, 2} {
+			if alpha := DFA(tt.series, order); math.Abs(alpha-tt.want) > 0.1 {
+				t.Errorf("%s, order %d: alpha %v, want %v ± 0.1", tt.name, order, alpha, tt.want)
+			}
+		}
+	}
+
+	if alpha := DFA(make([]float64, 100), 1); alpha != 0.5 {
+		t.Errorf("constant series: alpha %v, want the 0.5 fallback", alpha)
+	}
+}
+
+// h(2) is DFA's alpha; white noise, the returns of a GBM path, keeps h(q)
+// nearly flat, while a multiplicative cascade's h(q) falls steadily in q.
+func TestMFDFA(t *testing.T) {
+	noise := whiteNoise(24, 4096)
+	hq := MFDFA(noise, DefaultMFDFAQs, 1)
+	if alpha := DFA(noise, 1); math.Abs(hq[2]-alpha) > 1e-12 {
+		t.Errorf("h(2) %v, want DFA alpha %v", hq[2], alpha)
+	}
+	if width := Multifractality(hq); math.Abs(width) > 0.2 {
+		t.Errorf("white noise: width %v, want h(q) within 0.2 across q: %v", width, hq)
+	}
+
+	path := cascadeStaircase(12, 0.7)
+	cascade := make([]float64, len(path))
+	cascade[0] = path[0]
+	for i := 1; i < len(path); i++ {
+		cascade[i] = path[i] - path[i-1]
+	}
+	hq = MFDFA(cascade, DefaultMFDFAQs, 1)
+	qs := sortedQs(hq)
+	for i := 1; i < len(qs); i++ {
+		if !(hq[qs[i]] < hq[qs[i-1]]) {
+			t.Errorf("cascade: h(%v) %v, want below h(%v) %v", qs[i], hq[qs[i]], qs[i-1], hq[qs[i-1]])
+		}
+	}
+	if width := Multifractality(hq); !(width > 0.5) {
+		t.Errorf("cascade: width %v, want above 0.5", width)
+	}
+
+	for q, h := range MFDFA(make([]float64, 10), DefaultMFDFAQs, 1) {
+		if h != 0.5 {
+			t.Errorf("ten points: h(%v) %v, want the 0.5 fallback", q, h)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package fractal
+
+import "math"
+
+// Defaults for the per-window entropy estimates: template length, tolerance
+// as a multiple of the window's standard deviation, and the most recent
+// returns kept so the O(n²) matching stays affordable on long windows.
+const (
+	DefaultEntropyM      = 2
+	DefaultEntropyR      = 0.2
+	DefaultEntropyPoints = 1000
+)
+
+// SampleEntropy is -ln(A/B), where B counts pairs of length-m templates
+// within Chebyshev distance r and A the pairs that still match at length
+// m+1 (Richman & Moorman 2000). Self-matches are excluded. Regular series
+// score near 0; NaN means no templates matched and +Inf that none extended.
+//
+// The cost is O(n²) in len(series); subsample long series before calling.
+func SampleEntropy(series []float64, m int, r float64) float64 {
+	n := len(series)
+	if m < 1 || n <= m+1 {
+		return math.NaN()
+	}
+
+	// Same n-m templates at both lengths so A and B are comparable
+	var a, b int
+	for i := 0; i < n-m; i++ {
+		for j := i + 1; j < n-m; j++ {
+			if !templatesMatch(series, i, j, m, r) {
+				continue
+			}
+			b++
+			if math.Abs(series[i+m]-series[j+m]) <= r {
+				a++
+			}
+		}
+	}
+
+	if b == 0 {
+		return math.NaN()
+	}
+	if a == 0 {
+		return math.Inf(1)
+	}
+	return -math.Log(float64(a) / float64(b))
+}
+
+// ApproximateEntropy is Φ(m) - Φ(m+1), Φ(k) being the mean log fraction of
+// length-k templates within Chebyshev distance r of each template,
+// self-matches included (Pincus 1991). It is biased towards regularity on
+// short series, which SampleEntropy avoids.
+//
+// The cost is O(n²) in len(series); subsample long series before calling.
+func ApproximateEntropy(series []float64, m int, r float64) float64 {
+	if m < 1 || len(series) <= m+1 {
+		return math.NaN()
+	}
+	return approxEntropyPhi(series, m, r) - approxEntropyPhi(series, m+1, r)
+}
+
+func approxEntropyPhi(series []float64, m int, r float64) float64 {
+	count := len(series) - m + 1
+	total := 0.0
+	for i := 0; i < count; i++ {
+		matches := 0
+		for j := 0; j < count; j++ {
+			if templatesMatch(series, i, j, m, r) {
+				matches++
+			}
+		}
+		total += math.Log(float64(matches) / float64(count))
+	}
+	return total / float64(count)
+}
+
+// Reports whether the length-m templates at i and j are within r.
+func templatesMatch(series []float64, i, j, m int, r float64) bool {
+	for k := 0; k < m; k++ {
+		if math.Abs(series[i+k]-series[j+k]) > r {
+			return false
+		}
+	}
+	return true
+}
+
+// Population standard deviation; 0 for fewer than two points.
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	n := float64(len(values))
+	return math.Sqrt(variance(values) * (n - 1) / n)
+}
@@ -0,0 +1,44 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSampleAndApproximateEntropy(t *testing.T) {
+	constant := make([]float64, 300)
+	for i := range constant {
+		constant[i] = 5
+	}
+	noise := whiteNoise(28, 1000)
+	sine := make([]float64, 1000)
+	for i := range sine {
+		sine[i] = math.Sin(2 * math.Pi * float64(i) / 50)
+	}
+	tests := []struct {
+		name     string
+		series   []float64
+		min, max float64 // for both estimates
+	}{
+		{"constant", constant, 0, 0},
+		{"sine", sine, 0, 0.5},
+		{"white noise", noise, 1.5, 3},
+	}
+	for _, tt := range tests {
+		r := DefaultEntropyR * stdDev(tt.series)
+		sampEn := SampleEntropy(tt.series, DefaultEntropyM, r)
+		apEn := ApproximateEntropy(tt.series, DefaultEntropyM, r)
+		for _, e := range []struct {
+			name  string
+			value float64
+		}{{"sample", sampEn}, {"approximate", apEn}} {
+			if !(e.value >= tt.min-1e-12 && e.value <= tt.max+1e-12) {
+				t.Errorf("%s: %s entropy %v, want in [%v, %v]", tt.name, e.name, e.value, tt.min, tt.max)
+			}
+		}
+	}
+
+	if got := SampleEntropy([]float64{1, 2, 3}, 2, 0.1); !math.IsNaN(got) {
+		t.Errorf("three points: sample entropy %v, want NaN", got)
+	}
+}
@@ -0,0 +1,165 @@
+package fractal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Estimator is a named fractal dimension estimator that can be selected
+// by name with -method and in POST /fractal requests.
+type Estimator interface {
+	Name() string
+	Dimension(series []float64) (float64, error)
+}
+
+// ErrShortSeries is returned by the built-in estimators for series with
+// fewer than MinEstimatorPoints points.
+var ErrShortSeries = errors.New("series too short for a dimension")
+
+// MinEstimatorPoints is the shortest series the built-in estimators take.
+const MinEstimatorPoints = 4
+
+// EstimatorFunc adapts a plain dimension function with the package's
+// degenerate-input conventions into an Estimator: short series are an
+// error, as is a NaN or infinite result.
+func EstimatorFunc(name string, fn func([]float64) float64) Estimator {
+	return funcEstimator{name, fn}
+}
+
+type funcEstimator struct {
+	name string
+	fn   func([]float64) float64
+}
+
+func (e funcEstimator) Name() string { return e.name }
+
+func (e funcEstimator) Dimension(series []float64) (float64, error) {
+	if len(series) < MinEstimatorPoints {
+		return 0, ErrShortSeries
+	}
+	d := e.fn(series)
+	if math.IsNaN(d) || math.IsInf(d, 0) {
+		return 0, fmt.Errorf("%s: no finite dimension", e.name)
+	}
+	return d, nil
+}
+
+// Name implements Estimator for box counting.
+func (b BoxCounter) Name() string { return "box" }
+
+// Dimension implements Estimator with b's settings.
+func (b BoxCounter) Dimension(series []float64) (float64, error) {
+	if len(series) < MinEstimatorPoints {
+		return 0, ErrShortSeries
+	}
+	d, _ := b.Fit(series)
+	return d, nil
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Estimator{}
+)
+
+func init() {
+	for _, e := range []Estimator{
+		BoxCounter{},
+		EstimatorFunc("higuchi", HiguchiFractalDimension),
+		EstimatorFunc("katz", KatzFractalDimension),
+		EstimatorFunc("petrosian", PetrosianFractalDimension),
+		EstimatorFunc("variogram", VariogramFractalDimension),
+		EstimatorFunc("wavelet", WaveletFractalDimension),
+	} {
+		Register(e)
+	}
+}
+
+// Register makes e selectable by its name. Like database/sql drivers it is
+// meant to be called from an init function, and it panics if the name is
+// empty or already taken.
+func Register(e Estimator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name := e.Name()
+	if name == "" {
+		panic("fractal: Register estimator with empty name")
+	}
+	if _, dup := registry[name]; dup {
+		panic("fractal: Register called twice for estimator " + name)
+	}
+	registry[name] = e
+}
+
+// LookupEstimator returns the estimator registered under name.
+func LookupEstimator(name string) (Estimator, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	e, ok := registry[name]
+	return e, ok
+}
+
+// EstimatorNames lists the registered estimator names in sorted order.
+func EstimatorNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseEstimators resolves a comma-separated list of registered names.
+func ParseEstimators(spec string) ([]Estimator, error) {
+	var out []Estimator
+	for _, part := range strings.Split(spec, ",") {
+		name := strings.TrimSpace(part)
+		if name == "" {
+			continue
+		}
+		e, ok := LookupEstimator(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown method %q (want %s)", name, strings.Join(EstimatorNames(), ", "))
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// EstimatorAgreement returns the mean absolute difference between every
+// pair of the results' Methods, indexed in their order, averaged over the
+// windows where both estimators gave a dimension. Pairs that never do are
+// NaN; the diagonal is 0.
+func EstimatorAgreement(results []FractalResult) [][]float64 {
+	if len(results) == 0 {
+		return nil
+	}
+	k := len(results[0].Methods)
+	mad := make([][]float64, k)
+	for i := range mad {
+		mad[i] = make([]float64, k)
+		for j := range mad[i] {
+			if i == j {
+				continue
+			}
+			sum, n := 0.0, 0
+			for _, r := range results {
+				di, dj := r.Methods[i].Dimension, r.Methods[j].Dimension
+				if !math.IsNaN(di) && !math.IsNaN(dj) {
+					sum += math.Abs(di - dj)
+					n++
+				}
+			}
+			mad[i][j] = math.NaN()
+			if n > 0 {
+				mad[i][j] = sum / float64(n)
+			}
+		}
+	}
+	return mad
+}
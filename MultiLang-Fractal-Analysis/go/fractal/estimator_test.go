@@ -0,0 +1,127 @@
+package fractal
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Reports the window's length, so each window's value is known.
+type lengthEstimator struct{}
+
+func (lengthEstimator) Name() string { return "test-length" }
+
+func (lengthEstimator) Dimension(series []float64) (float64, error) {
+	return float64(len(series)), nil
+}
+
+func init() {
+	Register(lengthEstimator{})
+}
+
+func TestEstimatorRegistry(t *testing.T) {
+	if !slices.Contains(EstimatorNames(), "test-length") || !slices.IsSorted(EstimatorNames()) {
+		t.Errorf("names %v, want test-length among them in order", EstimatorNames())
+	}
+	got, err := ParseEstimators(" box , test-length,")
+	if err != nil || len(got) != 2 || got[0].Name() != "box" || got[1].Name() != "test-length" {
+		t.Fatalf("ParseEstimators: %v, %v", got, err)
+	}
+	if _, err := ParseEstimators("box,nope"); err == nil || !strings.Contains(err.Error(), `unknown method "nope"`) {
+		t.Errorf("unknown name: error %v", err)
+	}
+
+	for _, name := range []string{"box", "test-length"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("registering %s twice did not panic", name)
+				}
+			}()
+			e, _ := LookupEstimator(name)
+			Register(e)
+		}()
+	}
+}
+
+// Selected estimators add one dimension per window to Run's results in
+// the order given.
+func TestRunEstimators(t *testing.T) {
+	data := GenerateSeries(NewRand(70, 0), 600, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	estimators, err := ParseEstimators("test-length,katz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Analyzer{Estimators: estimators}.Run(context.Background(), data, []Window{{0, 100}, {100, 500}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		size := float64(r.WindowEnd - r.WindowStart + 1)
+		katz := KatzFractalDimension(SeriesPrice.Values(data[r.WindowStart : r.WindowEnd+1]))
+		if len(r.Methods) != 2 || r.Methods[0].Method != "test-length" || r.Methods[0].Dimension != size ||
+			r.Methods[1].Method != "katz" || math.Abs(r.Methods[1].Dimension-katz) > 1e-12 {
+			t.Errorf("window %d-%d: methods %+v, want test-length %v then katz %v", r.WindowStart, r.WindowEnd, r.Methods, size, katz)
+		}
+	}
+
+	if _, err := EstimatorFunc("short", KatzFractalDimension).Dimension([]float64{1, 2}); !errors.Is(err, ErrShortSeries) {
+		t.Errorf("short series: error %v, want ErrShortSeries", err)
+	}
+}
+
+// Two identical estimators agree exactly, a different one doesn't, and
+// the comparison CSV ends with the pairwise differences.
+func TestEstimatorAgreement(t *testing.T) {
+	data := GenerateSeries(NewRand(84, 0), 1000, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	katz, _ := LookupEstimator("katz")
+	higuchi, _ := LookupEstimator("higuchi")
+	estimators := []Estimator{katz, EstimatorFunc("katz-copy", KatzFractalDimension), higuchi}
+	results, err := Analyzer{Estimators: estimators}.Run(context.Background(), data, []Window{{0, 1000}, {0, 500}, {500, 500}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mad := EstimatorAgreement(results)
+	if len(mad) != 3 || mad[0][1] != 0 || mad[1][0] != 0 {
+		t.Fatalf("agreement %v, want 0 between katz and its copy", mad)
+	}
+	for i := range mad {
+		if mad[i][i] != 0 || i != 2 && !(mad[i][2] > 0 && mad[i][2] == mad[2][i]) {
+			t.Errorf("agreement %v, want a zero diagonal and symmetric positive differences from higuchi", mad)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "estimator_comparison.csv")
+	if err := WriteEstimatorComparisonCSV([]string{"full", "first", "second"}, results, mad, path); err != nil {
+		t.Fatal(err)
+	}
+	rows := readCSVFile(t, path)
+	if len(rows) != 1+3+3 || !slices.Equal(rows[0], []string{"Window", "WindowStart", "WindowEnd", "katz", "katz-copy", "higuchi"}) {
+		t.Fatalf("comparison CSV %v, want a header, 3 windows and 3 MAD rows", rows)
+	}
+	if row := rows[4]; row[0] != "MAD_katz" || row[3] != "0.000000" || row[4] != "0.000000" {
+		t.Errorf("MAD row %v, want zero against katz itself and its copy", row)
+	}
+
+	// Windows where an estimator has no dimension are left out of its pairs
+	nan := math.NaN()
+	byHand := []FractalResult{
+		{Methods: []MethodDimension{{"a", 1}, {"b", 2}, {"c", nan}}},
+		{Methods: []MethodDimension{{"a", 1}, {"b", nan}, {"c", nan}}},
+		{Methods: []MethodDimension{{"a", 2}, {"b", 5}, {"c", nan}}},
+	}
+	mad = EstimatorAgreement(byHand)
+	if mad[0][1] != 2 || !math.IsNaN(mad[0][2]) || !math.IsNaN(mad[2][1]) {
+		t.Errorf("agreement with missing dimensions %v, want a-b 2 and c NaN", mad)
+	}
+	if EstimatorAgreement(nil) != nil {
+		t.Error("agreement of no results is not nil")
+	}
+}
@@ -0,0 +1,79 @@
+package fractal
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Series lengths every estimator is benchmarked at.
+var benchSizes = []int{500, 5000, 50000}
+
+// Runs fn on the first n values of series for each benchmark size, with
+// the data generated before the timer starts.
+func benchmarkSizes(b *testing.B, series []float64, fn func([]float64) float64) {
+	for _, n := range benchSizes {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			window := series[:n]
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				fn(window)
+			}
+		})
+	}
+}
+
+// Prices and returns of a seeded generated market, long enough for the
+// largest benchmark size.
+func benchSeries() (prices, returns []float64) {
+	data := GenerateSeries(NewRand(1, 0), benchSizes[len(benchSizes)-1], 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	return SeriesPrice.Values(data), SeriesReturns.Values(data)
+}
+
+func BenchmarkBoxCounting(b *testing.B) {
+	prices, _ := benchSeries()
+	benchmarkSizes(b, prices, BoxCountingFractalDimension)
+}
+
+func BenchmarkHiguchi(b *testing.B) {
+	prices, _ := benchSeries()
+	benchmarkSizes(b, prices, HiguchiFractalDimension)
+}
+
+func BenchmarkKatz(b *testing.B) {
+	prices, _ := benchSeries()
+	benchmarkSizes(b, prices, KatzFractalDimension)
+}
+
+func BenchmarkPetrosian(b *testing.B) {
+	prices, _ := benchSeries()
+	benchmarkSizes(b, prices, PetrosianFractalDimension)
+}
+
+func BenchmarkVariogram(b *testing.B) {
+	prices, _ := benchSeries()
+	benchmarkSizes(b, prices, VariogramFractalDimension)
+}
+
+func BenchmarkWavelet(b *testing.B) {
+	prices, _ := benchSeries()
+	benchmarkSizes(b, prices, WaveletFractalDimension)
+}
+
+func BenchmarkDFA(b *testing.B) {
+	_, returns := benchSeries()
+	benchmarkSizes(b, returns, func(s []float64) float64 { return DFA(s, 1) })
+}
+
+func BenchmarkHurstRS(b *testing.B) {
+	_, returns := benchSeries()
+	benchmarkSizes(b, returns, HurstRS)
+}
+
+func BenchmarkBoxCountingGrid(b *testing.B) {
+	prices, _ := benchSeries()
+	benchmarkSizes(b, prices, func(s []float64) float64 {
+		d, _ := BoxCounter{Impl: BoxGrid}.Fit(s)
+		return d
+	})
+}
@@ -0,0 +1,169 @@
+package fractal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportVersion is the layout version WriteExportJSON stamps on documents
+// and ReadExportJSON accepts.
+const ExportVersion = 1
+
+// Export is everything about one run in a single archivable document:
+// the settings it ran with, its summary and window results, and
+// optionally the candles themselves.
+type Export struct {
+	Version int               // ExportVersion when written
+	Config  map[string]string // setting name to value, as given on the command line
+	Summary []Metric
+	Results []FractalResult
+	Candles []MarketCandle // nil leaves them out of the document
+}
+
+type exportJSON struct {
+	Version int               `json:"version"`
+	Config  map[string]string `json:"config"`
+	Summary []metricJSON      `json:"summary"`
+	Results []resultJSON      `json:"results"`
+	Candles []candleJSON      `json:"candles,omitempty"`
+}
+
+// A summary metric with its value's type kept: floats are always written
+// with a decimal point or exponent, so they don't read back as ints.
+type metricJSON struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+type candleJSON struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Price      jsonFloat `json:"price"`
+	Volume     jsonFloat `json:"volume"`
+	Returns    jsonFloat `json:"returns"`
+	Volatility jsonFloat `json:"volatility"`
+	Open       jsonFloat `json:"open"`
+	High       jsonFloat `json:"high"`
+	Low        jsonFloat `json:"low"`
+	Close      jsonFloat `json:"close"`
+}
+
+func newMetricJSON(m Metric) (metricJSON, error) {
+	var raw []byte
+	var err error
+	switch v := m.Value.(type) {
+	case float64:
+		raw, err = jsonFloat(v).MarshalJSON()
+		if s := string(raw); err == nil && s != "null" && !strings.ContainsAny(s, ".eE") {
+			raw = append(raw, ".0"...)
+		}
+	default:
+		raw, err = json.Marshal(v)
+	}
+	return metricJSON{m.Name, raw}, err
+}
+
+// The Metric doc was made from: numbers with a decimal point or exponent
+// are float64, other numbers int, null NaN and anything else a string.
+func (doc metricJSON) metric() (Metric, error) {
+	s := strings.TrimSpace(string(doc.Value))
+	switch {
+	case s == "null":
+		return Metric{doc.Name, math.NaN()}, nil
+	case strings.HasPrefix(s, `"`):
+		var v string
+		err := json.Unmarshal(doc.Value, &v)
+		return Metric{doc.Name, v}, err
+	case strings.ContainsAny(s, ".eE"):
+		v, err := strconv.ParseFloat(s, 64)
+		return Metric{doc.Name, v}, err
+	}
+	v, err := strconv.Atoi(s)
+	return Metric{doc.Name, v}, err
+}
+
+// WriteExportJSON writes doc as a single indented JSON document, stamped
+// with ExportVersion. NaN values are written as null.
+func WriteExportJSON(doc Export, filename string) error {
+	out := exportJSON{
+		Version: ExportVersion,
+		Config:  doc.Config,
+		Summary: make([]metricJSON, len(doc.Summary)),
+		Results: make([]resultJSON, len(doc.Results)),
+	}
+	for i, m := range doc.Summary {
+		var err error
+		if out.Summary[i], err = newMetricJSON(m); err != nil {
+			return fmt.Errorf("summary %s: %w", m.Name, err)
+		}
+	}
+	for i, r := range doc.Results {
+		out.Results[i] = newResultJSON(r)
+	}
+	for _, c := range doc.Candles {
+		out.Candles = append(out.Candles, candleJSON{
+			Timestamp:  c.Timestamp,
+			Price:      jsonFloat(c.Price),
+			Volume:     jsonFloat(c.Volume),
+			Returns:    jsonFloat(c.Returns),
+			Volatility: jsonFloat(c.Volatility),
+			Open:       jsonFloat(c.Open),
+			High:       jsonFloat(c.High),
+			Low:        jsonFloat(c.Low),
+			Close:      jsonFloat(c.Close),
+		})
+	}
+
+	raw, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(raw, '\n'), 0644)
+}
+
+// ReadExportJSON reads a document written by WriteExportJSON back into an
+// Export, rejecting versions it doesn't know.
+func ReadExportJSON(filename string) (Export, error) {
+	var doc Export
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return doc, err
+	}
+	var in exportJSON
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return doc, fmt.Errorf("%s: %w", filename, err)
+	}
+	if in.Version != ExportVersion {
+		return doc, fmt.Errorf("%s: export version %d, want %d", filename, in.Version, ExportVersion)
+	}
+
+	doc = Export{Version: in.Version, Config: in.Config}
+	for _, m := range in.Summary {
+		metric, err := m.metric()
+		if err != nil {
+			return Export{}, fmt.Errorf("%s: summary %s: %w", filename, m.Name, err)
+		}
+		doc.Summary = append(doc.Summary, metric)
+	}
+	for _, r := range in.Results {
+		doc.Results = append(doc.Results, r.result())
+	}
+	for _, c := range in.Candles {
+		doc.Candles = append(doc.Candles, MarketCandle{
+			Timestamp:  c.Timestamp,
+			Price:      float64(c.Price),
+			Volume:     float64(c.Volume),
+			Returns:    float64(c.Returns),
+			Volatility: float64(c.Volatility),
+			Open:       float64(c.Open),
+			High:       float64(c.High),
+			Low:        float64(c.Low),
+			Close:      float64(c.Close),
+		})
+	}
+	return doc, nil
+}
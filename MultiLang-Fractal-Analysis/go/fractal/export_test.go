@@ -0,0 +1,92 @@
+package fractal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// A document reads back into the structs it was written from, metric
+// value types and NaNs included, with or without its candles.
+func TestExportRoundTrip(t *testing.T) {
+	data := GenerateSeries(NewRand(98, 0), 600, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	katz, _ := LookupEstimator("katz")
+	results, err := Analyzer{Estimators: []Estimator{katz}, Bootstrap: 10, Seed: 98}.Run(context.Background(), data, []Window{{0, 600}, {100, 300}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := Summarize(data, results, Metric{"Note", "a, \"quoted\" note"}, Metric{"Whole", 2.0}, Metric{"Undefined", math.NaN()})
+	doc := Export{
+		Config:  map[string]string{"n": "600", "seed": "98", "method": "katz"},
+		Summary: summary,
+		Results: results,
+		Candles: data,
+	}
+
+	dir := t.TempDir()
+	for _, withCandles := range []bool{true, false} {
+		in := doc
+		if !withCandles {
+			in.Candles = nil
+		}
+		path := filepath.Join(dir, fmt.Sprintf("export-%v.json", withCandles))
+		if err := WriteExportJSON(in, path); err != nil {
+			t.Fatal(err)
+		}
+		out, err := ReadExportJSON(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if out.Version != ExportVersion || !maps.Equal(out.Config, in.Config) {
+			t.Errorf("candles %v: version %d config %v, want %d and %v", withCandles, out.Version, out.Config, ExportVersion, in.Config)
+		}
+		if len(out.Summary) != len(summary) {
+			t.Fatalf("candles %v: %d metrics, want %d", withCandles, len(out.Summary), len(summary))
+		}
+		for i, m := range out.Summary {
+			want := summary[i]
+			if m.Name != want.Name || reflect.TypeOf(m.Value) != reflect.TypeOf(want.Value) || m.String() != want.String() {
+				t.Errorf("candles %v: metric %s %T %v, want %s %T %v", withCandles, m.Name, m.Value, m.Value, want.Name, want.Value, want.Value)
+			}
+		}
+		// Sprint compares the NaN fields an unrun bootstrap leaves as well
+		if got, want := fmt.Sprint(out.Results), fmt.Sprint(results); got != want {
+			t.Errorf("candles %v: results\n%s\nwant\n%s", withCandles, got, want)
+		}
+
+		if !withCandles {
+			raw, _ := os.ReadFile(path)
+			if out.Candles != nil || strings.Contains(string(raw), `"candles"`) {
+				t.Errorf("candles left out, but the document holds %d", len(out.Candles))
+			}
+			continue
+		}
+		if len(out.Candles) != len(data) {
+			t.Fatalf("%d candles, want %d", len(out.Candles), len(data))
+		}
+		for i, c := range out.Candles {
+			w := data[i]
+			if !c.Timestamp.Equal(w.Timestamp) || c.Price != w.Price || c.Volume != w.Volume || c.Returns != w.Returns ||
+				c.Volatility != w.Volatility || c.Open != w.Open || c.High != w.High || c.Low != w.Low || c.Close != w.Close {
+				t.Errorf("candle %d %+v, want %+v", i, c, w)
+				break
+			}
+		}
+	}
+
+	future := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(future, []byte(`{"version": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadExportJSON(future); err == nil || !strings.Contains(err.Error(), "export version 2") {
+		t.Errorf("version 2: error %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// The Hurst exponent estimators recover the exponent fBm was generated
+// with from its log-returns.
+func TestGenerateFBMSeriesHurst(t *testing.T) {
+	estimators := []struct {
+		name string
+		fn   func([]float64) float64
+		tol  float64
+	}{
+		{"DFA", func(r []float64) float64 { return DFA(r, 1) }, 0.08},
+		{"HurstRS", HurstRS, 0.1},
+	}
+	for _, hurst := range []float64{0.3, 0.7} {
+		data := GenerateFBMSeries(NewRand(12, 0), 8192, 100, 0.01, hurst)
+		returns := logReturns(data, 100)
+		for _, e := range estimators {
+			got := e.fn(returns)
+			if math.Abs(got-hurst) > e.tol {
+				t.Errorf("H %v: %s %v, want within %v", hurst, e.name, got, e.tol)
+			}
+		}
+	}
+
+	if GenerateFBM(NewRand(12, 0), 10, 1) != nil {
+		t.Error("H 1: want nil")
+	}
+}
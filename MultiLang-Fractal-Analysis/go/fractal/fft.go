@@ -0,0 +1,108 @@
+package fractal
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// In-place iterative radix-2 FFT. len(a) must be a power of two.
+// The inverse transform is scaled by 1/len(a).
+func fft(a []complex128, inverse bool) {
+	n := len(a)
+
+	// Bit-reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		w := cmplx.Exp(complex(0, sign*2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			wk := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u := a[start+k]
+				v := a[start+k+size/2] * wk
+				a[start+k] = u + v
+				a[start+k+size/2] = u - v
+				wk *= w
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1/float64(n), 0)
+		for i := range a {
+			a[i] *= scale
+		}
+	}
+}
+
+func nextPow2(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// Discrete Fourier transform of any length, returned in a new slice with
+// the same scaling as fft. Powers of two go straight to fft; other lengths
+// use Bluestein's chirp-z identity, which recasts the transform as a
+// convolution that padded power-of-two FFTs can compute.
+func dft(x []complex128, inverse bool) []complex128 {
+	n := len(x)
+	out := append([]complex128(nil), x...)
+	if n == 0 || n&(n-1) == 0 {
+		fft(out, inverse)
+		return out
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	// chirp[k] = exp(sign·iπk²/n); k² is reduced mod 2n to keep the angle
+	// small and exact for long series
+	chirp := make([]complex128, n)
+	for k := range chirp {
+		k2 := (k * k) % (2 * n)
+		chirp[k] = cmplx.Exp(complex(0, sign*math.Pi*float64(k2)/float64(n)))
+	}
+
+	m := nextPow2(2*n - 1)
+	a := make([]complex128, m)
+	b := make([]complex128, m)
+	for k := 0; k < n; k++ {
+		a[k] = x[k] * chirp[k]
+	}
+	b[0] = cmplx.Conj(chirp[0])
+	for k := 1; k < n; k++ {
+		b[k] = cmplx.Conj(chirp[k])
+		b[m-k] = b[k]
+	}
+	fft(a, false)
+	fft(b, false)
+	for i := range a {
+		a[i] *= b[i]
+	}
+	fft(a, true)
+
+	for k := range out {
+		out[k] = a[k] * chirp[k]
+		if inverse {
+			out[k] /= complex(float64(n), 0)
+		}
+	}
+	return out
+}
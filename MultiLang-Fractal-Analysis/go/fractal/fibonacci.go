@@ -0,0 +1,109 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+)
+
+// FibonacciRatios are the retracement ratios FibonacciLevels reports.
+var FibonacciRatios = []float64{0.236, 0.382, 0.5, 0.618, 0.786}
+
+// DefaultSwingMove is the smallest reversal, as a fraction of the last
+// swing point's price, that SwingPoints treats as a new swing.
+const DefaultSwingMove = 0.05
+
+// FibonacciKey names a ratio in the map FibonacciLevels returns, as
+// "0.236" or "0.500".
+func FibonacciKey(ratio float64) string {
+	return fmt.Sprintf("%.3f", ratio)
+}
+
+// FibonacciLevels returns the retracement levels of a move between high
+// and low for each of FibonacciRatios, measured down from the high:
+// high - ratio*(high-low), keyed by FibonacciKey. The arguments are
+// swapped if low is above high; a move with no range has no levels and
+// yields nil.
+func FibonacciLevels(high, low float64) map[string]float64 {
+	if low > high {
+		high, low = low, high
+	}
+	if !(high > low) {
+		return nil
+	}
+	levels := make(map[string]float64, len(FibonacciRatios))
+	for _, r := range FibonacciRatios {
+		levels[FibonacciKey(r)] = high - r*(high-low)
+	}
+	return levels
+}
+
+// SwingPoint is a Williams fractal pivot kept as a swing extreme.
+type SwingPoint struct {
+	Index int
+	Price float64 // the candle's High for a high, Low for a low
+	High  bool
+}
+
+// SwingPoints reduces Williams fractal pivots to alternating swing highs
+// and lows, like a zig-zag: consecutive pivots of one kind collapse to the
+// most extreme of them, and a pivot of the other kind only starts a new
+// swing if it lies at least minMove (a fraction of price) from the last
+// swing point, so minor wiggles are ignored. bullish and bearish are the
+// DetectWilliamsFractals indices; a pivot both high and low is taken as a
+// low first. minMove <= 0 keeps every alternation.
+func SwingPoints(data []MarketCandle, bullish, bearish []int, minMove float64) []SwingPoint {
+	var points []SwingPoint
+	add := func(p SwingPoint) {
+		if len(points) == 0 {
+			points = append(points, p)
+			return
+		}
+		last := &points[len(points)-1]
+		if p.High == last.High {
+			if (p.High && p.Price > last.Price) || (!p.High && p.Price < last.Price) {
+				*last = p
+			}
+			return
+		}
+		if math.Abs(p.Price-last.Price) >= minMove*math.Abs(last.Price) {
+			points = append(points, p)
+		}
+	}
+
+	// Merge the two sorted index lists as WritePivotsCSV does
+	b, s := 0, 0
+	for b < len(bullish) || s < len(bearish) {
+		if s == len(bearish) || (b < len(bullish) && bullish[b] <= bearish[s]) {
+			add(SwingPoint{Index: bullish[b], Price: data[bullish[b]].Low})
+			b++
+		} else {
+			add(SwingPoint{Index: bearish[s], Price: data[bearish[s]].High, High: true})
+			s++
+		}
+	}
+	return points
+}
+
+// LastSwing returns the most recent swing, the last two of points, as its
+// start and end; ok is false with fewer than two points.
+func LastSwing(points []SwingPoint) (start, end SwingPoint, ok bool) {
+	if len(points) < 2 {
+		return SwingPoint{}, SwingPoint{}, false
+	}
+	return points[len(points)-2], points[len(points)-1], true
+}
+
+// SwingLevels returns the retracement levels of the swing from start to
+// end, measured back from where it ended: down from the high of an up
+// swing, as FibonacciLevels, and up from the low of a down swing.
+func SwingLevels(start, end SwingPoint) map[string]float64 {
+	levels := FibonacciLevels(start.Price, end.Price)
+	if end.High {
+		return levels
+	}
+	for key, v := range levels {
+		// Mirror within the range so ratios count up from the low
+		levels[key] = start.Price + end.Price - v
+	}
+	return levels
+}
@@ -0,0 +1,73 @@
+package fractal
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestFibonacciLevels(t *testing.T) {
+	want := map[string]float64{"0.236": 176.4, "0.382": 161.8, "0.500": 150, "0.618": 138.2, "0.786": 121.4}
+	for _, args := range [][2]float64{{200, 100}, {100, 200}} {
+		levels := FibonacciLevels(args[0], args[1])
+		if len(levels) != len(want) {
+			t.Errorf("FibonacciLevels(%v, %v) = %v, want %v", args[0], args[1], levels, want)
+			continue
+		}
+		for key, v := range want {
+			if math.Abs(levels[key]-v) > 1e-9 {
+				t.Errorf("FibonacciLevels(%v, %v)[%s] = %v, want %v", args[0], args[1], key, levels[key], v)
+			}
+		}
+	}
+	if levels := FibonacciLevels(150, 150); levels != nil {
+		t.Errorf("no range: levels %v, want nil", levels)
+	}
+}
+
+// Pivots reduce to alternating swings: a higher high replaces the last
+// one, reversals under 5% are ignored, and the last swing's levels count
+// back from where it ended.
+func TestSwingPoints(t *testing.T) {
+	data := make([]MarketCandle, 15)
+	lows := map[int]float64{1: 100, 7: 112, 9: 105}
+	highs := map[int]float64{3: 110, 5: 115, 11: 106, 13: 130}
+	for i := range data {
+		data[i].High, data[i].Low = 120, 110
+	}
+	for i, p := range lows {
+		data[i].Low = p
+	}
+	for i, p := range highs {
+		data[i].High = p
+	}
+
+	points := SwingPoints(data, []int{1, 7, 9}, []int{3, 5, 11, 13}, DefaultSwingMove)
+	want := []SwingPoint{{1, 100, false}, {5, 115, true}, {9, 105, false}, {13, 130, true}}
+	if !slices.Equal(points, want) {
+		t.Fatalf("swings %v, want %v", points, want)
+	}
+	if wide := SwingPoints(data, []int{1, 7, 9}, []int{3, 5, 11, 13}, 0.5); !slices.Equal(wide, want[:1]) {
+		t.Errorf("50%% minimum move: swings %v, want only %v", wide, want[:1])
+	}
+
+	start, end, ok := LastSwing(points)
+	if !ok || start != want[2] || end != want[3] {
+		t.Fatalf("last swing %v to %v, want %v to %v", start, end, want[2], want[3])
+	}
+	up := SwingLevels(start, end)
+	down := SwingLevels(want[1], want[2])
+	for _, r := range FibonacciRatios {
+		key := FibonacciKey(r)
+		if math.Abs(up[key]-(130-r*25)) > 1e-9 {
+			t.Errorf("up swing level %s %v, want %v", key, up[key], 130-r*25)
+		}
+		if math.Abs(down[key]-(105+r*10)) > 1e-9 {
+			t.Errorf("down swing level %s %v, want %v", key, down[key], 105+r*10)
+		}
+	}
+
+	if _, _, ok := LastSwing(points[:1]); ok {
+		t.Error("one swing point gave a last swing")
+	}
+}
@@ -0,0 +1,67 @@
+// Package fractal implements the fractal market analysis used by the
+// Go command: series generation, fractal and long-memory estimators, and
+// CSV input/output.
+//
+// The estimators take candles to be evenly spaced in time and work on
+// their indices, so a weekend or halt in the data is read as one large
+// move between adjacent candles. DetectGaps finds such gaps and FillGaps
+// pads them onto a uniform grid.
+package fractal
+
+import "time"
+
+// MarketCandle is a single period of market data. Price is the closing
+// price and always equals Close; it is kept for code written before
+// candles carried OHLC.
+type MarketCandle struct {
+	Timestamp  time.Time
+	Price      float64
+	Volume     float64
+	Returns    float64
+	Volatility float64
+	Open       float64
+	High       float64
+	Low        float64
+	Close      float64
+}
+
+// FractalResult holds the estimates for one window of a series, with
+// WindowStart and WindowEnd as inclusive candle indices.
+type FractalResult struct {
+	WindowStart int
+	WindowEnd   int
+	Dimension   float64
+	R2          float64 // goodness of fit of the dimension's log-log regression
+	Hurst       float64 // rescaled range, HurstRS
+	HurstAggVar float64 // aggregated variance, HurstAggVar
+	DFA         float64
+	// Sample and approximate entropy of the window's most recent returns
+	SampleEntropy float64
+	ApproxEntropy float64
+	PermEntropy   float64           // normalised permutation entropy of returns
+	LempelZiv     float64           // normalised LZ76 complexity of return signs
+	Lacunarity    []LacunarityPoint // of the dimension's series, in box-size order
+	// Mean and 5th/95th percentiles of the dimension over block-bootstrap
+	// replicates; NaN when the run did not bootstrap
+	BootstrapMean float64
+	BootstrapP05  float64
+	BootstrapP95  float64
+	// Mean surrogate dimension and the p-value of the window's dimension
+	// against phase-randomized surrogates; NaN when the run did not test
+	SurrogateMean float64
+	SurrogateP    float64
+	// Dimensions of the series by each Analyzer.Estimators method, NaN
+	// where a method could not estimate one
+	Methods []MethodDimension
+	// Time the worker spent on the window, excluding bootstrap and
+	// surrogate replicates, and whether its box count was degenerate and
+	// Dimension is the 1.0 fallback
+	ComputeMicros int64
+	Degenerate    bool
+}
+
+// MethodDimension is the dimension a named Estimator gave a window.
+type MethodDimension struct {
+	Method    string
+	Dimension float64
+}
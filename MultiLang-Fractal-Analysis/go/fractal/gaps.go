@@ -0,0 +1,120 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Gap is a stretch of missing candles in a series, such as a weekend or a
+// trading halt.
+type Gap struct {
+	Index   int       // candle after the gap
+	Before  time.Time // timestamp of the candle before it
+	After   time.Time // and of the candle after it
+	Missing int       // candles the regular interval puts in between
+}
+
+// GapMode selects what is done with timestamp gaps.
+type GapMode string
+
+const (
+	GapsIgnore GapMode = "ignore" // treat candles as evenly spaced
+	GapsFlag   GapMode = "flag"   // report them, leaving the series as is
+	GapsFill   GapMode = "fill"   // insert placeholders on a uniform grid
+)
+
+// ParseGapMode validates a gap mode name.
+func ParseGapMode(name string) (GapMode, error) {
+	switch m := GapMode(name); m {
+	case GapsIgnore, GapsFlag, GapsFill:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown gap mode %q (want ignore, flag or fill)", name)
+}
+
+// MedianInterval returns the median spacing of consecutive timestamps, the
+// regular interval of a series with occasional gaps, or 0 with fewer than
+// two candles or no positive spacing.
+func MedianInterval(data []MarketCandle) time.Duration {
+	var steps []float64
+	for i := 1; i < len(data); i++ {
+		if d := data[i].Timestamp.Sub(data[i-1].Timestamp); d > 0 {
+			steps = append(steps, float64(d))
+		}
+	}
+	if len(steps) == 0 {
+		return 0
+	}
+	sort.Float64s(steps)
+	return time.Duration(Percentile(steps, 50))
+}
+
+// DetectGaps finds, in index order, the places where consecutive
+// timestamps are two or more intervals apart, rounding to whole
+// intervals. An interval of 0 uses MedianInterval.
+func DetectGaps(data []MarketCandle, interval time.Duration) []Gap {
+	if interval <= 0 {
+		interval = MedianInterval(data)
+	}
+	if interval <= 0 {
+		return nil
+	}
+
+	var gaps []Gap
+	for i := 1; i < len(data); i++ {
+		step := data[i].Timestamp.Sub(data[i-1].Timestamp)
+		missing := int(math.Round(float64(step)/float64(interval))) - 1
+		if missing >= 1 {
+			gaps = append(gaps, Gap{Index: i, Before: data[i-1].Timestamp, After: data[i].Timestamp, Missing: missing})
+		}
+	}
+	return gaps
+}
+
+// FillGaps returns data with each gap DetectGaps finds padded with
+// placeholder candles one interval apart, so indices are uniform in time.
+// Placeholders have NaN prices, no volume, a zero return and the
+// volatility of the candle before; the candle after a gap keeps the return
+// across it. Box counting skips NaN prices, the power spectrum
+// interpolates across them and return-based estimators see the gap as
+// flat; other price measures, such as lacunarity, are NaN over windows
+// holding a placeholder, and indicators built by recursion (EMA, RSI, ATR)
+// from the first placeholder on. Without gaps data itself is returned.
+func FillGaps(data []MarketCandle, interval time.Duration) []MarketCandle {
+	if interval <= 0 {
+		interval = MedianInterval(data)
+	}
+	gaps := DetectGaps(data, interval)
+	if len(gaps) == 0 {
+		return data
+	}
+
+	missing := 0
+	for _, g := range gaps {
+		missing += g.Missing
+	}
+	filled := make([]MarketCandle, 0, len(data)+missing)
+	next := 0
+	nan := math.NaN()
+	for i, c := range data {
+		if next < len(gaps) && gaps[next].Index == i {
+			prev := data[i-1]
+			for k := 1; k <= gaps[next].Missing; k++ {
+				filled = append(filled, MarketCandle{
+					Timestamp:  prev.Timestamp.Add(time.Duration(k) * interval),
+					Price:      nan,
+					Open:       nan,
+					High:       nan,
+					Low:        nan,
+					Close:      nan,
+					Volatility: prev.Volatility,
+				})
+			}
+			next++
+		}
+		filled = append(filled, c)
+	}
+	return filled
+}
@@ -0,0 +1,64 @@
+package fractal
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Two weeks of weekday candles, Monday 1 January 2024 first.
+func weekdays() []MarketCandle {
+	var data []MarketCandle
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for len(data) < 10 {
+		if wd := day.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			data = append(data, MarketCandle{Timestamp: day, Price: 100 + float64(len(data)), Volatility: 0.01})
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return data
+}
+
+// The weekend is found at the Monday after it, two days missing.
+func TestDetectGapsWeekend(t *testing.T) {
+	data := weekdays()
+	if got := MedianInterval(data); got != 24*time.Hour {
+		t.Fatalf("median interval %v, want a day", got)
+	}
+	fri, mon := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	want := []Gap{{Index: 5, Before: fri, After: mon, Missing: 2}}
+	if got := DetectGaps(data, 0); !slices.Equal(got, want) {
+		t.Errorf("gaps %v, want %v", got, want)
+	}
+	if got := DetectGaps(data, 72*time.Hour); got != nil {
+		t.Errorf("gaps at a three-day interval %v, want none", got)
+	}
+	if got := DetectGaps(data[:1], 0); got != nil {
+		t.Errorf("gaps in one candle %v, want none", got)
+	}
+}
+
+// Filling puts Saturday and Sunday placeholders before Monday.
+func TestFillGapsWeekend(t *testing.T) {
+	data := weekdays()
+	filled := FillGaps(data, 0)
+	if len(filled) != 12 {
+		t.Fatalf("%d candles, want 12", len(filled))
+	}
+	for i, c := range filled {
+		if want := data[0].Timestamp.AddDate(0, 0, i); !c.Timestamp.Equal(want) {
+			t.Errorf("candle %d at %v, want %v", i, c.Timestamp, want)
+		}
+		placeholder := i == 5 || i == 6
+		if math.IsNaN(c.Price) != placeholder || placeholder && (c.Volume != 0 || c.Volatility != 0.01) {
+			t.Errorf("candle %d %+v, want placeholder %v", i, c, placeholder)
+		}
+	}
+	if filled[7].Price != data[5].Price {
+		t.Errorf("Monday price %v, want %v", filled[7].Price, data[5].Price)
+	}
+	if got := FillGaps(data[:5], 0); len(got) != 5 || &got[0] != &data[0] {
+		t.Error("a series without gaps was copied")
+	}
+}
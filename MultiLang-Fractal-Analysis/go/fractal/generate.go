@@ -0,0 +1,187 @@
+package fractal
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// GenerateSeries produces n hourly candles driven by multi-octave fractal
+// noise, drawing from rng.
+func GenerateSeries(rng *rand.Rand, n int, initial float64) []MarketCandle {
+	data := make([]MarketCandle, n)
+	price := initial
+	start := time.Now().Add(-time.Duration(n) * time.Hour)
+
+	for i := 0; i < n; i++ {
+		// Multi-octave fractal noise
+		noise := 0.0
+		amp, freq := 1.0, 1.0
+		for o := 0; o < 5; o++ {
+			phase := math.Mod(float64(i)*freq*0.07, 2*math.Pi)
+			sine := math.Sin(phase) + 0.5*math.Sin(phase*1.618)
+			noise += amp * sine * gaussianFrom(rng) * 0.08
+			amp *= 0.55
+			freq *= 2
+		}
+
+		drift := 0.00005
+		vol := 0.015
+		rnd := gaussianFrom(rng)
+		dP := drift + vol*(rnd+0.3*noise)
+		price *= (1 + dP)
+
+		volume := 1000 + math.Abs(rnd)*400
+
+		data[i] = MarketCandle{
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Price:     price,
+			Volume:    volume,
+		}
+	}
+	fillOHLC(rng, data)
+	return data
+}
+
+// NewRand returns a source for stream of a run seeded with master, so
+// goroutines each own a generator yet the run stays reproducible. Stream 0
+// is master itself; other streams are decorrelated with SplitMix64.
+func NewRand(master int64, stream int) *rand.Rand {
+	if stream == 0 {
+		return rand.New(rand.NewSource(master))
+	}
+	z := uint64(master) + uint64(stream)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return rand.New(rand.NewSource(int64(z ^ z>>31)))
+}
+
+// Box-Muller standard normal draw from r.
+func gaussianFrom(r *rand.Rand) float64 {
+	u1 := 1.0 - r.Float64()
+	u2 := 1.0 - r.Float64()
+	return math.Sqrt(-2.0*math.Log(u1)) * math.Sin(2.0*math.Pi*u2)
+}
+
+// GenerateGBM produces n hourly candles following geometric Brownian motion
+// with per-candle drift and volatility, so log-returns are normal with mean
+// drift - vol²/2 and variance vol².
+func GenerateGBM(rng *rand.Rand, n int, initial, drift, vol float64) []MarketCandle {
+	data := make([]MarketCandle, n)
+	price := initial
+	start := time.Now().Add(-time.Duration(n) * time.Hour)
+
+	for i := 0; i < n; i++ {
+		z := gaussianFrom(rng)
+		price *= math.Exp(drift - 0.5*vol*vol + vol*z)
+
+		data[i] = MarketCandle{
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Price:     price,
+			Volume:    1000 + math.Abs(z)*400,
+		}
+	}
+	fillOHLC(rng, data)
+	return data
+}
+
+// Derives plausible OHLC bars from generated closing prices: each bar opens
+// at the previous close and its wicks extend past the body by a random
+// fraction of the bar's move plus a small spread. Runs after the price path
+// is built so the random draws don't perturb it.
+func fillOHLC(rng *rand.Rand, data []MarketCandle) {
+	for i := range data {
+		c := data[i].Price
+		o := c
+		if i > 0 {
+			o = data[i-1].Price
+		}
+		body := math.Abs(c - o)
+		spread := 0.5 * (body + 0.002*c)
+
+		data[i].Open = o
+		data[i].Close = c
+		data[i].High = math.Max(o, c) + math.Abs(gaussianFrom(rng))*spread
+		data[i].Low = math.Min(o, c) - math.Abs(gaussianFrom(rng))*spread
+	}
+}
+
+// GenerateOU produces n hourly candles whose log-price follows an
+// Ornstein-Uhlenbeck process pulled towards ln(mu) at rate theta per
+// candle with per-candle diffusion sigma. It uses the exact transition
+// x' = m + (x-m)e^(-θ) + σ√((1-e^(-2θ))/2θ)·z, so any theta > 0 is stable;
+// returns are mean-reverting and their lag-1 autocorrelation negative.
+// mu must be positive; a non-positive theta degenerates to a driftless
+// random walk.
+func GenerateOU(rng *rand.Rand, n int, initial, theta, mu, sigma float64) []MarketCandle {
+	data := make([]MarketCandle, n)
+	start := time.Now().Add(-time.Duration(n) * time.Hour)
+
+	m := math.Log(mu)
+	decay, scale := 1.0, sigma
+	if theta > 0 {
+		decay = math.Exp(-theta)
+		scale = sigma * math.Sqrt((1-decay*decay)/(2*theta))
+	}
+
+	x := math.Log(initial)
+	for i := 0; i < n; i++ {
+		z := gaussianFrom(rng)
+		x = m + (x-m)*decay + scale*z
+
+		data[i] = MarketCandle{
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Price:     math.Exp(x),
+			Volume:    1000 + math.Abs(z)*400,
+		}
+	}
+	fillOHLC(rng, data)
+	return data
+}
+
+// GenerateJumpDiffusion produces n hourly candles from Merton's
+// jump-diffusion: GBM increments as in GenerateGBM plus a compound Poisson
+// number of jumps per candle, jumpIntensity on average, each multiplying
+// the price by exp(N(jumpMean, jumpStd²)). The drift is compensated for
+// the mean jump so drift stays the expected per-candle return, as in GBM.
+func GenerateJumpDiffusion(rng *rand.Rand, n int, initial, drift, vol, jumpIntensity, jumpMean, jumpStd float64) []MarketCandle {
+	data := make([]MarketCandle, n)
+	price := initial
+	start := time.Now().Add(-time.Duration(n) * time.Hour)
+
+	kappa := math.Exp(jumpMean+0.5*jumpStd*jumpStd) - 1
+	mu := drift - 0.5*vol*vol - jumpIntensity*kappa
+
+	for i := 0; i < n; i++ {
+		z := gaussianFrom(rng)
+		step := mu + vol*z
+		jumps := poissonFrom(rng, jumpIntensity)
+		for k := 0; k < jumps; k++ {
+			step += jumpMean + jumpStd*gaussianFrom(rng)
+		}
+		price *= math.Exp(step)
+
+		data[i] = MarketCandle{
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Price:     price,
+			Volume:    1000*float64(1+jumps) + math.Abs(z)*400, // jumps trade heavily
+		}
+	}
+	fillOHLC(rng, data)
+	return data
+}
+
+// Poisson draw by Knuth's multiplication method, fine for the small
+// per-candle intensities used here.
+func poissonFrom(r *rand.Rand, lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	limit := math.Exp(-lambda)
+	k, p := 0, r.Float64()
+	for p > limit {
+		k++
+		p *= r.Float64()
+	}
+	return k
+}
@@ -0,0 +1,138 @@
+package fractal
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Log-returns of data's closes, the first taken from initial.
+func logReturns(data []MarketCandle, initial float64) []float64 {
+	returns := make([]float64, len(data))
+	prev := initial
+	for i, c := range data {
+		returns[i] = math.Log(c.Price / prev)
+		prev = c.Price
+	}
+	return returns
+}
+
+// GBM log-returns are normal with mean drift - vol²/2 and variance vol².
+func TestGenerateGBMLogReturns(t *testing.T) {
+	tests := []struct{ drift, vol float64 }{
+		{0, 0.01},
+		{0.001, 0.02},
+		{-0.0005, 0.005},
+	}
+	const n = 20000
+	for _, tt := range tests {
+		returns := logReturns(GenerateGBM(NewRand(11, 0), n, 100, tt.drift, tt.vol), 100)
+		wantMean := tt.drift - tt.vol*tt.vol/2
+		if got := mean(returns); math.Abs(got-wantMean) > 4*tt.vol/math.Sqrt(n) {
+			t.Errorf("drift %v, vol %v: mean %v, want %v", tt.drift, tt.vol, got, wantMean)
+		}
+		if got := variance(returns); math.Abs(got/(tt.vol*tt.vol)-1) > 0.05 {
+			t.Errorf("drift %v, vol %v: variance %v, want %v", tt.drift, tt.vol, got, tt.vol*tt.vol)
+		}
+		if _, p := NormalKS(returns); p < 0.01 {
+			t.Errorf("drift %v, vol %v: KS p-value %v rejects normality", tt.drift, tt.vol, p)
+		}
+	}
+}
+
+func TestNewRandStreams(t *testing.T) {
+	draws := func(master int64, stream int) [4]float64 {
+		rng := NewRand(master, stream)
+		return [4]float64{rng.Float64(), rng.Float64(), rng.Float64(), rng.Float64()}
+	}
+	tests := []struct {
+		master   int64
+		a, b     int
+		wantSame bool
+		name     string
+	}{
+		{42, 0, 0, true, "same stream"},
+		{42, 7, 7, true, "same derived stream"},
+		{42, 0, 1, false, "master and first stream"},
+		{42, 1, 2, false, "neighbouring streams"},
+	}
+	for _, tt := range tests {
+		if same := draws(tt.master, tt.a) == draws(tt.master, tt.b); same != tt.wantSame {
+			t.Errorf("%s: streams %d and %d draw alike %v, want %v", tt.name, tt.a, tt.b, same, tt.wantSame)
+		}
+	}
+	if draws(1, 3) == draws(2, 3) {
+		t.Error("stream 3 of masters 1 and 2 draw alike")
+	}
+}
+
+// A fixed master seed reproduces the generated series and every result,
+// replicate-driven statistics included, however many workers share the run.
+func TestFixedSeedReproducible(t *testing.T) {
+	generate := func() []MarketCandle {
+		data := GenerateSeries(NewRand(32, 0), 1500, 100)
+		ComputeReturnsAndVol(data, DefaultVolWindow)
+		return data
+	}
+	data := generate()
+	for i, c := range generate() {
+		if c.Price != data[i].Price || c.High != data[i].High || c.Low != data[i].Low {
+			t.Fatalf("candle %d differs between runs with the same seed", i)
+		}
+	}
+
+	windows := []Window{{0, 1500}, {0, 500}, {500, 500}, {1000, 500}}
+	var runs [][]FractalResult
+	for _, workers := range []int{1, 8, 1} {
+		a := Analyzer{Workers: workers, Bootstrap: 20, Surrogates: 19, Seed: 32}
+		results, err := a.Run(context.Background(), data, windows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, results)
+	}
+	for _, results := range runs[1:] {
+		for i, r := range results {
+			w := runs[0][i]
+			r.ComputeMicros, w.ComputeMicros = 0, 0 // timing is all that may differ
+			if !reflect.DeepEqual(r, w) {
+				t.Errorf("window %d: %+v differs from %+v", i, r, w)
+			}
+		}
+	}
+}
+
+// OU returns are anti-persistent: lag-1 autocorrelation -(1-e^(-θ))/2 and
+// a Hurst exponent below 0.5.
+func TestGenerateOUMeanReverts(t *testing.T) {
+	for _, theta := range []float64{0.05, 0.2, 0.5} {
+		returns := logReturns(GenerateOU(NewRand(44, 0), 8192, 100, theta, 100, 0.01), 100)
+		want := -(1 - math.Exp(-theta)) / 2
+		if got := Autocorrelation(returns, 1)[1]; math.Abs(got-want) > 0.03 || got >= 0 {
+			t.Errorf("theta %v: lag-1 autocorrelation %v, want %v", theta, got, want)
+		}
+		if h := HurstRS(returns); h >= 0.45 {
+			t.Errorf("theta %v: Hurst %v, want below 0.5", theta, h)
+		}
+	}
+}
+
+// Jumps fatten the tails: Merton returns have far more excess kurtosis
+// than GBM returns with the same diffusion.
+func TestGenerateJumpDiffusionKurtosis(t *testing.T) {
+	const n = 20000
+	gbm := kurtosis(logReturns(GenerateGBM(NewRand(46, 0), n, 100, 0, 0.01), 100))
+	merton := kurtosis(logReturns(GenerateJumpDiffusion(NewRand(46, 0), n, 100, 0, 0.01, 0.05, 0, 0.04), 100))
+	if math.Abs(gbm) > 0.2 {
+		t.Errorf("GBM excess kurtosis %v, want near 0", gbm)
+	}
+	if merton < gbm+3 {
+		t.Errorf("Merton excess kurtosis %v, want well above GBM's %v", merton, gbm)
+	}
+
+	noJumps := logReturns(GenerateJumpDiffusion(NewRand(46, 0), n, 100, 0, 0.01, 0, 0, 0.04), 100)
+	if k := kurtosis(noJumps); math.Abs(k) > 0.2 {
+		t.Errorf("no jumps: excess kurtosis %v, want GBM's near 0", k)
+	}
+}
@@ -0,0 +1,57 @@
+package fractal
+
+import "math"
+
+// DefaultHiguchiKMax is the largest interval used by HiguchiFractalDimension.
+const DefaultHiguchiKMax = 10
+
+// HiguchiFractalDimension estimates the dimension of a series from how its
+// curve length scales with the sampling interval (Higuchi 1988).
+func HiguchiFractalDimension(series []float64) float64 {
+	d, _ := HiguchiFit(series, DefaultHiguchiKMax)
+	return d
+}
+
+// HiguchiFit returns the Higuchi dimension for intervals 1..kmax together
+// with the R² of the log-log fit. Degenerate inputs report 1.0 with R² 0.
+func HiguchiFit(series []float64, kmax int) (dimension, r2 float64) {
+	n := len(series)
+	if kmax > n/2 {
+		kmax = n / 2
+	}
+	if kmax < 2 {
+		return 1.0, 0
+	}
+
+	var logInvK, logL []float64
+	for k := 1; k <= kmax; k++ {
+		total, curves := 0.0, 0
+		for m := 0; m < k; m++ {
+			steps := (n - 1 - m) / k
+			if steps < 1 {
+				continue
+			}
+			length := 0.0
+			for i := 1; i <= steps; i++ {
+				length += math.Abs(series[m+i*k] - series[m+(i-1)*k])
+			}
+			// Normalise for the number of points the sub-curve skips
+			total += length * float64(n-1) / float64(steps*k) / float64(k)
+			curves++
+		}
+		if curves == 0 || total <= 0 {
+			continue
+		}
+		logInvK = append(logInvK, math.Log(1/float64(k)))
+		logL = append(logL, math.Log(total/float64(curves)))
+	}
+
+	if len(logInvK) < 2 {
+		return 1.0, 0
+	}
+	dimension, r2, ok := LinearFit(logInvK, logL)
+	if !ok {
+		return 1.0, 0
+	}
+	return dimension, r2
+}
@@ -0,0 +1,99 @@
+package fractal
+
+import "math"
+
+// HurstRS estimates the Hurst exponent of returns via rescaled-range analysis.
+// H > 0.5 means persistent (trending), H < 0.5 anti-persistent (mean-reverting).
+func HurstRS(returns []float64) float64 {
+	if len(returns) < 32 {
+		return 0.5 // too short to estimate, assume random walk
+	}
+
+	// Logarithmically spaced sub-period lengths
+	var logN, logRS []float64
+	for size := 8.0; int(size) <= len(returns)/2; size *= 1.5 {
+		n := int(size)
+		chunks := len(returns) / n
+		rsSum := 0.0
+		rsCount := 0
+
+		for c := 0; c < chunks; c++ {
+			chunk := returns[c*n : (c+1)*n]
+
+			mean := 0.0
+			for _, r := range chunk {
+				mean += r
+			}
+			mean /= float64(n)
+
+			// Range of cumulative deviations and standard deviation
+			cum, minCum, maxCum, ss := 0.0, 0.0, 0.0, 0.0
+			for _, r := range chunk {
+				dev := r - mean
+				cum += dev
+				ss += dev * dev
+				if cum < minCum {
+					minCum = cum
+				}
+				if cum > maxCum {
+					maxCum = cum
+				}
+			}
+			std := math.Sqrt(ss / float64(n))
+			if std > 0 {
+				rsSum += (maxCum - minCum) / std
+				rsCount++
+			}
+		}
+
+		if rsCount > 0 {
+			logN = append(logN, math.Log(float64(n)))
+			logRS = append(logRS, math.Log(rsSum/float64(rsCount)))
+		}
+	}
+
+	if len(logN) < 2 {
+		return 0.5
+	}
+
+	h, ok := LinearSlope(logN, logRS)
+	if !ok {
+		return 0.5
+	}
+	return h
+}
+
+// HurstAggVar estimates the Hurst exponent of returns with the
+// aggregated-variance method, a cross-check on HurstRS without its
+// small-sample bias: the variance of the means of blocks of m returns
+// scales as m^(2H-2), so H is one plus half the slope of log variance
+// against log m. Block sizes grow by half from 4 while at least 8 blocks
+// remain. Series too short to fit fall back to 0.5 as HurstRS does.
+func HurstAggVar(returns []float64) float64 {
+	if len(returns) < 32 {
+		return 0.5
+	}
+
+	var logM, logVar []float64
+	for size := 4.0; len(returns)/int(size) >= 8; size *= 1.5 {
+		m := int(size)
+		means := make([]float64, len(returns)/m)
+		for b := range means {
+			means[b] = mean(returns[b*m : (b+1)*m])
+		}
+		if v := variance(means); v > 0 {
+			logM = append(logM, math.Log(float64(m)))
+			logVar = append(logVar, math.Log(v))
+		}
+	}
+
+	if len(logM) < 2 {
+		return 0.5
+	}
+
+	slope, ok := LinearSlope(logM, logVar)
+	if !ok {
+		return 0.5
+	}
+	return 1 + slope/2
+}
@@ -0,0 +1,51 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// Fractional Gaussian noise, the increments of fBm, with the given Hurst
+// exponent: persistent above 0.5 and anti-persistent below.
+func fgn(seed int64, n int, hurst float64) []float64 {
+	return fractionalGaussianNoise(NewRand(seed, 0), n, hurst)
+}
+
+func TestHurstRS(t *testing.T) {
+	tests := []struct {
+		name     string
+		returns  []float64
+		min, max float64
+	}{
+		{"persistent", fgn(1, 4096, 0.8), 0.65, 0.95},
+		{"anti-persistent", fgn(2, 4096, 0.2), 0.05, 0.4},
+		{"too short", fgn(3, 31, 0.8), 0.5, 0.5},
+	}
+	for _, tt := range tests {
+		h := HurstRS(tt.returns)
+		if h < tt.min || h > tt.max {
+			t.Errorf("%s: H %v, want in [%v, %v]", tt.name, h, tt.min, tt.max)
+		}
+	}
+}
+
+// On fBm increments of known H both estimators land near it, and near
+// each other.
+func TestHurstAggVar(t *testing.T) {
+	for i, h := range []float64{0.3, 0.5, 0.7, 0.85} {
+		returns := fgn(int64(94+i), 8192, h)
+		agg, rs := HurstAggVar(returns), HurstRS(returns)
+		if math.Abs(agg-h) > 0.08 {
+			t.Errorf("H %v: aggregated variance %v", h, agg)
+		}
+		if math.Abs(rs-h) > 0.1 {
+			t.Errorf("H %v: R/S %v", h, rs)
+		}
+		if math.Abs(agg-rs) > 0.15 {
+			t.Errorf("H %v: estimators disagree, aggregated variance %v and R/S %v", h, agg, rs)
+		}
+	}
+	if h := HurstAggVar(fgn(99, 31, 0.8)); h != 0.5 {
+		t.Errorf("too short: H %v, want 0.5", h)
+	}
+}
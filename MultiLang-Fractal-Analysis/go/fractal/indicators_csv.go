@@ -0,0 +1,277 @@
+package fractal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// WritePivotsCSV writes bullish and bearish fractal pivots in index order,
+// with the pivot low or high as the price.
+func WritePivotsCSV(data []MarketCandle, bullish, bearish []int, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Index", "Timestamp", "Price", "Type"})
+
+	write := func(i int, kind string, price float64) {
+		writer.Write([]string{
+			strconv.Itoa(i),
+			data[i].Timestamp.Format(timestampLayout),
+			fmt.Sprintf("%.6f", price),
+			kind,
+		})
+	}
+
+	// Merge the two sorted index lists
+	b, s := 0, 0
+	for b < len(bullish) || s < len(bearish) {
+		if s == len(bearish) || (b < len(bullish) && bullish[b] <= bearish[s]) {
+			write(bullish[b], "bullish", data[bullish[b]].Low)
+			b++
+		} else {
+			write(bearish[s], "bearish", data[bearish[s]].High)
+			s++
+		}
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteFibonacciCSV writes the retracement levels of the last swing in
+// points, from SwingPoints over data: the swing's end and start as levels
+// 0.000 and 1.000 with their candles, then each of FibonacciRatios in
+// order. Only the header is written when there is no swing or it has no
+// range.
+func WriteFibonacciCSV(data []MarketCandle, points []SwingPoint, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Level", "Price", "Index", "Timestamp", "Direction"})
+
+	start, end, ok := LastSwing(points)
+	levels := SwingLevels(start, end)
+	if ok && levels != nil {
+		direction := "down"
+		if end.High {
+			direction = "up"
+		}
+		pivot := func(level string, p SwingPoint) {
+			writer.Write([]string{
+				level,
+				fmt.Sprintf("%.6f", p.Price),
+				strconv.Itoa(p.Index),
+				data[p.Index].Timestamp.Format(timestampLayout),
+				direction,
+			})
+		}
+		pivot(FibonacciKey(0), end)
+		pivot(FibonacciKey(1), start)
+		for _, r := range FibonacciRatios {
+			writer.Write([]string{FibonacciKey(r), fmt.Sprintf("%.6f", levels[FibonacciKey(r)]), "", "", direction})
+		}
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteGapsCSV writes each timestamp gap with the candles either side of
+// it and how many candles are missing.
+func WriteGapsCSV(gaps []Gap, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Index", "Before", "After", "Duration", "Missing"})
+
+	for _, g := range gaps {
+		writer.Write([]string{
+			strconv.Itoa(g.Index),
+			g.Before.Format(timestampLayout),
+			g.After.Format(timestampLayout),
+			g.After.Sub(g.Before).String(),
+			strconv.Itoa(g.Missing),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteDivergencesCSV writes each RSI divergence with the pivot prices
+// and RSI values it compares.
+func WriteDivergencesCSV(data []MarketCandle, rsi []float64, divergences []Divergence, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Timestamp", "Index", "PreviousIndex", "Type", "Price", "PreviousPrice", "RSI", "PreviousRSI"})
+
+	for _, d := range divergences {
+		kind, price, prevPrice := "bearish", data[d.Index].High, data[d.Previous].High
+		if d.Bullish {
+			kind, price, prevPrice = "bullish", data[d.Index].Low, data[d.Previous].Low
+		}
+		writer.Write([]string{
+			data[d.Index].Timestamp.Format(timestampLayout),
+			strconv.Itoa(d.Index),
+			strconv.Itoa(d.Previous),
+			kind,
+			fmt.Sprintf("%.6f", price),
+			fmt.Sprintf("%.6f", prevPrice),
+			fmt.Sprintf("%.6f", rsi[d.Index]),
+			fmt.Sprintf("%.6f", rsi[d.Previous]),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteBandsCSV writes each candle's price with its Bollinger bands;
+// candles without a full window leave the band columns empty.
+func WriteBandsCSV(data []MarketCandle, mid, upper, lower []float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Timestamp", "Price", "Middle", "Upper", "Lower"})
+
+	band := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return fmt.Sprintf("%.6f", v)
+	}
+	for i, c := range data {
+		writer.Write([]string{
+			c.Timestamp.Format(timestampLayout),
+			fmt.Sprintf("%.6f", c.Price),
+			band(mid[i]),
+			band(upper[i]),
+			band(lower[i]),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteMACDCSV writes each candle's price with its MACD line, signal line
+// and histogram; values still warming up are left empty.
+func WriteMACDCSV(data []MarketCandle, macd, signal, histogram []float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Timestamp", "Price", "MACD", "Signal", "Histogram"})
+
+	value := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return fmt.Sprintf("%.6f", v)
+	}
+	for i, c := range data {
+		writer.Write([]string{
+			c.Timestamp.Format(timestampLayout),
+			fmt.Sprintf("%.6f", c.Price),
+			value(macd[i]),
+			value(signal[i]),
+			value(histogram[i]),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteVolatilityCSV writes each candle's per-period volatility next to
+// its annualized value, leaving both empty for the first warmUp candles.
+func WriteVolatilityCSV(data []MarketCandle, periodsPerYear float64, warmUp int, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Timestamp", "Volatility", "AnnualizedVolatility"})
+
+	for i, c := range data {
+		if i < warmUp {
+			writer.Write([]string{c.Timestamp.Format(timestampLayout), "", ""})
+			continue
+		}
+		writer.Write([]string{
+			c.Timestamp.Format(timestampLayout),
+			fmt.Sprintf("%.6f", c.Volatility),
+			fmt.Sprintf("%.6f", AnnualizeVol(c.Volatility, periodsPerYear)),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteATRCSV writes each candle's close with its true range and
+// average true range; candles before the first full window leave ATR
+// empty.
+func WriteATRCSV(data []MarketCandle, atr []float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Timestamp", "Close", "TrueRange", "ATR"})
+
+	tr := TrueRange(data)
+	for i, c := range data {
+		value := ""
+		if !math.IsNaN(atr[i]) {
+			value = fmt.Sprintf("%.6f", atr[i])
+		}
+		writer.Write([]string{
+			c.Timestamp.Format(timestampLayout),
+			fmt.Sprintf("%.6f", c.Close),
+			fmt.Sprintf("%.6f", tr[i]),
+			value,
+		})
+	}
+
+	return finishCSV(writer, file)
+}
@@ -0,0 +1,186 @@
+package fractal
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+)
+
+// jsonFloat encodes NaN and ±Inf as null, which encoding/json rejects.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON reads null back as NaN.
+func (f *jsonFloat) UnmarshalJSON(raw []byte) error {
+	if string(raw) == "null" {
+		*f = jsonFloat(math.NaN())
+		return nil
+	}
+	var v float64
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return err
+	}
+	*f = jsonFloat(v)
+	return nil
+}
+
+type resultJSON struct {
+	WindowStart   int              `json:"windowStart"`
+	WindowEnd     int              `json:"windowEnd"`
+	WindowSize    int              `json:"windowSize"`
+	Dimension     jsonFloat        `json:"dimension"`
+	R2            jsonFloat        `json:"r2"`
+	Hurst         jsonFloat        `json:"hurst"`
+	HurstAggVar   jsonFloat        `json:"hurstAggVar"`
+	DFA           jsonFloat        `json:"dfa"`
+	SampleEntropy jsonFloat        `json:"sampleEntropy"`
+	ApproxEntropy jsonFloat        `json:"approxEntropy"`
+	PermEntropy   jsonFloat        `json:"permutationEntropy"`
+	LempelZiv     jsonFloat        `json:"lempelZiv"`
+	BootstrapMean jsonFloat        `json:"bootstrapMean"`
+	BootstrapP05  jsonFloat        `json:"bootstrapP05"`
+	BootstrapP95  jsonFloat        `json:"bootstrapP95"`
+	SurrogateMean jsonFloat        `json:"surrogateMean"`
+	SurrogateP    jsonFloat        `json:"surrogateP"`
+	ComputeMicros int64            `json:"computeMicros"`
+	Degenerate    bool             `json:"degenerate"`
+	Lacunarity    []lacunarityJSON `json:"lacunarity"`
+	Methods       []methodJSON     `json:"methods,omitempty"`
+}
+
+func newResultJSON(r FractalResult) resultJSON {
+	doc := resultJSON{
+		WindowStart:   r.WindowStart,
+		WindowEnd:     r.WindowEnd,
+		WindowSize:    r.WindowEnd - r.WindowStart + 1,
+		Dimension:     jsonFloat(r.Dimension),
+		R2:            jsonFloat(r.R2),
+		Hurst:         jsonFloat(r.Hurst),
+		HurstAggVar:   jsonFloat(r.HurstAggVar),
+		DFA:           jsonFloat(r.DFA),
+		SampleEntropy: jsonFloat(r.SampleEntropy),
+		ApproxEntropy: jsonFloat(r.ApproxEntropy),
+		PermEntropy:   jsonFloat(r.PermEntropy),
+		LempelZiv:     jsonFloat(r.LempelZiv),
+		BootstrapMean: jsonFloat(r.BootstrapMean),
+		BootstrapP05:  jsonFloat(r.BootstrapP05),
+		BootstrapP95:  jsonFloat(r.BootstrapP95),
+		SurrogateMean: jsonFloat(r.SurrogateMean),
+		SurrogateP:    jsonFloat(r.SurrogateP),
+		ComputeMicros: r.ComputeMicros,
+		Degenerate:    r.Degenerate,
+		Lacunarity:    make([]lacunarityJSON, len(r.Lacunarity)),
+	}
+	for j, p := range r.Lacunarity {
+		doc.Lacunarity[j] = lacunarityJSON{p.BoxSize, jsonFloat(p.Value)}
+	}
+	for _, m := range r.Methods {
+		doc.Methods = append(doc.Methods, methodJSON{m.Method, jsonFloat(m.Dimension)})
+	}
+	return doc
+}
+
+// The FractalResult doc was made from.
+func (doc resultJSON) result() FractalResult {
+	r := FractalResult{
+		WindowStart:   doc.WindowStart,
+		WindowEnd:     doc.WindowEnd,
+		Dimension:     float64(doc.Dimension),
+		R2:            float64(doc.R2),
+		Hurst:         float64(doc.Hurst),
+		HurstAggVar:   float64(doc.HurstAggVar),
+		DFA:           float64(doc.DFA),
+		SampleEntropy: float64(doc.SampleEntropy),
+		ApproxEntropy: float64(doc.ApproxEntropy),
+		PermEntropy:   float64(doc.PermEntropy),
+		LempelZiv:     float64(doc.LempelZiv),
+		BootstrapMean: float64(doc.BootstrapMean),
+		BootstrapP05:  float64(doc.BootstrapP05),
+		BootstrapP95:  float64(doc.BootstrapP95),
+		SurrogateMean: float64(doc.SurrogateMean),
+		SurrogateP:    float64(doc.SurrogateP),
+		ComputeMicros: doc.ComputeMicros,
+		Degenerate:    doc.Degenerate,
+	}
+	for _, p := range doc.Lacunarity {
+		r.Lacunarity = append(r.Lacunarity, LacunarityPoint{BoxSize: p.BoxSize, Value: float64(p.Value)})
+	}
+	for _, m := range doc.Methods {
+		r.Methods = append(r.Methods, MethodDimension{m.Method, float64(m.Dimension)})
+	}
+	return r
+}
+
+type methodJSON struct {
+	Method    string    `json:"method"`
+	Dimension jsonFloat `json:"dimension"`
+}
+
+type lacunarityJSON struct {
+	BoxSize int       `json:"boxSize"`
+	Value   jsonFloat `json:"value"`
+}
+
+// summaryJSON keeps the metrics in reporting order as a JSON object.
+type summaryJSON []Metric
+
+func (s summaryJSON) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range s {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.Name)
+		if err != nil {
+			return nil, err
+		}
+		value := m.Value
+		if f, ok := value.(float64); ok {
+			value = jsonFloat(f)
+		}
+		val, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// WriteFractalJSON writes the window results and the session summary as a
+// single indented JSON document.
+func WriteFractalJSON(data []MarketCandle, results []FractalResult, filename string, extra ...Metric) error {
+	return WriteResultsJSON(results, Summarize(data, results, extra...), filename)
+}
+
+// WriteResultsJSON is WriteFractalJSON with an already computed summary.
+func WriteResultsJSON(results []FractalResult, summary []Metric, filename string) error {
+	doc := struct {
+		Results []resultJSON `json:"results"`
+		Summary summaryJSON  `json:"summary"`
+	}{
+		Results: make([]resultJSON, len(results)),
+		Summary: summary,
+	}
+	for i, r := range results {
+		doc.Results[i] = newResultJSON(r)
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(out, '\n'), 0644)
+}
@@ -0,0 +1,42 @@
+package fractal
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// NaN and infinite fields, which encoding/json refuses, are written as
+// null and the document still parses.
+func TestWriteResultsJSONNonFinite(t *testing.T) {
+	r := FractalResult{WindowStart: 0, WindowEnd: 99, Dimension: math.NaN(), R2: 0.9, Hurst: math.Inf(1), DFA: math.Inf(-1)}
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := WriteResultsJSON([]FractalResult{r}, nil, path); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Results []map[string]any `json:"results"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("output does not parse: %v", err)
+	}
+	if len(doc.Results) != 1 {
+		t.Fatalf("%d results, want 1", len(doc.Results))
+	}
+	got := doc.Results[0]
+	for _, field := range []string{"dimension", "hurst", "dfa"} {
+		if v, ok := got[field]; !ok || v != nil {
+			t.Errorf("%s: %v, want null", field, v)
+		}
+	}
+	if got["r2"] != 0.9 {
+		t.Errorf("r2: %v, want 0.9", got["r2"])
+	}
+}
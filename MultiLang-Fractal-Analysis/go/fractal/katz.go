@@ -0,0 +1,30 @@
+package fractal
+
+import "math"
+
+// KatzFractalDimension computes Katz's waveform dimension
+// log(L/a) / log(d/a), where L is the total path length, a the mean step
+// and d the largest excursion from the first point. It needs no regression,
+// so there is no goodness of fit. Flat or too-short series report 1.0.
+func KatzFractalDimension(series []float64) float64 {
+	if len(series) < 2 {
+		return 1.0
+	}
+
+	length, extent := 0.0, 0.0
+	for i := 1; i < len(series); i++ {
+		length += math.Abs(series[i] - series[i-1])
+		if d := math.Abs(series[i] - series[0]); d > extent {
+			extent = d
+		}
+	}
+	if length <= 0 || extent <= 0 {
+		return 1.0
+	}
+
+	a := length / float64(len(series)-1)
+	if extent <= a {
+		return 1.0 // log(d/a) would be zero or negative
+	}
+	return math.Log(length/a) / math.Log(extent/a)
+}
@@ -0,0 +1,71 @@
+package fractal
+
+import (
+	"math"
+	"sort"
+)
+
+// Kolmogorov-Smirnov distance between sample's empirical distribution and
+// cdf: the largest gap between the two, checked on both sides of each
+// step of the empirical CDF. NaN for an empty sample.
+func ksStatistic(sample []float64, cdf func(float64) float64) float64 {
+	if len(sample) == 0 {
+		return math.NaN()
+	}
+	sorted := append([]float64(nil), sample...)
+	sort.Float64s(sorted)
+
+	n := float64(len(sorted))
+	d := 0.0
+	for i, x := range sorted {
+		f := cdf(x)
+		d = math.Max(d, math.Max(float64(i+1)/n-f, f-float64(i)/n))
+	}
+	return d
+}
+
+// Asymptotic p-value of a KS statistic d over n points, using the
+// Kolmogorov distribution with Stephens' small-sample correction.
+func ksPValue(d float64, n int) float64 {
+	if math.IsNaN(d) || n == 0 {
+		return math.NaN()
+	}
+	sn := math.Sqrt(float64(n))
+	lambda := (sn + 0.12 + 0.11/sn) * d
+	if lambda < 0.2 {
+		return 1 // the series below converges slowly and sums to 1 here
+	}
+	p := 0.0
+	for k := 1; k <= 100; k++ {
+		term := 2 * math.Exp(-2*float64(k*k)*lambda*lambda)
+		if k%2 == 0 {
+			term = -term
+		}
+		p += term
+		if math.Abs(term) < 1e-12 {
+			break
+		}
+	}
+	return math.Max(0, math.Min(1, p))
+}
+
+// CDF of the normal distribution with mean mu and standard deviation sigma.
+func normalCDF(mu, sigma float64) func(float64) float64 {
+	return func(x float64) float64 {
+		return 0.5 * math.Erfc(-(x-mu)/(sigma*math.Sqrt2))
+	}
+}
+
+// NormalKS compares values with the normal distribution of their own sample
+// mean and variance, the GBM assumption for returns. It returns the KS
+// statistic and its p-value; as the normal is fitted to the same data the
+// p-value is conservative (Lilliefors), so a small one is strong evidence
+// against normality. Both are NaN for fewer than two values or no spread.
+func NormalKS(values []float64) (d, p float64) {
+	sigma := math.Sqrt(variance(values))
+	if !(sigma > 0) {
+		return math.NaN(), math.NaN()
+	}
+	d = ksStatistic(values, normalCDF(mean(values), sigma))
+	return d, ksPValue(d, len(values))
+}
@@ -0,0 +1,63 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKSStatistic(t *testing.T) {
+	uniform := func(x float64) float64 { return math.Max(0, math.Min(1, x)) }
+	tests := []struct {
+		name   string
+		sample []float64
+		want   float64
+	}{
+		{"one point at the median", []float64{0.5}, 0.5},
+		{"evenly spread", []float64{0.125, 0.375, 0.625, 0.875}, 0.125},
+		{"all low", []float64{0, 0, 0}, 1},
+		{"unsorted", []float64{0.875, 0.125, 0.625, 0.375}, 0.125},
+	}
+	for _, tt := range tests {
+		if got := ksStatistic(tt.sample, uniform); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: D %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if d := ksStatistic(nil, uniform); !math.IsNaN(d) {
+		t.Errorf("empty sample: D %v, want NaN", d)
+	}
+
+	// The Kolmogorov distribution's 5% point is λ = 1.358
+	n := 10000
+	sn := math.Sqrt(float64(n))
+	if p := ksPValue(1.358/(sn+0.12+0.11/sn), n); math.Abs(p-0.05) > 0.001 {
+		t.Errorf("p at the 5%% critical value %v, want 0.05", p)
+	}
+}
+
+// A normal sample sits close to its fitted normal; a Student t with two
+// degrees of freedom, whose tails are far heavier, does not.
+func TestNormalKS(t *testing.T) {
+	const n = 2000
+	rng := NewRand(78, 0)
+	normal, heavy := make([]float64, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		normal[i] = 0.001 + 0.02*rng.NormFloat64()
+		z1, z2 := rng.NormFloat64(), rng.NormFloat64()
+		heavy[i] = 0.02 * rng.NormFloat64() / math.Sqrt((z1*z1+z2*z2)/2)
+	}
+
+	d, p := NormalKS(normal)
+	if d > 0.03 || p < 0.1 {
+		t.Errorf("normal sample: D %v p %v, want a small D and no rejection", d, p)
+	}
+	heavyD, heavyP := NormalKS(heavy)
+	if heavyD < 0.1 || heavyP > 1e-6 {
+		t.Errorf("t(2) sample: D %v p %v, want a large D and rejection", heavyD, heavyP)
+	}
+
+	for _, values := range [][]float64{nil, {1}, {2, 2, 2}} {
+		if d, p := NormalKS(values); !math.IsNaN(d) || !math.IsNaN(p) {
+			t.Errorf("%v: D %v p %v, want NaN", values, d, p)
+		}
+	}
+}
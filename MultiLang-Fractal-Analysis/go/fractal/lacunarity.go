@@ -0,0 +1,56 @@
+package fractal
+
+import "math"
+
+// DefaultLacunaritySizes are the gliding-box sizes reported per window.
+var DefaultLacunaritySizes = []int{4, 16, 64}
+
+// LacunarityPoint is the lacunarity of a series at one box size.
+type LacunarityPoint struct {
+	BoxSize int
+	Value   float64
+}
+
+// Lacunarity measures how unevenly a series' movement is spread over time
+// with the gliding-box algorithm. The mass of each point is its absolute
+// change |x[i]-x[i-1]|; a box of boxSize changes slides one step at a time
+// and the result is E[M²]/E[M]² over the box masses M. Evenly spread moves
+// give values near 1 and clustered bursts give larger values. It returns
+// NaN when the box doesn't fit or the series never moves.
+func Lacunarity(prices []float64, boxSize int) float64 {
+	if boxSize < 1 || boxSize > len(prices)-1 {
+		return math.NaN()
+	}
+
+	mass := 0.0
+	for i := 1; i <= boxSize; i++ {
+		mass += math.Abs(prices[i] - prices[i-1])
+	}
+
+	sum, sumSq, boxes := 0.0, 0.0, 0
+	for start := 1; ; start++ {
+		sum += mass
+		sumSq += mass * mass
+		boxes++
+		end := start + boxSize
+		if end >= len(prices) {
+			break
+		}
+		mass += math.Abs(prices[end]-prices[end-1]) - math.Abs(prices[start]-prices[start-1])
+	}
+
+	mean := sum / float64(boxes)
+	if mean <= 0 {
+		return math.NaN()
+	}
+	return (sumSq / float64(boxes)) / (mean * mean)
+}
+
+// LacunarityProfile evaluates Lacunarity at each box size in order.
+func LacunarityProfile(prices []float64, sizes []int) []LacunarityPoint {
+	points := make([]LacunarityPoint, len(sizes))
+	for i, bs := range sizes {
+		points[i] = LacunarityPoint{BoxSize: bs, Value: Lacunarity(prices, bs)}
+	}
+	return points
+}
@@ -0,0 +1,49 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLacunarity(t *testing.T) {
+	n := 400
+	ramp := make([]float64, n)
+	zigzag := make([]float64, n)
+	clustered := make([]float64, n)
+	for i := range ramp {
+		ramp[i] = float64(i)
+		zigzag[i] = float64(i % 2)
+		if i > 0 {
+			clustered[i] = clustered[i-1]
+			if i%100 < 5 { // a burst of five moves every hundred candles
+				clustered[i] += 3
+			}
+		}
+	}
+	tests := []struct {
+		name     string
+		prices   []float64
+		boxSize  int
+		min, max float64
+	}{
+		{"ramp", ramp, 4, 1, 1 + 1e-12},
+		{"zig-zag", zigzag, 16, 1, 1 + 1e-12},
+		{"random walk", randomWalk(27, n), 4, 1, 1.5},
+		{"clustered", clustered, 4, 10, math.Inf(1)},
+		{"clustered, wide box", clustered, 64, 1.3, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		if got := Lacunarity(tt.prices, tt.boxSize); !(got >= tt.min && got <= tt.max) {
+			t.Errorf("%s, box %d: lacunarity %v, want in [%v, %v]", tt.name, tt.boxSize, got, tt.min, tt.max)
+		}
+	}
+
+	for _, bad := range []struct {
+		prices  []float64
+		boxSize int
+	}{{ramp, 0}, {ramp, n}, {make([]float64, 10), 4}} {
+		if got := Lacunarity(bad.prices, bad.boxSize); !math.IsNaN(got) {
+			t.Errorf("box %d over %d points: lacunarity %v, want NaN", bad.boxSize, len(bad.prices), got)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package fractal
+
+import "math"
+
+// LempelZivComplexity binarises series by the sign of successive
+// differences (1 for a rise, 0 otherwise), counts the phrases of its LZ76
+// parsing and normalises by n/log2(n), the count expected for a random
+// binary sequence of length n. Periodic patterns score near 0 and random
+// signs near 1. Fewer than three points, which leave under two symbols,
+// yield NaN.
+func LempelZivComplexity(series []float64) float64 {
+	if len(series) < 3 {
+		return math.NaN()
+	}
+	s := make([]bool, len(series)-1)
+	for i := range s {
+		s[i] = series[i+1] > series[i]
+	}
+	n := len(s)
+
+	// Kaspar & Schuster (1987) scan: l is where the current phrase starts,
+	// i the candidate earlier start and k the length matched so far
+	c, l, i, k, kmax := 1, 1, 0, 1, 1
+	for {
+		if s[i+k-1] == s[l+k-1] {
+			k++
+			if l+k > n {
+				c++
+				break
+			}
+			continue
+		}
+		if k > kmax {
+			kmax = k
+		}
+		i++
+		if i == l {
+			c++
+			l += kmax
+			if l+1 > n {
+				break
+			}
+			i, k, kmax = 0, 1, 1
+		} else {
+			k = 1
+		}
+	}
+
+	return float64(c) / (float64(n) / math.Log2(float64(n)))
+}
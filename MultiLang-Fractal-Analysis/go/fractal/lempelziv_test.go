@@ -0,0 +1,50 @@
+package fractal
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// A series whose successive moves spell bits: a rise for '1', a fall for
+// '0'.
+func seriesFromBits(bits string) []float64 {
+	series := make([]float64, len(bits)+1)
+	for i, b := range bits {
+		step := -1.0
+		if b == '1' {
+			step = 1
+		}
+		series[i+1] = series[i] + step
+	}
+	return series
+}
+
+func TestLempelZivComplexity(t *testing.T) {
+	random := whiteNoise(55, 4001)
+	periodic := make([]float64, 4001)
+	for i := range periodic {
+		periodic[i] = math.Sin(2 * math.Pi * float64(i) / 8)
+	}
+
+	tests := []struct {
+		name   string
+		series []float64
+		lo, hi float64
+	}{
+		// Kaspar & Schuster's example parses as 0·001·10·100·1000·101
+		{"six phrases", seriesFromBits("0001101001000101"), 6.0 / 4, 6.0 / 4},
+		{"constant", make([]float64, 1000), 0, 0.02},
+		{"alternating", seriesFromBits(strings.Repeat("10", 1000)), 0, 0.02},
+		{"period 8", periodic, 0, 0.05},
+		{"random signs", random, 0.9, 1.1},
+	}
+	for _, tt := range tests {
+		if got := LempelZivComplexity(tt.series); !(got >= tt.lo-1e-12 && got <= tt.hi+1e-12) {
+			t.Errorf("%s: %v, want in [%v, %v]", tt.name, got, tt.lo, tt.hi)
+		}
+	}
+	if got := LempelZivComplexity([]float64{1, 2}); !math.IsNaN(got) {
+		t.Errorf("two points: %v, want NaN", got)
+	}
+}
@@ -0,0 +1,62 @@
+package fractal
+
+import "math"
+
+// Appel's MACD periods.
+const (
+	DefaultMACDFast   = 12
+	DefaultMACDSlow   = 26
+	DefaultMACDSignal = 9
+)
+
+// EMA returns the exponential moving average of values with smoothing
+// 2/(period+1), seeded with the simple mean of the first period values.
+// Entries before the seed are NaN. Leading NaNs in values are skipped, so
+// an EMA of another indicator starts once that indicator does; a NaN after
+// the seed is not expected and propagates. A period below 1 yields all NaN.
+func EMA(values []float64, period int) []float64 {
+	out := make([]float64, len(values))
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	first := 0
+	for first < len(values) && math.IsNaN(values[first]) {
+		first++
+	}
+	if period < 1 || len(values)-first < period {
+		return out
+	}
+
+	seed := 0.0
+	for _, v := range values[first : first+period] {
+		seed += v
+	}
+	ema := seed / float64(period)
+	out[first+period-1] = ema
+
+	alpha := 2 / float64(period+1)
+	for i := first + period; i < len(values); i++ {
+		ema += alpha * (values[i] - ema)
+		out[i] = ema
+	}
+	return out
+}
+
+// MACD returns the MACD line, the fast minus the slow EMA of prices, its
+// signal-period EMA and the histogram between them. The line is NaN until
+// the slow EMA is seeded, and the signal and histogram for a further
+// signal-1 candles.
+func MACD(prices []float64, fast, slow, signal int) (macdLine, signalLine, histogram []float64) {
+	fastEMA, slowEMA := EMA(prices, fast), EMA(prices, slow)
+	macdLine = make([]float64, len(prices))
+	for i := range prices {
+		macdLine[i] = fastEMA[i] - slowEMA[i] // NaN while either is unseeded
+	}
+
+	signalLine = EMA(macdLine, signal)
+	histogram = make([]float64, len(prices))
+	for i := range prices {
+		histogram[i] = macdLine[i] - signalLine[i]
+	}
+	return macdLine, signalLine, histogram
+}
@@ -0,0 +1,78 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEMA(t *testing.T) {
+	constant := make([]float64, 40)
+	for i := range constant {
+		constant[i] = 7.5
+	}
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64 // NaN marks an unseeded entry
+	}{
+		{"constant", constant, 10, nil},
+		// seed mean(1, 2, 3) = 2, then alpha 1/2
+		{"by hand", []float64{1, 2, 3, 6, 2}, 3, []float64{nan, nan, 2, 4, 3}},
+		{"leading NaNs", []float64{nan, nan, 1, 3, 5}, 2, []float64{nan, nan, nan, 2, 4}},
+		{"period 1", []float64{3, 1, 4}, 1, []float64{3, 1, 4}},
+		{"too short", []float64{1, 2}, 3, []float64{nan, nan}},
+		{"period 0", []float64{1, 2}, 0, []float64{nan, nan}},
+	}
+	for _, tt := range tests {
+		got := EMA(tt.values, tt.period)
+		if tt.want == nil {
+			for i, v := range got[tt.period-1:] {
+				if math.Abs(v-7.5) > 1e-12 {
+					t.Errorf("%s: EMA %d %v, want the constant", tt.name, i+tt.period-1, v)
+				}
+			}
+			for i, v := range got[:tt.period-1] {
+				if !math.IsNaN(v) {
+					t.Errorf("%s: EMA %d %v before the seed, want NaN", tt.name, i, v)
+				}
+			}
+			continue
+		}
+		for i, want := range tt.want {
+			if math.IsNaN(want) != math.IsNaN(got[i]) || !math.IsNaN(want) && math.Abs(got[i]-want) > 1e-12 {
+				t.Errorf("%s: EMA %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// A constant series has a zero MACD once seeded; a steady trend's flattens
+// to a constant gap with a vanishing histogram.
+func TestMACD(t *testing.T) {
+	n := 200
+	flat, trend := make([]float64, n), make([]float64, n)
+	for i := range flat {
+		flat[i] = 100
+		trend[i] = 100 + float64(i)
+	}
+	line, signal, hist := MACD(flat, DefaultMACDFast, DefaultMACDSlow, DefaultMACDSignal)
+	seeded := DefaultMACDSlow - 1
+	signalSeeded := seeded + DefaultMACDSignal - 1
+	for i := range flat {
+		if unseeded := i < seeded; unseeded != math.IsNaN(line[i]) || !unseeded && line[i] != 0 {
+			t.Errorf("flat: MACD %d %v", i, line[i])
+		}
+		if unseeded := i < signalSeeded; unseeded != math.IsNaN(signal[i]) || unseeded != math.IsNaN(hist[i]) {
+			t.Errorf("flat: signal %d %v histogram %v", i, signal[i], hist[i])
+		}
+	}
+
+	// Each EMA lags a unit-slope line by (period-1)/2, so the gap is 7
+	line, _, hist = MACD(trend, DefaultMACDFast, DefaultMACDSlow, DefaultMACDSignal)
+	if math.Abs(line[n-1]-7) > 1e-6 || math.Abs(hist[n-1]) > 1e-6 {
+		t.Errorf("trend: MACD %v histogram %v, want 7 and 0", line[n-1], hist[n-1])
+	}
+}
@@ -0,0 +1,72 @@
+package fractal
+
+import "math"
+
+// Arithmetic mean; NaN for no values.
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// Sample variance with Bessel's n-1 correction; NaN for fewer than two
+// values.
+func variance(values []float64) float64 {
+	if len(values) < 2 {
+		return math.NaN()
+	}
+	m := mean(values)
+	ss := 0.0
+	for _, v := range values {
+		ss += (v - m) * (v - m)
+	}
+	return ss / float64(len(values)-1)
+}
+
+// Biased central moments m2, m3 and m4, each averaged over n.
+func centralMoments(values []float64) (m2, m3, m4 float64) {
+	m := mean(values)
+	for _, v := range values {
+		d := v - m
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	n := float64(len(values))
+	return m2 / n, m3 / n, m4 / n
+}
+
+// Adjusted Fisher-Pearson sample skewness G1, the estimator of Excel's
+// SKEW and pandas; NaN for fewer than three values or no spread.
+func skewness(values []float64) float64 {
+	if len(values) < 3 {
+		return math.NaN()
+	}
+	m2, m3, _ := centralMoments(values)
+	if m2 < 1e-300 {
+		return math.NaN()
+	}
+	n := float64(len(values))
+	return m3 / math.Pow(m2, 1.5) * math.Sqrt(n*(n-1)) / (n - 2)
+}
+
+// Bias-corrected sample excess kurtosis G2, the estimator of Excel's KURT
+// and pandas (0 for a normal population); NaN for fewer than four values
+// or no spread.
+func kurtosis(values []float64) float64 {
+	if len(values) < 4 {
+		return math.NaN()
+	}
+	m2, _, m4 := centralMoments(values)
+	if m2 < 1e-300 {
+		return math.NaN()
+	}
+	n := float64(len(values))
+	g2 := m4/(m2*m2) - 3
+	return ((n+1)*g2 + 6) * (n - 1) / ((n - 2) * (n - 3))
+}
@@ -0,0 +1,75 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMoments(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name               string
+		values             []float64
+		mean, variance     float64
+		skewness, kurtosis float64
+	}{
+		// Excel's SKEW and KURT give 1.697056 and 3.152
+		{"by hand", []float64{1, 2, 3, 4, 10}, 4, 12.5, 1.6970562748477143, 3.152},
+		{"symmetric", []float64{-2, -1, 0, 1, 2}, 0, 2.5, 0, -1.2},
+		{"constant", []float64{3, 3, 3, 3}, 3, 0, nan, nan},
+		{"three values", []float64{1, 2, 4}, 7.0 / 3, 7.0 / 3, 0.9352195295828246, nan},
+		{"one value", []float64{5}, 5, nan, nan, nan},
+		{"empty", nil, nan, nan, nan, nan},
+	}
+	for _, tt := range tests {
+		for _, m := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"mean", mean(tt.values), tt.mean},
+			{"variance", variance(tt.values), tt.variance},
+			{"skewness", skewness(tt.values), tt.skewness},
+			{"kurtosis", kurtosis(tt.values), tt.kurtosis},
+		} {
+			if math.IsNaN(m.want) != math.IsNaN(m.got) || math.Abs(m.got-m.want) > 1e-9 {
+				t.Errorf("%s: %s %v, want %v", tt.name, m.name, m.got, m.want)
+			}
+		}
+	}
+}
+
+// Large samples recover the population moments of known distributions.
+func TestMomentsOfDistributions(t *testing.T) {
+	const n = 200000
+	rng := NewRand(77, 0)
+	normal, uniform, exponential := make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		normal[i] = 2 + 3*rng.NormFloat64()
+		uniform[i] = rng.Float64()
+		exponential[i] = rng.ExpFloat64()
+	}
+	tests := []struct {
+		name                               string
+		values                             []float64
+		mean, variance, skewness, kurtosis float64
+		tol                                float64 // of skewness and kurtosis
+	}{
+		{"normal(2, 9)", normal, 2, 9, 0, 0, 0.05},
+		{"uniform", uniform, 0.5, 1.0 / 12, 0, -1.2, 0.02},
+		{"exponential", exponential, 1, 1, 2, 6, 0.3},
+	}
+	for _, tt := range tests {
+		if m := mean(tt.values); math.Abs(m-tt.mean) > 0.02*math.Max(1, tt.mean) {
+			t.Errorf("%s: mean %v, want %v", tt.name, m, tt.mean)
+		}
+		if v := variance(tt.values); math.Abs(v/tt.variance-1) > 0.02 {
+			t.Errorf("%s: variance %v, want %v", tt.name, v, tt.variance)
+		}
+		if s := skewness(tt.values); math.Abs(s-tt.skewness) > tt.tol {
+			t.Errorf("%s: skewness %v, want %v", tt.name, s, tt.skewness)
+		}
+		if k := kurtosis(tt.values); math.Abs(k-tt.kurtosis) > tt.tol {
+			t.Errorf("%s: kurtosis %v, want %v", tt.name, k, tt.kurtosis)
+		}
+	}
+}
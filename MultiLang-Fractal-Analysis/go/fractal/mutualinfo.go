@@ -0,0 +1,97 @@
+package fractal
+
+import "math"
+
+// Number of histogram bins per axis for MutualInformation.
+const mutualInfoBins = 8
+
+// MutualInformation returns the average mutual information, in nats,
+// between series[t] and series[t+lag] for lags 0..maxLag, estimated from a
+// joint histogram over the series' range. Each value is shared linearly
+// between its two nearest bin centres; hard binning makes the curve of a
+// smooth signal jitter enough to create spurious minima. Lags that leave
+// no pairs are NaN.
+func MutualInformation(series []float64, maxLag int) []float64 {
+	if maxLag < 0 {
+		return nil
+	}
+	mi := make([]float64, maxLag+1)
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range series {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	bins := make([]int, len(series))
+	weights := make([]float64, len(series)) // share of the upper bin
+	if hi > lo {
+		for i, v := range series {
+			u := (v - lo) / (hi - lo) * (mutualInfoBins - 1)
+			b := int(u)
+			if b >= mutualInfoBins-1 {
+				b = mutualInfoBins - 2 // the maximum sits on the last centre
+			}
+			bins[i], weights[i] = b, u-float64(b)
+		}
+	}
+
+	var joint [mutualInfoBins][mutualInfoBins]float64
+	for lag := 0; lag <= maxLag; lag++ {
+		pairs := len(series) - lag
+		if pairs <= 0 {
+			mi[lag] = math.NaN()
+			continue
+		}
+
+		joint = [mutualInfoBins][mutualInfoBins]float64{}
+		for t := 0; t < pairs; t++ {
+			a, b := bins[t], bins[t+lag]
+			wa, wb := weights[t], weights[t+lag]
+			joint[a][b] += (1 - wa) * (1 - wb)
+			joint[a][b+1] += (1 - wa) * wb
+			joint[a+1][b] += wa * (1 - wb)
+			joint[a+1][b+1] += wa * wb
+		}
+
+		var px, py [mutualInfoBins]float64
+		for a := range joint {
+			for b, p := range joint[a] {
+				px[a] += p
+				py[b] += p
+			}
+		}
+
+		total := float64(pairs)
+		sum := 0.0
+		for a := range joint {
+			for b, p := range joint[a] {
+				if p <= 0 {
+					continue
+				}
+				sum += p / total * math.Log(p*total/(px[a]*py[b]))
+			}
+		}
+		mi[lag] = sum
+	}
+	return mi
+}
+
+// FirstLocalMin returns the first index whose value is below its
+// predecessor and not above its successor, or -1 if there is none.
+func FirstLocalMin(values []float64) int {
+	for i := 1; i < len(values)-1; i++ {
+		if values[i] < values[i-1] && values[i] <= values[i+1] {
+			return i
+		}
+	}
+	return -1
+}
+
+// EmbeddingDelay picks a time-delay embedding lag as the first local
+// minimum of the mutual information up to maxLag, falling back to 1.
+func EmbeddingDelay(series []float64, maxLag int) int {
+	if lag := FirstLocalMin(MutualInformation(series, maxLag)); lag > 0 {
+		return lag
+	}
+	return 1
+}
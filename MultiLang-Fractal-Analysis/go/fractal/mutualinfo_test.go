@@ -0,0 +1,43 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// The first mutual-information minimum of a sinusoid lands near a quarter
+// period, where the delayed copy is furthest from redundant.
+func TestEmbeddingDelaySinusoid(t *testing.T) {
+	for _, period := range []float64{20, 40, 64} {
+		series := make([]float64, 2000)
+		for i := range series {
+			series[i] = math.Sin(2 * math.Pi * float64(i) / period)
+		}
+		quarter := period / 4
+		if lag := EmbeddingDelay(series, int(period)); math.Abs(float64(lag)-quarter) > 1 {
+			t.Errorf("period %v: delay %d, want near %v", period, lag, quarter)
+		}
+	}
+}
+
+func TestFirstLocalMin(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   int
+	}{
+		{[]float64{3, 2, 1, 2, 0}, 2},
+		{[]float64{3, 2, 2, 3}, 1}, // a plateau counts at its start
+		{[]float64{1, 2, 3}, -1},
+		{[]float64{3, 2, 1}, -1}, // still falling at the end
+		{nil, -1},
+	}
+	for _, tt := range tests {
+		if got := FirstLocalMin(tt.values); got != tt.want {
+			t.Errorf("FirstLocalMin(%v) = %d, want %d", tt.values, got, tt.want)
+		}
+	}
+
+	if lag := EmbeddingDelay(make([]float64, 100), 10); lag != 1 {
+		t.Errorf("constant series: delay %d, want the fallback 1", lag)
+	}
+}
@@ -0,0 +1,74 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Normalization selects how box counting maps prices onto the unit grid.
+type Normalization string
+
+const (
+	NormalizeMinMax Normalization = "minmax" // the finite range spans the grid
+	NormalizeZScore Normalization = "zscore" // mean ± ZScoreClip standard deviations
+	NormalizeRobust Normalization = "robust" // median/IQR, clipped to a quantile range
+)
+
+// ZScoreClip is how many standard deviations either side of the mean
+// NormalizeZScore keeps on the grid.
+const ZScoreClip = 3.0
+
+// DefaultClipQuantile is the tail quantile NormalizeRobust clips at when a
+// BoxCounter has none: values outside the 1st to 99th percentiles are
+// pulled in to them.
+const DefaultClipQuantile = 0.01
+
+// ParseNormalization validates a normalization name.
+func ParseNormalization(name string) (Normalization, error) {
+	switch n := Normalization(name); n {
+	case NormalizeMinMax, NormalizeZScore, NormalizeRobust:
+		return n, nil
+	}
+	return "", fmt.Errorf("unknown normalization %q (want minmax, zscore or robust)", name)
+}
+
+// Normalizes lows and highs onto [0,1] with b's method. Box counting is
+// blind to rescaling, so each method comes down to the price band the grid
+// spans, with values outside it clipped to its edges: the full range for
+// min-max, mean ± ZScoreClip sd for z-scores (never wider than the data),
+// and for robust scaling the band between the ClipQuantile and
+// 1-ClipQuantile quantiles, where median/IQR-standardized values would be
+// clipped.
+func (b BoxCounter) normalise(lows, highs []float64) (normLow, normHigh []float64, ok bool) {
+	if b.Normalize == "" || b.Normalize == NormalizeMinMax {
+		return normaliseRange(lows, highs)
+	}
+
+	var pooled []float64
+	for i := range lows {
+		if finitePoint(lows, highs, i) {
+			pooled = append(pooled, lows[i], highs[i])
+		}
+	}
+	if len(pooled) == 0 {
+		return nil, nil, false
+	}
+	sort.Float64s(pooled)
+	lo, hi := pooled[0], pooled[len(pooled)-1]
+
+	switch b.Normalize {
+	case NormalizeZScore:
+		mu, sd := mean(pooled), math.Sqrt(variance(pooled))
+		lo = math.Max(lo, mu-ZScoreClip*sd)
+		hi = math.Min(hi, mu+ZScoreClip*sd)
+	case NormalizeRobust:
+		q := b.ClipQuantile
+		if q == 0 {
+			q = DefaultClipQuantile
+		}
+		lo = Percentile(pooled, 100*q)
+		hi = Percentile(pooled, 100*(1-q))
+	}
+	return scaleRange(lows, highs, lo, hi)
+}
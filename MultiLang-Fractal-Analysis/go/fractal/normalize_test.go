@@ -0,0 +1,69 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// One spike stretches the min-max grid and moves the dimension; the
+// robust band clips it away and barely notices.
+func TestNormalizeSpike(t *testing.T) {
+	walk := randomWalk(88, 2000)
+	spiked := append([]float64(nil), walk...)
+	spiked[1000] += 200
+
+	shift := map[Normalization]float64{}
+	for _, n := range []Normalization{NormalizeMinMax, NormalizeZScore, NormalizeRobust} {
+		counter := BoxCounter{Normalize: n}
+		before, _ := counter.Fit(walk)
+		after, _ := counter.Fit(spiked)
+		shift[n] = math.Abs(after - before)
+	}
+	if shift[NormalizeMinMax] < 0.1 || shift[NormalizeRobust] > 0.02 || shift[NormalizeRobust] > shift[NormalizeMinMax]/10 {
+		t.Errorf("dimension shifts %v, want min-max moved and robust at a tenth of it or less", shift)
+	}
+	if !(shift[NormalizeZScore] < shift[NormalizeMinMax]) {
+		t.Errorf("z-score shift %v, want below min-max's %v", shift[NormalizeZScore], shift[NormalizeMinMax])
+	}
+}
+
+// Each method's band maps onto [0,1], with points outside clipped to it.
+func TestNormalise(t *testing.T) {
+	values := make([]float64, 101)
+	for i := range values {
+		values[i] = float64(i)
+	}
+	values[100] = 1000 // spike
+	tests := []struct {
+		counter BoxCounter
+		lo, hi  float64 // the band the grid spans
+	}{
+		{BoxCounter{}, 0, 1000},
+		{BoxCounter{Normalize: NormalizeMinMax}, 0, 1000},
+		{BoxCounter{Normalize: NormalizeRobust, ClipQuantile: 0.1}, Percentile(values, 10), Percentile(values, 90)},
+		{BoxCounter{Normalize: NormalizeRobust}, Percentile(values, 1), Percentile(values, 99)},
+	}
+	for _, tt := range tests {
+		norm, _, ok := tt.counter.normalise(values, values)
+		if !ok {
+			t.Errorf("%s: not normalised", tt.counter.Normalize)
+			continue
+		}
+		for i, v := range values {
+			want := (math.Max(tt.lo, math.Min(tt.hi, v)) - tt.lo) / (tt.hi - tt.lo)
+			if math.Abs(norm[i]-want) > 1e-12 {
+				t.Errorf("%s: value %v normalised to %v, want %v", tt.counter.Normalize, v, norm[i], want)
+				break
+			}
+		}
+	}
+
+	for _, name := range []string{"minmax", "zscore", "robust"} {
+		if n, err := ParseNormalization(name); err != nil || string(n) != name {
+			t.Errorf("ParseNormalization(%q) = %q, %v", name, n, err)
+		}
+	}
+	if _, err := ParseNormalization("chebyshev"); err == nil {
+		t.Error("ParseNormalization(chebyshev): no error")
+	}
+}
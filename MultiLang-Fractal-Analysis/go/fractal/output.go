@@ -0,0 +1,79 @@
+package fractal
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+	"os"
+	"strings"
+)
+
+// Creates filename for writing, gzip-compressed when it ends in ".gz".
+// Close finishes the gzip stream before closing the file, so callers must
+// flush any buffered writer on top of it first.
+func createOutput(filename string) (io.WriteCloser, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(filename, ".gz") {
+		return file, nil
+	}
+	return gzipWriteFile{gzip.NewWriter(file), file}, nil
+}
+
+// Flushes writer and closes the file beneath it, returning the first
+// error, such as a full disk, that either reports. Writers defer their own
+// Flush and Close too, which only matter on early returns.
+func finishCSV(writer *csv.Writer, file io.Closer) error {
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Opens filename for reading, decompressing it when it ends in ".gz".
+func openInput(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(filename, ".gz") {
+		return file, nil
+	}
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return gzipReadFile{zr, file}, nil
+}
+
+// gzip streams paired with their file so Close finishes both, in order.
+type gzipWriteFile struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (g gzipWriteFile) Close() error {
+	err := g.Writer.Close()
+	if cerr := g.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+type gzipReadFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g gzipReadFile) Close() error {
+	err := g.Reader.Close()
+	if cerr := g.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package fractal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// The records of the CSV file at path, decompressed when it ends in ".gz".
+func readCSVFile(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := openInput(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+// Each writer gzipped reads back through gzip.Reader byte for byte as its
+// plain output, and the market data through ReadMarketCSV as well.
+func TestGzipOutputs(t *testing.T) {
+	data := GenerateSeries(NewRand(43, 0), 200, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	results, err := Analyzer{}.Run(context.Background(), data, []Window{{0, 100}, {100, 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writers := []struct {
+		name  string
+		write func(string) error
+	}{
+		{"market_data.csv", func(f string) error { return WriteMarketCSV(data, f) }},
+		{"fractal_results.csv", func(f string) error { return WriteFractalCSV(results, f) }},
+		{"summary.csv", func(f string) error { return WriteSummary(data, results, f) }},
+	}
+	dir := t.TempDir()
+	for _, w := range writers {
+		plain := filepath.Join(dir, w.name)
+		gz := plain + ".gz"
+		if err := w.write(plain); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.write(gz); err != nil {
+			t.Fatal(err)
+		}
+
+		file, err := os.Open(gz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("%s: %v", gz, err)
+		}
+		got, err := io.ReadAll(zr)
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", gz, err)
+		}
+		want, _ := os.ReadFile(plain)
+		if !bytes.Equal(got, want) || len(want) == 0 {
+			t.Errorf("%s: %d bytes decompressed, want the %d of %s", gz, len(got), len(want), w.name)
+		}
+	}
+
+	back, err := ReadMarketCSV(filepath.Join(dir, "market_data.csv.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != len(data) || math.Abs(back[len(back)-1].Price-data[len(data)-1].Price) > 1e-6 {
+		t.Errorf("read back %d candles, want %d with the same prices", len(back), len(data))
+	}
+}
+
+// A window of constant prices falls back to dimension 1 and says so in
+// fractal_results.csv; a moving window doesn't, and both report their
+// compute time.
+func TestDegenerateWindow(t *testing.T) {
+	data := GenerateSeries(NewRand(101, 0), 6000, 100)
+	for i := range data[:1000] {
+		data[i].Price = 100
+	}
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	results, err := Analyzer{}.Run(context.Background(), data, []Window{{0, 1000}, {1000, 5000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := results[0]; !r.Degenerate || r.Dimension != 1 || r.ComputeMicros < 0 {
+		t.Errorf("constant window: %+v, want degenerate at dimension 1", r)
+	}
+	if r := results[1]; r.Degenerate || r.Dimension == 1 || r.ComputeMicros <= 0 {
+		t.Errorf("moving window: %+v, want a fitted dimension and a compute time", r)
+	}
+
+	path := filepath.Join(t.TempDir(), "fractal_results.csv")
+	if err := WriteFractalCSV(results, path); err != nil {
+		t.Fatal(err)
+	}
+	rows := readCSVFile(t, path)
+	col := slices.Index(rows[0], "Degenerate")
+	if col < 1 || rows[0][col-1] != "ComputeMicros" {
+		t.Fatalf("header %v, want ComputeMicros then Degenerate", rows[0])
+	}
+	for i, want := range []string{"true", "false"} {
+		if got := rows[i+1][col]; got != want {
+			t.Errorf("window %d: Degenerate %s, want %s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package fractal
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMarketParquetRoundTrip(t *testing.T) {
+	generated := GenerateSeries(NewRand(25, 0), 500, 100)
+	ComputeReturnsAndVol(generated, DefaultVolWindow)
+	tests := []struct {
+		name string
+		data []MarketCandle
+	}{
+		{"generated", generated},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "market_data.parquet")
+		if err := WriteMarketParquet(tt.data, path); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadMarketParquet(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.data) {
+			t.Fatalf("%s: read %d candles, want %d", tt.name, len(got), len(tt.data))
+		}
+		for i, want := range tt.data {
+			c := got[i]
+			if !c.Timestamp.Equal(want.Timestamp.Truncate(time.Millisecond)) {
+				t.Errorf("%s: candle %d at %v, want %v", tt.name, i, c.Timestamp, want.Timestamp)
+			}
+			fields := [][2]float64{
+				{c.Price, want.Price}, {c.Volume, want.Volume}, {c.Returns, want.Returns},
+				{c.Volatility, want.Volatility}, {c.Open, want.Open}, {c.High, want.High},
+				{c.Low, want.Low}, {c.Close, want.Close},
+			}
+			for _, f := range fields {
+				if math.Abs(f[0]-f[1]) > 1e-12 {
+					t.Errorf("%s: candle %d is %+v, want %+v", tt.name, i, c, want)
+					break
+				}
+			}
+		}
+	}
+}
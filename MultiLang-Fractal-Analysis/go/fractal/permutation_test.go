@@ -0,0 +1,39 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPermutationEntropy(t *testing.T) {
+	ramp := make([]float64, 2000)
+	for i := range ramp {
+		ramp[i] = float64(i)
+	}
+	shuffled := append([]float64(nil), ramp...)
+	NewRand(42, 0).Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	tests := []struct {
+		name   string
+		series []float64
+		order  int
+		delay  int
+		lo, hi float64
+	}{
+		{"monotone up", ramp, 3, 1, 0, 1e-12},
+		{"monotone up, order 5 delay 2", ramp, 5, 2, 0, 1e-12},
+		{"shuffled", shuffled, 3, 1, 0.99, 1},
+		{"shuffled order 4", shuffled, 4, 1, 0.98, 1},
+	}
+	for _, tt := range tests {
+		if got := PermutationEntropy(tt.series, tt.order, tt.delay); !(got >= tt.lo && got <= tt.hi) {
+			t.Errorf("%s: %v, want in [%v, %v]", tt.name, got, tt.lo, tt.hi)
+		}
+	}
+
+	for _, bad := range []struct{ n, order, delay int }{{10, 1, 1}, {10, 3, 0}, {4, 3, 2}} {
+		if got := PermutationEntropy(ramp[:bad.n], bad.order, bad.delay); !math.IsNaN(got) {
+			t.Errorf("n %d order %d delay %d: %v, want NaN", bad.n, bad.order, bad.delay, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPetrosianFractalDimension(t *testing.T) {
+	ramp := make([]float64, 100)
+	alternating := make([]float64, 100)
+	for i := range ramp {
+		ramp[i] = float64(i)
+		alternating[i] = float64(i % 2)
+	}
+	// 98 sign changes over 100 points
+	zigzag := 2 / (2 + math.Log10(100/(100+0.4*98)))
+
+	tests := []struct {
+		name   string
+		series []float64
+		want   float64
+	}{
+		{"smooth ramp", ramp, 1},
+		{"alternating", alternating, zigzag},
+		{"flat step keeps direction", []float64{0, 1, 1, 2, 1}, math.Log10(5) / (math.Log10(5) + math.Log10(5/5.4))}, // one change,
+		{"two points", []float64{1, 2}, 1},
+	}
+	for _, tt := range tests {
+		if got := PetrosianFractalDimension(tt.series); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if zigzag <= 1.05 {
+		t.Errorf("alternating dimension %v, want well above the ramp's 1", zigzag)
+	}
+}
@@ -0,0 +1,79 @@
+package fractal
+
+import (
+	"context"
+	"math"
+)
+
+// ProfileDiff pairs the results for one analysis window of two series, the
+// window taken at the same fraction of each series' length.
+type ProfileDiff struct {
+	StartFraction float64 // where the window starts, as a fraction of length
+	EndFraction   float64 // and where it ends, exclusive
+	A, B          FractalResult
+}
+
+// ScaleWindow maps w, a window of a from-candle series, to the window at
+// the same fractions of a to-candle series, rounding to whole candles.
+// The result holds at least one candle and lies inside the series.
+func ScaleWindow(w Window, from, to int) Window {
+	if from == to {
+		return w
+	}
+	scale := float64(to) / float64(from)
+	start := int(math.Round(float64(w.Start) * scale))
+	end := int(math.Round(float64(w.Start+w.Size) * scale))
+	start = min(start, to-1)
+	end = max(min(end, to), start+1)
+	return Window{Start: start, Size: end - start}
+}
+
+// CompareProfiles analyses windows of x, and the same windows scaled by
+// ScaleWindow onto y, with a's settings, so series of different lengths
+// are compared at matching fractions of their length. Windows are
+// clipped to x as Run does and those starting outside it are skipped.
+// Results are in the order of windows.
+func (a Analyzer) CompareProfiles(ctx context.Context, x, y []MarketCandle, windows []Window) ([]ProfileDiff, error) {
+	if len(x) == 0 || len(y) == 0 {
+		return nil, nil
+	}
+
+	var inX, inY []Window
+	for _, w := range windows {
+		if w.Start < 0 || w.Start >= len(x) || w.Size <= 0 {
+			continue
+		}
+		w.Size = min(w.Size, len(x)-w.Start)
+		inX = append(inX, w)
+		inY = append(inY, ScaleWindow(w, len(x), len(y)))
+	}
+
+	resultsX, err := a.Run(ctx, x, inX)
+	if err != nil {
+		return nil, err
+	}
+	resultsY, err := a.Run(ctx, y, inY)
+	if err != nil {
+		return nil, err
+	}
+	// Run sorts its results, so pair them back up by window
+	byWindow := func(results []FractalResult) map[Window]FractalResult {
+		m := make(map[Window]FractalResult, len(results))
+		for _, r := range results {
+			m[Window{Start: r.WindowStart, Size: r.WindowEnd - r.WindowStart + 1}] = r
+		}
+		return m
+	}
+	fromX, fromY := byWindow(resultsX), byWindow(resultsY)
+
+	diffs := make([]ProfileDiff, 0, len(inX))
+	for i, w := range inX {
+		diffs = append(diffs, ProfileDiff{
+			StartFraction: float64(w.Start) / float64(len(x)),
+			EndFraction:   float64(w.Start+w.Size) / float64(len(x)),
+			A:             fromX[w],
+			B:             fromY[inY[i]],
+		})
+	}
+	return diffs, nil
+}
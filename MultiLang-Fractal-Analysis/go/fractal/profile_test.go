@@ -0,0 +1,61 @@
+package fractal
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestScaleWindow(t *testing.T) {
+	tests := []struct {
+		w        Window
+		from, to int
+		want     Window
+	}{
+		{Window{100, 50}, 1000, 1000, Window{100, 50}},
+		{Window{100, 50}, 1000, 2000, Window{200, 100}},
+		{Window{100, 50}, 1000, 500, Window{50, 25}},
+		{Window{0, 1000}, 1000, 333, Window{0, 333}},
+		{Window{999, 1}, 1000, 10, Window{9, 1}}, // at least one candle, inside the series
+	}
+	for _, tt := range tests {
+		if got := ScaleWindow(tt.w, tt.from, tt.to); got != tt.want {
+			t.Errorf("ScaleWindow(%v, %d, %d) = %v, want %v", tt.w, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// Comparing a series with itself gives zero differences in every window
+// and every written diff column.
+func TestCompareProfilesSelf(t *testing.T) {
+	data := GenerateSeries(NewRand(7, 0), 3000, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	windows := []Window{{0, 3000}, {2000, 1000}, {2500, 500}, {0, 2000}, {1000, 2000}}
+	names := []string{"full", "last1k", "last500", "first2k", "mid2k"}
+
+	diffs, err := Analyzer{}.CompareProfiles(context.Background(), data, data, windows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != len(windows) {
+		t.Fatalf("%d diffs, want %d", len(diffs), len(windows))
+	}
+	for i, d := range diffs {
+		if d.A.WindowStart != d.B.WindowStart || d.A.WindowEnd != d.B.WindowEnd {
+			t.Errorf("%s: windows %d-%d and %d-%d differ", names[i], d.A.WindowStart, d.A.WindowEnd, d.B.WindowStart, d.B.WindowEnd)
+		}
+		if d.B.Dimension-d.A.Dimension != 0 || d.B.Hurst-d.A.Hurst != 0 {
+			t.Errorf("%s: dimension diff %v, Hurst diff %v, want 0", names[i], d.B.Dimension-d.A.Dimension, d.B.Hurst-d.A.Hurst)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "compare.csv")
+	if err := WriteCompareCSV(names, diffs, path); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range readCSVFile(t, path)[1:] {
+		if row[9] != "0.000000" || row[12] != "0.000000" {
+			t.Errorf("%s: DimensionDiff %s, HurstDiff %s, want 0.000000", row[0], row[9], row[12])
+		}
+	}
+}
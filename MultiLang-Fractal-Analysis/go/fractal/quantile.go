@@ -0,0 +1,183 @@
+package fractal
+
+import "math"
+
+// RollingQuantile tracks quantiles of the last window values of a stream.
+// Values are kept in arrival order in a ring buffer and ranked in an
+// order-statistic treap, so Push and Pop are O(log n) expected and any
+// quantile is read in O(log n), unlike re-sorting each window. NaN values
+// take their place in the window but are not ranked.
+//
+// A RollingQuantile is not safe for concurrent use.
+type RollingQuantile struct {
+	ring  []rankedValue
+	next  int // ring index the next value is written to
+	count int
+	root  *quantileNode
+	seq   uint64 // arrival number, which breaks ties between equal values
+	prio  uint64 // splitmix64 state for treap priorities
+}
+
+// A value with its arrival number, the treap's unique key.
+type rankedValue struct {
+	value float64
+	seq   uint64
+}
+
+func (a rankedValue) less(b rankedValue) bool {
+	return a.value < b.value || (a.value == b.value && a.seq < b.seq)
+}
+
+type quantileNode struct {
+	key         rankedValue
+	prio        uint64
+	size        int // nodes in this subtree
+	left, right *quantileNode
+}
+
+func (n *quantileNode) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *quantileNode) update() { n.size = 1 + n.left.len() + n.right.len() }
+
+// NewRollingQuantile returns a tracker over the last window values. A
+// window below 1 is treated as 1.
+func NewRollingQuantile(window int) *RollingQuantile {
+	if window < 1 {
+		window = 1
+	}
+	return &RollingQuantile{ring: make([]rankedValue, window)}
+}
+
+// Push appends a value, evicting the oldest once the window is full.
+func (r *RollingQuantile) Push(v float64) {
+	if r.count == len(r.ring) {
+		r.Pop()
+	}
+	key := rankedValue{value: v, seq: r.seq}
+	r.seq++
+	r.ring[r.next] = key
+	r.next = (r.next + 1) % len(r.ring)
+	r.count++
+	if !math.IsNaN(v) {
+		r.root = r.insert(r.root, key)
+	}
+}
+
+// Pop removes and returns the oldest value; ok is false when the window
+// is empty.
+func (r *RollingQuantile) Pop() (v float64, ok bool) {
+	if r.count == 0 {
+		return 0, false
+	}
+	oldest := (r.next - r.count + len(r.ring)) % len(r.ring)
+	key := r.ring[oldest]
+	r.count--
+	if !math.IsNaN(key.value) {
+		r.root = treapRemove(r.root, key)
+	}
+	return key.value, true
+}
+
+// Len reports how many values are in the window, NaNs included.
+func (r *RollingQuantile) Len() int { return r.count }
+
+// Quantile returns the q-th quantile (0-1) of the window's non-NaN values,
+// interpolated between the closest ranks as Percentile does. q is clamped
+// to [0, 1]; a window with no values yields NaN.
+func (r *RollingQuantile) Quantile(q float64) float64 {
+	n := r.root.len()
+	if n == 0 {
+		return math.NaN()
+	}
+	q = math.Max(0, math.Min(1, q))
+
+	h := q * float64(n-1)
+	lo := int(math.Floor(h))
+	if lo >= n-1 {
+		return treapKth(r.root, n-1)
+	}
+	a := treapKth(r.root, lo)
+	return a + (h-float64(lo))*(treapKth(r.root, lo+1)-a)
+}
+
+// Next treap priority.
+func (r *RollingQuantile) priority() uint64 {
+	r.prio += 0x9e3779b97f4a7c15
+	z := r.prio
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (r *RollingQuantile) insert(root *quantileNode, key rankedValue) *quantileNode {
+	left, right := treapSplit(root, key)
+	node := &quantileNode{key: key, prio: r.priority(), size: 1}
+	return treapMerge(treapMerge(left, node), right)
+}
+
+// Splits t into the keys below key and the rest.
+func treapSplit(t *quantileNode, key rankedValue) (left, right *quantileNode) {
+	if t == nil {
+		return nil, nil
+	}
+	if t.key.less(key) {
+		t.right, right = treapSplit(t.right, key)
+		t.update()
+		return t, right
+	}
+	left, t.left = treapSplit(t.left, key)
+	t.update()
+	return left, t
+}
+
+// Joins a and b, every key in a being below every key in b.
+func treapMerge(a, b *quantileNode) *quantileNode {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.prio > b.prio:
+		a.right = treapMerge(a.right, b)
+		a.update()
+		return a
+	}
+	b.left = treapMerge(a, b.left)
+	b.update()
+	return b
+}
+
+func treapRemove(t *quantileNode, key rankedValue) *quantileNode {
+	switch {
+	case t == nil:
+		return nil
+	case key.less(t.key):
+		t.left = treapRemove(t.left, key)
+	case t.key.less(key):
+		t.right = treapRemove(t.right, key)
+	default:
+		return treapMerge(t.left, t.right)
+	}
+	t.update()
+	return t
+}
+
+// The k-th smallest value (0-based) in t.
+func treapKth(t *quantileNode, k int) float64 {
+	for {
+		switch l := t.left.len(); {
+		case k < l:
+			t = t.left
+		case k == l:
+			return t.key.value
+		default:
+			k -= l + 1
+			t = t.right
+		}
+	}
+}
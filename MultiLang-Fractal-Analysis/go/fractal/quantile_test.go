@@ -0,0 +1,66 @@
+package fractal
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// Every quantile of every sliding window matches sorting the window, with
+// ties and NaNs in the stream.
+func TestRollingQuantileMatchesBruteForce(t *testing.T) {
+	rng := NewRand(92, 0)
+	stream := make([]float64, 2000)
+	for i := range stream {
+		stream[i] = math.Round(10 * rng.NormFloat64()) // plenty of ties
+		if rng.Intn(20) == 0 {
+			stream[i] = math.NaN()
+		}
+	}
+	qs := []float64{0, 0.1, 0.25, 0.5, 0.9, 1}
+	for _, window := range []int{1, 7, 64, 500} {
+		r := NewRollingQuantile(window)
+		for i, v := range stream {
+			r.Push(v)
+			var sorted []float64
+			for _, w := range stream[max(0, i+1-window) : i+1] {
+				if !math.IsNaN(w) {
+					sorted = append(sorted, w)
+				}
+			}
+			sort.Float64s(sorted)
+			if r.Len() != min(i+1, window) {
+				t.Fatalf("window %d step %d: Len %d", window, i, r.Len())
+			}
+			for _, q := range qs {
+				got, want := r.Quantile(q), Percentile(sorted, 100*q)
+				if math.IsNaN(got) != math.IsNaN(want) || math.Abs(got-want) > 1e-12 {
+					t.Fatalf("window %d step %d: quantile %v %v, want %v", window, i, q, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRollingQuantilePop(t *testing.T) {
+	r := NewRollingQuantile(3)
+	for _, v := range []float64{5, 1, 4, 2} {
+		r.Push(v)
+	}
+	if got := r.Quantile(0.5); got != 2 {
+		t.Errorf("median of 1, 4, 2 = %v, want 2", got)
+	}
+	for _, want := range []float64{1, 4, 2} {
+		if v, ok := r.Pop(); !ok || v != want {
+			t.Errorf("Pop = %v, %v, want %v in arrival order", v, ok, want)
+		}
+	}
+	if _, ok := r.Pop(); ok || r.Len() != 0 || !math.IsNaN(r.Quantile(0.5)) {
+		t.Errorf("empty window: Pop ok %v, Len %d, median %v", ok, r.Len(), r.Quantile(0.5))
+	}
+	one := NewRollingQuantile(0)
+	one.Push(3)
+	if one.Quantile(2) != 3 || one.Quantile(-1) != 3 {
+		t.Errorf("window 0: quantiles %v and %v, want 3 with q clamped", one.Quantile(2), one.Quantile(-1))
+	}
+}
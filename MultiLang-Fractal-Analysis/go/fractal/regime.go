@@ -0,0 +1,73 @@
+package fractal
+
+import (
+	"math"
+	"slices"
+)
+
+// Regime labels returned by ClassifyRegime.
+const (
+	RegimeTrending = "trending"
+	RegimeRandom   = "random"
+	RegimeChoppy   = "choppy"
+	RegimeUnknown  = "unknown" // the dimension is NaN
+)
+
+// RegimeConfig holds the dimension thresholds separating market regimes.
+type RegimeConfig struct {
+	TrendingBelow float64 // dimensions below this are trending
+	ChoppyAbove   float64 // dimensions above this are choppy
+}
+
+// RegimeTerciles places the thresholds at the lower and upper terciles of
+// the results' dimensions, so each regime holds about a third of the
+// windows whatever range the estimator reports. Fixed cut-offs such as the
+// conventional 1.4 / 1.6 suit only estimators spanning 1 to 2, and the
+// normalised box count of a price path sits well below 1.4. NaN
+// dimensions are ignored, and with none left both thresholds are NaN,
+// labelling every window random.
+func RegimeTerciles(results []FractalResult) RegimeConfig {
+	var dims []float64
+	for _, r := range results {
+		if !math.IsNaN(r.Dimension) {
+			dims = append(dims, r.Dimension)
+		}
+	}
+	slices.Sort(dims)
+	return RegimeConfig{TrendingBelow: Percentile(dims, 100.0/3), ChoppyAbove: Percentile(dims, 200.0/3)}
+}
+
+// ClassifyRegime labels a window by its fractal dimension: trending below
+// cfg.TrendingBelow, choppy above cfg.ChoppyAbove and random in between,
+// bounds included.
+func ClassifyRegime(d float64, cfg RegimeConfig) string {
+	switch {
+	case math.IsNaN(d):
+		return RegimeUnknown
+	case d < cfg.TrendingBelow:
+		return RegimeTrending
+	case d > cfg.ChoppyAbove:
+		return RegimeChoppy
+	}
+	return RegimeRandom
+}
+
+// RegimeCounts returns summary rows with the thresholds and the number of
+// results in each regime.
+func RegimeCounts(results []FractalResult, cfg RegimeConfig) []Metric {
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[ClassifyRegime(r.Dimension, cfg)]++
+	}
+	metrics := []Metric{
+		{"RegimeTrendingBelow", cfg.TrendingBelow},
+		{"RegimeChoppyAbove", cfg.ChoppyAbove},
+		{"RegimeTrending", counts[RegimeTrending]},
+		{"RegimeRandom", counts[RegimeRandom]},
+		{"RegimeChoppy", counts[RegimeChoppy]},
+	}
+	if n := counts[RegimeUnknown]; n > 0 {
+		metrics = append(metrics, Metric{"RegimeUnknown", n})
+	}
+	return metrics
+}
@@ -0,0 +1,94 @@
+package fractal
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestClassifyRegime(t *testing.T) {
+	cfg := RegimeConfig{TrendingBelow: 1.2, ChoppyAbove: 1.5}
+	tests := []struct {
+		d    float64
+		want string
+	}{
+		{1.1, RegimeTrending},
+		{1.2, RegimeRandom}, // bounds are random
+		{1.35, RegimeRandom},
+		{1.5, RegimeRandom},
+		{1.6, RegimeChoppy},
+		{math.NaN(), RegimeUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyRegime(tt.d, cfg); got != tt.want {
+			t.Errorf("ClassifyRegime(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestRegimeTerciles(t *testing.T) {
+	results := func(dims ...float64) []FractalResult {
+		rs := make([]FractalResult, len(dims))
+		for i, d := range dims {
+			rs[i].Dimension = d
+		}
+		return rs
+	}
+	tests := []struct {
+		name string
+		rs   []FractalResult
+		want RegimeConfig
+	}{
+		{"order does not matter", results(0.7, 0.1, 0.4, 0.3, 0.5, 0.2, 0.6), RegimeConfig{0.3, 0.5}},
+		{"NaN ignored", results(math.NaN(), 0, 3, math.NaN(), 6), RegimeConfig{2, 4}},
+		{"single window", results(1.3), RegimeConfig{1.3, 1.3}},
+	}
+	for _, tt := range tests {
+		got := RegimeTerciles(tt.rs)
+		if math.Abs(got.TrendingBelow-tt.want.TrendingBelow) > 1e-12 || math.Abs(got.ChoppyAbove-tt.want.ChoppyAbove) > 1e-12 {
+			t.Errorf("%s: %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	got := RegimeTerciles(results(math.NaN()))
+	if !math.IsNaN(got.TrendingBelow) || !math.IsNaN(got.ChoppyAbove) {
+		t.Errorf("all NaN: %+v, want NaN thresholds", got)
+	}
+	if counts := RegimeCounts(results(math.NaN(), math.NaN()), got); metricCount(counts, "RegimeRandom") != 0 {
+		t.Errorf("all NaN: counts %v, want every window unknown", counts)
+	}
+}
+
+// On a default-sized generated market the box-counting dimensions sit well
+// below 1.4, so the conventional cut-offs label every rolling window
+// trending; the derived thresholds find all three regimes.
+func TestRegimeTercilesFindBothRegimes(t *testing.T) {
+	data := GenerateSeries(NewRand(42, 0), 10000, 100)
+	rolling, err := Analyzer{}.RunRolling(context.Background(), SeriesPrice.Values(data), 500, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixed := RegimeCounts(rolling, RegimeConfig{TrendingBelow: 1.4, ChoppyAbove: 1.6})
+	if got := metricCount(fixed, "RegimeTrending"); got != len(rolling) {
+		t.Errorf("1.4 / 1.6: %d of %d windows trending, want all", got, len(rolling))
+	}
+
+	cfg := RegimeTerciles(rolling)
+	counts := RegimeCounts(rolling, cfg)
+	for _, name := range []string{"RegimeTrending", "RegimeRandom", "RegimeChoppy"} {
+		if got := metricCount(counts, name); got < len(rolling)/4 || got > len(rolling)/2 {
+			t.Errorf("%s: %d of %d windows under %+v, want about a third", name, got, len(rolling), cfg)
+		}
+	}
+}
+
+// The count in RegimeCounts' row name, or 0 when the row is absent.
+func metricCount(metrics []Metric, name string) int {
+	for _, m := range metrics {
+		if m.Name == name {
+			return m.Value.(int)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// The running sum of a binomial multiplicative cascade over 2^levels
+// cells, each split p : 1-p at every level: a devil's staircase whose
+// moves concentrate more unevenly the further p is from 0.5.
+func cascadeStaircase(levels int, p float64) []float64 {
+	mass := []float64{1}
+	for l := 0; l < levels; l++ {
+		next := make([]float64, 0, 2*len(mass))
+		for _, m := range mass {
+			next = append(next, m*p, m*(1-p))
+		}
+		mass = next
+	}
+	path := make([]float64, len(mass))
+	sum := 0.0
+	for i, m := range mass {
+		sum += m
+		path[i] = sum
+	}
+	return path
+}
+
+func TestRenyiDimensionsOrder(t *testing.T) {
+	// A cascade's scaling runs down to single cells, so fit every size
+	b := BoxCounter{MinPointsPerBox: 1}
+	prevSpread := 0.0
+	for _, p := range []float64{0.6, 0.7, 0.8} {
+		dq := b.RenyiDimensions(cascadeStaircase(12, p), DefaultRenyiQs)
+		if !(dq[0] > dq[1] && dq[1] > dq[2]) {
+			t.Errorf("cascade p %v: D0 %v, D1 %v, D2 %v, want decreasing", p, dq[0], dq[1], dq[2])
+		}
+		// More uneven cascades spread the spectrum wider
+		if spread := dq[0] - dq[2]; spread <= prevSpread {
+			t.Errorf("cascade p %v: D0-D2 %v, want above %v", p, spread, prevSpread)
+		} else {
+			prevSpread = spread
+		}
+	}
+
+	// A random walk is monofractal: the orders nearly agree
+	dq := b.RenyiDimensions(randomWalk(66, 4096), DefaultRenyiQs)
+	if math.Abs(dq[0]-dq[1]) > 0.05 || math.Abs(dq[1]-dq[2]) > 0.05 {
+		t.Errorf("random walk: D0 %v, D1 %v, D2 %v, want close together", dq[0], dq[1], dq[2])
+	}
+
+	for q, d := range RenyiDimensions([]float64{1, 2, 3}, DefaultRenyiQs) {
+		if d != 1 {
+			t.Errorf("three points: D%v %v, want 1", q, d)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package fractal
+
+import "math"
+
+// DefaultVolWindow is the rolling volatility window used when a candle
+// set's returns have to be derived without a caller-chosen window.
+const DefaultVolWindow = 30
+
+// Resample aggregates every factor consecutive candles into one bar
+// stamped with the group's first timestamp: open of the first, close of
+// the last, extreme high and low, and summed volume. A trailing partial
+// group becomes a final shorter bar. Simple returns and rolling volatility
+// over DefaultVolWindow are recomputed on the result. A factor of 1 or
+// less returns a copy of data.
+func Resample(data []MarketCandle, factor int) []MarketCandle {
+	if factor <= 1 {
+		return append([]MarketCandle(nil), data...)
+	}
+
+	out := make([]MarketCandle, 0, (len(data)+factor-1)/factor)
+	for start := 0; start < len(data); start += factor {
+		end := start + factor
+		if end > len(data) {
+			end = len(data)
+		}
+		group := data[start:end]
+
+		bar := MarketCandle{
+			Timestamp: group[0].Timestamp,
+			Open:      group[0].Open,
+			High:      math.Inf(-1),
+			Low:       math.Inf(1),
+			Close:     group[len(group)-1].Close,
+		}
+		for _, c := range group {
+			bar.High = math.Max(bar.High, c.High)
+			bar.Low = math.Min(bar.Low, c.Low)
+			bar.Volume += c.Volume
+		}
+		bar.Price = bar.Close
+		out = append(out, bar)
+	}
+
+	ComputeReturnsAndVol(out, DefaultVolWindow)
+	return out
+}
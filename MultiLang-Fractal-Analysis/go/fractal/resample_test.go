@@ -0,0 +1,57 @@
+package fractal
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResample(t *testing.T) {
+	hourly := GenerateSeries(NewRand(38, 0), 250, 100)
+	ComputeReturnsAndVol(hourly, DefaultVolWindow)
+
+	same := Resample(hourly, 1)
+	if !reflect.DeepEqual(same, hourly) {
+		t.Error("factor 1 changed the candles")
+	}
+	same[0].Price = -1
+	if hourly[0].Price == -1 {
+		t.Error("factor 1 returned data itself rather than a copy")
+	}
+
+	tests := []struct {
+		candles, factor, want int
+	}{
+		{240, 24, 10},
+		{250, 24, 11}, // a trailing partial day
+		{5, 10, 1},
+		{0, 24, 0},
+	}
+	for _, tt := range tests {
+		if got := Resample(hourly[:tt.candles], tt.factor); len(got) != tt.want {
+			t.Errorf("%d candles by %d: %d bars, want %d", tt.candles, tt.factor, len(got), tt.want)
+		}
+	}
+
+	daily := Resample(hourly[:240], 24)
+	for d, bar := range daily {
+		day := hourly[d*24 : (d+1)*24]
+		high, low, volume := math.Inf(-1), math.Inf(1), 0.0
+		for _, c := range day {
+			high, low, volume = math.Max(high, c.High), math.Min(low, c.Low), volume+c.Volume
+		}
+		if !bar.Timestamp.Equal(day[0].Timestamp) || bar.Open != day[0].Open || bar.Close != day[23].Close ||
+			bar.Price != bar.Close || bar.High != high || bar.Low != low || math.Abs(bar.Volume-volume) > 1e-9 {
+			t.Errorf("day %d: bar %+v does not aggregate its hours", d, bar)
+		}
+		if d > 0 {
+			if want := bar.Price/daily[d-1].Price - 1; math.Abs(bar.Returns-want) > 1e-12 {
+				t.Errorf("day %d: return %v, want %v", d, bar.Returns, want)
+			}
+		}
+		if got := bar.Timestamp.Sub(daily[0].Timestamp); got != time.Duration(d)*24*time.Hour {
+			t.Errorf("day %d starts %v after the first", d, got)
+		}
+	}
+}
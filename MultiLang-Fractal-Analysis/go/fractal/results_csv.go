@@ -0,0 +1,314 @@
+package fractal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// WriteFractalCSV writes one row per analysed window.
+func WriteFractalCSV(results []FractalResult, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	header := []string{"WindowStart", "WindowEnd", "WindowSize", "FractalDimension", "R2", "HurstExponent", "HurstAggVar", "DFAAlpha", "SampleEntropy", "ApproxEntropy", "PermutationEntropy", "LempelZiv", "BootstrapMean", "BootstrapP05", "BootstrapP95", "SurrogateMean", "SurrogateP", "ComputeMicros", "Degenerate"}
+	// Lacunarity columns follow the box sizes of the first result
+	var lacSizes []int
+	if len(results) > 0 {
+		for _, p := range results[0].Lacunarity {
+			lacSizes = append(lacSizes, p.BoxSize)
+			header = append(header, fmt.Sprintf("Lacunarity_%d", p.BoxSize))
+		}
+		// And one Dimension_<method> column per selected estimator
+		for _, m := range results[0].Methods {
+			header = append(header, "Dimension_"+m.Method)
+		}
+	}
+	writer.Write(header)
+
+	for _, r := range results {
+		record := []string{
+			strconv.Itoa(r.WindowStart),
+			strconv.Itoa(r.WindowEnd),
+			strconv.Itoa(r.WindowEnd - r.WindowStart + 1),
+			fmt.Sprintf("%.6f", r.Dimension),
+			fmt.Sprintf("%.6f", r.R2),
+			fmt.Sprintf("%.6f", r.Hurst),
+			fmt.Sprintf("%.6f", r.HurstAggVar),
+			fmt.Sprintf("%.6f", r.DFA),
+			fmt.Sprintf("%.6f", r.SampleEntropy),
+			fmt.Sprintf("%.6f", r.ApproxEntropy),
+			fmt.Sprintf("%.6f", r.PermEntropy),
+			fmt.Sprintf("%.6f", r.LempelZiv),
+			fmt.Sprintf("%.6f", r.BootstrapMean),
+			fmt.Sprintf("%.6f", r.BootstrapP05),
+			fmt.Sprintf("%.6f", r.BootstrapP95),
+			fmt.Sprintf("%.6f", r.SurrogateMean),
+			fmt.Sprintf("%.6f", r.SurrogateP),
+			strconv.FormatInt(r.ComputeMicros, 10),
+			strconv.FormatBool(r.Degenerate),
+		}
+		for i := range lacSizes {
+			v := math.NaN()
+			if i < len(r.Lacunarity) {
+				v = r.Lacunarity[i].Value
+			}
+			record = append(record, fmt.Sprintf("%.6f", v))
+		}
+		for _, m := range r.Methods {
+			record = append(record, fmt.Sprintf("%.6f", m.Dimension))
+		}
+		writer.Write(record)
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteRollingCSV writes the sliding-window dimension series with each
+// window's regime under cfg.
+func WriteRollingCSV(results []FractalResult, cfg RegimeConfig, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"WindowStart", "WindowEnd", "WindowSize", "FractalDimension", "R2", "Regime"})
+
+	for _, r := range results {
+		record := []string{
+			strconv.Itoa(r.WindowStart),
+			strconv.Itoa(r.WindowEnd),
+			strconv.Itoa(r.WindowEnd - r.WindowStart + 1),
+			fmt.Sprintf("%.6f", r.Dimension),
+			fmt.Sprintf("%.6f", r.R2),
+			ClassifyRegime(r.Dimension, cfg),
+		}
+		writer.Write(record)
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteScalingCSV writes the dimension of each trailing window against
+// its size.
+func WriteScalingCSV(results []FractalResult, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"WindowSize", "FractalDimension", "R2"})
+
+	for _, r := range results {
+		writer.Write([]string{
+			strconv.Itoa(r.WindowEnd - r.WindowStart + 1),
+			fmt.Sprintf("%.6f", r.Dimension),
+			fmt.Sprintf("%.6f", r.R2),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteRollingHurstCSV writes rolling Hurst exponents against the rolling
+// dimension windows they line up with; NaN estimates are left empty.
+func WriteRollingHurstCSV(rolling []FractalResult, hurst []float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"WindowIndex", "WindowStart", "WindowEnd", "HurstExponent"})
+
+	for i, h := range hurst {
+		if i >= len(rolling) {
+			break
+		}
+		value := ""
+		if !math.IsNaN(h) {
+			value = fmt.Sprintf("%.6f", h)
+		}
+		writer.Write([]string{
+			strconv.Itoa(i),
+			strconv.Itoa(rolling[i].WindowStart),
+			strconv.Itoa(rolling[i].WindowEnd),
+			value,
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteFDVolCSV writes each rolling window's dimension and mean volatility
+// with their trailing correlation; undefined correlations are left empty.
+func WriteFDVolCSV(rolling []FractalResult, vols, corr []float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"WindowIndex", "WindowStart", "WindowEnd", "FractalDimension", "Volatility", "Correlation"})
+
+	for i, r := range rolling {
+		value := ""
+		if !math.IsNaN(corr[i]) {
+			value = fmt.Sprintf("%.6f", corr[i])
+		}
+		writer.Write([]string{
+			strconv.Itoa(i),
+			strconv.Itoa(r.WindowStart),
+			strconv.Itoa(r.WindowEnd),
+			fmt.Sprintf("%.6f", r.Dimension),
+			fmt.Sprintf("%.6f", vols[i]),
+			value,
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteChangePointsCSV writes each change point in the rolling dimension
+// with the first window of the new segment, timestamped at that window's
+// last candle, and the mean dimension of the segments either side.
+func WriteChangePointsCSV(data []MarketCandle, rolling []FractalResult, points []int, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Index", "WindowStart", "WindowEnd", "Timestamp", "DimensionBefore", "DimensionAfter"})
+
+	dims := make([]float64, len(rolling))
+	for i, r := range rolling {
+		dims[i] = r.Dimension
+	}
+	means := segmentMeans(dims, points)
+	for i, p := range points {
+		r := rolling[p]
+		writer.Write([]string{
+			strconv.Itoa(p),
+			strconv.Itoa(r.WindowStart),
+			strconv.Itoa(r.WindowEnd),
+			data[r.WindowEnd].Timestamp.Format(timestampLayout),
+			fmt.Sprintf("%.6f", means[i]),
+			fmt.Sprintf("%.6f", means[i+1]),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteEstimatorComparisonCSV writes each window's dimension from every
+// method in its results, one column per method, followed by one row per
+// method of its mean absolute difference from each of the others.
+// Undefined values are left empty.
+func WriteEstimatorComparisonCSV(names []string, results []FractalResult, mad [][]float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	value := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return fmt.Sprintf("%.6f", v)
+	}
+
+	header := []string{"Window", "WindowStart", "WindowEnd"}
+	if len(results) > 0 {
+		for _, m := range results[0].Methods {
+			header = append(header, m.Method)
+		}
+	}
+	writer.Write(header)
+
+	for i, r := range results {
+		row := []string{names[i], strconv.Itoa(r.WindowStart), strconv.Itoa(r.WindowEnd)}
+		for _, m := range r.Methods {
+			row = append(row, value(m.Dimension))
+		}
+		writer.Write(row)
+	}
+	for i, diffs := range mad {
+		row := []string{"MAD_" + header[3+i], "", ""}
+		for _, d := range diffs {
+			row = append(row, value(d))
+		}
+		writer.Write(row)
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteCompareCSV writes one row per window of a profile comparison: the
+// window's fractional position, its candles in each series, and the
+// dimension and Hurst exponent of each with their difference B - A.
+func WriteCompareCSV(names []string, diffs []ProfileDiff, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{
+		"Window", "StartFraction", "EndFraction",
+		"WindowStartA", "WindowEndA", "WindowStartB", "WindowEndB",
+		"DimensionA", "DimensionB", "DimensionDiff",
+		"HurstA", "HurstB", "HurstDiff",
+	})
+
+	for i, d := range diffs {
+		writer.Write([]string{
+			names[i],
+			fmt.Sprintf("%.6f", d.StartFraction),
+			fmt.Sprintf("%.6f", d.EndFraction),
+			strconv.Itoa(d.A.WindowStart),
+			strconv.Itoa(d.A.WindowEnd),
+			strconv.Itoa(d.B.WindowStart),
+			strconv.Itoa(d.B.WindowEnd),
+			fmt.Sprintf("%.6f", d.A.Dimension),
+			fmt.Sprintf("%.6f", d.B.Dimension),
+			fmt.Sprintf("%.6f", d.B.Dimension-d.A.Dimension),
+			fmt.Sprintf("%.6f", d.A.Hurst),
+			fmt.Sprintf("%.6f", d.B.Hurst),
+			fmt.Sprintf("%.6f", d.B.Hurst-d.A.Hurst),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
@@ -0,0 +1,177 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+)
+
+// ReturnKind selects how ComputeReturns measures each period's return.
+type ReturnKind string
+
+const (
+	SimpleReturns ReturnKind = "simple" // (p_t - p_{t-1}) / p_{t-1}
+	LogReturns    ReturnKind = "log"    // ln(p_t / p_{t-1}), additive over time
+)
+
+// ParseReturnKind validates a return kind name.
+func ParseReturnKind(name string) (ReturnKind, error) {
+	switch k := ReturnKind(name); k {
+	case SimpleReturns, LogReturns:
+		return k, nil
+	}
+	return "", fmt.Errorf("unknown return kind %q (want simple or log)", name)
+}
+
+// ComputeReturnsAndVol fills simple Returns and a rolling standard
+// deviation of returns over the preceding window candles.
+func ComputeReturnsAndVol(data []MarketCandle, window int) {
+	ComputeReturns(data, SimpleReturns)
+	ComputeRollingVolatility(data, window)
+}
+
+// ComputeReturns fills Returns from consecutive prices; the first candle
+// has no predecessor and gets 0. An empty kind means simple returns.
+func ComputeReturns(data []MarketCandle, kind ReturnKind) {
+	if len(data) > 0 {
+		data[0].Returns = 0
+	}
+	for i := 1; i < len(data); i++ {
+		if kind == LogReturns {
+			data[i].Returns = math.Log(data[i].Price / data[i-1].Price)
+		} else {
+			data[i].Returns = (data[i].Price - data[i-1].Price) / data[i-1].Price
+		}
+	}
+}
+
+// ComputeRollingVolatility fills Volatility with the sample standard
+// deviation of whatever Returns hold over the preceding window candles.
+// The first window candles get 0, as does every candle if window < 2. The
+// window's mean and sum of squared deviations are slid along with
+// Welford-style updates, so each candle costs O(1) whatever the window.
+func ComputeRollingVolatility(data []MarketCandle, window int) {
+	for i := 0; i < len(data) && (i < window || window < 2); i++ {
+		data[i].Volatility = 0
+	}
+	if window < 2 || len(data) <= window {
+		return
+	}
+
+	// Candle i covers returns [i-window, i)
+	first := SeriesReturns.Values(data[:window])
+	m, m2 := mean(first), variance(first)*float64(window-1)
+
+	for i := window; i < len(data); i++ {
+		if i > window {
+			in, out := data[i-1].Returns, data[i-1-window].Returns
+			prevMean := m
+			m += (in - out) / float64(window)
+			m2 += (in - out) * (in - m + out - prevMean)
+			if m2 < 0 {
+				m2 = 0 // rounding on a flat window
+			}
+		}
+		data[i].Volatility = math.Sqrt(m2 / float64(window-1))
+	}
+}
+
+// VolatilityWarmUp counts the leading candles whose Volatility is still 0,
+// the warm-up ComputeRollingVolatility leaves before its first full
+// window. Statistics of volatility skip these so the placeholder zeros
+// don't drag them down.
+func VolatilityWarmUp(data []MarketCandle) int {
+	n := 0
+	for n < len(data) && data[n].Volatility == 0 {
+		n++
+	}
+	return n
+}
+
+// DefaultEWMALambda is the RiskMetrics decay for daily-style data.
+const DefaultEWMALambda = 0.94
+
+// ComputeEWMAVolatility replaces Volatility with the exponentially weighted
+// RiskMetrics estimate σ²_t = λσ²_{t-1} + (1-λ)r²_t. Returns must already be
+// filled. The recursion is seeded from the first return's square, so the
+// leading candles carry that estimate instead of zero. A lambda outside
+// (0, 1) uses DefaultEWMALambda.
+func ComputeEWMAVolatility(data []MarketCandle, lambda float64) {
+	if lambda <= 0 || lambda >= 1 {
+		lambda = DefaultEWMALambda
+	}
+	if len(data) < 2 {
+		for i := range data {
+			data[i].Volatility = 0
+		}
+		return
+	}
+
+	// data[0] has no return of its own
+	variance := data[1].Returns * data[1].Returns
+	data[0].Volatility = math.Sqrt(variance)
+	data[1].Volatility = math.Sqrt(variance)
+
+	for i := 2; i < len(data); i++ {
+		r := data[i].Returns
+		variance = lambda*variance + (1-lambda)*r*r
+		data[i].Volatility = math.Sqrt(variance)
+	}
+}
+
+// DefaultVolHalfLife is the half-life, in candles, of the weighted
+// volatility's weights when none is given.
+const DefaultVolHalfLife = 10.0
+
+// WeightedVolatility returns, for each index i, the exponentially weighted
+// standard deviation of returns[i-window:i], the return k steps before
+// i-1 weighted by 0.5^(k/halfLife) against the most recent. The weights are
+// normalized to sum to one and the variance is divided by 1 - Σw², which
+// makes it unbiased and, as halfLife grows without bound, equal to the
+// sample standard deviation ComputeRollingVolatility takes. The first
+// window values are 0, as is everything if window < 2; a halfLife that
+// isn't positive uses DefaultVolHalfLife.
+func WeightedVolatility(returns []float64, window int, halfLife float64) []float64 {
+	vol := make([]float64, len(returns))
+	if window < 2 || len(returns) <= window {
+		return vol
+	}
+	if !(halfLife > 0) {
+		halfLife = DefaultVolHalfLife
+	}
+
+	// weights[j] is for returns[i-window+j], so the last is the newest
+	weights := make([]float64, window)
+	sum := 0.0
+	for j := range weights {
+		weights[j] = math.Pow(0.5, float64(window-1-j)/halfLife)
+		sum += weights[j]
+	}
+	sumSq := 0.0
+	for j := range weights {
+		weights[j] /= sum
+		sumSq += weights[j] * weights[j]
+	}
+
+	for i := window; i < len(returns); i++ {
+		r := returns[i-window : i]
+		m := 0.0
+		for j, w := range weights {
+			m += w * r[j]
+		}
+		ss := 0.0
+		for j, w := range weights {
+			ss += w * (r[j] - m) * (r[j] - m)
+		}
+		vol[i] = math.Sqrt(ss / (1 - sumSq))
+	}
+	return vol
+}
+
+// ComputeWeightedVolatility replaces Volatility with WeightedVolatility of
+// the candles' Returns, with the same warm-up of zeros as
+// ComputeRollingVolatility.
+func ComputeWeightedVolatility(data []MarketCandle, window int, halfLife float64) {
+	for i, v := range WeightedVolatility(SeriesReturns.Values(data), window, halfLife) {
+		data[i].Volatility = v
+	}
+}
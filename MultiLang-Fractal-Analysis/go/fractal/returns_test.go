@@ -0,0 +1,203 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Candles holding the given returns, the first of which has none.
+func candlesWithReturns(returns ...float64) []MarketCandle {
+	data := make([]MarketCandle, len(returns))
+	for i, r := range returns {
+		data[i].Returns = r
+	}
+	return data
+}
+
+func TestComputeEWMAVolatility(t *testing.T) {
+	tests := []struct {
+		name    string
+		returns []float64
+		lambda  float64
+		want    []float64 // variances, by hand from σ²_t = λσ²_{t-1} + (1-λ)r²_t
+	}{
+		{
+			name:    "seeded from the first return",
+			returns: []float64{0, 0.1, 0, 0.2},
+			lambda:  0.5,
+			// 0.01, 0.01, 0.5*0.01, 0.5*0.005 + 0.5*0.04
+			want: []float64{0.01, 0.01, 0.005, 0.0225},
+		},
+		{
+			name:    "out-of-range lambda uses the default",
+			returns: []float64{0, 0.1, 0.1},
+			lambda:  1,
+			want:    []float64{0.01, 0.01, 0.01},
+		},
+		{
+			name:    "default decay",
+			returns: []float64{0, 0.02, 0},
+			lambda:  DefaultEWMALambda,
+			want:    []float64{0.0004, 0.0004, 0.94 * 0.0004},
+		},
+		{name: "one candle", returns: []float64{0}, lambda: 0.9, want: []float64{0}},
+	}
+	for _, tt := range tests {
+		data := candlesWithReturns(tt.returns...)
+		ComputeEWMAVolatility(data, tt.lambda)
+		for i, c := range data {
+			if want := math.Sqrt(tt.want[i]); math.Abs(c.Volatility-want) > 1e-12 {
+				t.Errorf("%s: volatility %d %v, want %v", tt.name, i, c.Volatility, want)
+			}
+		}
+	}
+}
+
+func TestComputeLogReturns(t *testing.T) {
+	data := GenerateGBM(NewRand(33, 0), 1000, 100, 0.0002, 0.01)
+	data[0].Returns = 0.5 // stale value the first candle must lose
+	ComputeReturns(data, LogReturns)
+	if data[0].Returns != 0 {
+		t.Errorf("first return %v, want 0", data[0].Returns)
+	}
+	sum := 0.0
+	for _, c := range data {
+		sum += c.Returns
+	}
+	if want := math.Log(data[len(data)-1].Price / data[0].Price); math.Abs(sum-want) > 1e-9 {
+		t.Errorf("log-returns sum to %v, want the log total return %v", sum, want)
+	}
+
+	ComputeReturns(data, SimpleReturns)
+	if got, want := data[1].Returns, data[1].Price/data[0].Price-1; math.Abs(got-want) > 1e-15 {
+		t.Errorf("simple return %v, want %v", got, want)
+	}
+}
+
+func TestParseReturnKind(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		want    ReturnKind
+		wantErr bool
+	}{
+		{"simple", SimpleReturns, false},
+		{"log", LogReturns, false},
+		{"Log", "", true},
+		{"", "", true},
+	} {
+		got, err := ParseReturnKind(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseReturnKind(%q) = %q, %v", tt.name, got, err)
+		}
+	}
+}
+
+// The sample standard deviation of the window returns before each candle,
+// recomputed from scratch as ComputeRollingVolatility did before it slid.
+func naiveRollingVolatility(data []MarketCandle, window int) []float64 {
+	vols := make([]float64, len(data))
+	for i := window; i < len(data); i++ {
+		vols[i] = math.Sqrt(variance(SeriesReturns.Values(data[i-window : i])))
+	}
+	return vols
+}
+
+func TestComputeRollingVolatilityMatchesNaive(t *testing.T) {
+	data := GenerateSeries(NewRand(50, 0), 5000, 100)
+	ComputeReturns(data, SimpleReturns)
+	for _, window := range []int{2, 20, 500, 4999} {
+		ComputeRollingVolatility(data, window)
+		want := naiveRollingVolatility(data, window)
+		for i, c := range data {
+			if math.Abs(c.Volatility-want[i]) > 1e-9 {
+				t.Errorf("window %d: volatility %d %v, want %v", window, i, c.Volatility, want[i])
+				break
+			}
+		}
+	}
+
+	// Windows past the first return are flat and round to zero, not NaN
+	flat := candlesWithReturns(0, 0.01, 0.01, 0.01, 0.01, 0.01)
+	ComputeRollingVolatility(flat, 3)
+	for i := 4; i < len(flat); i++ {
+		if v := flat[i].Volatility; !(math.Abs(v) < 1e-12) {
+			t.Errorf("flat: volatility %d %v, want 0", i, v)
+		}
+	}
+}
+
+func TestWeightedVolatility(t *testing.T) {
+	tests := []struct {
+		name     string
+		returns  []float64
+		window   int
+		halfLife float64
+		want     []float64 // variances
+	}{
+		{
+			// Weights 1/4, 1/2, 1 normalize to 1/7, 2/7, 4/7 with Σw² 3/7; the
+			// weighted mean of 0, 3, 6 is 30/7 and Σw(r-m)² 1638/343
+			name:     "by hand",
+			returns:  []float64{0, 3, 6, 0},
+			window:   3,
+			halfLife: 1,
+			want:     []float64{0, 0, 0, 1638.0 / 343 / (4.0 / 7)},
+		},
+		{
+			// Any weighting of two points gives the sample variance
+			name:     "two points",
+			returns:  []float64{1, 3, 9},
+			window:   2,
+			halfLife: 0.5,
+			want:     []float64{0, 0, 2},
+		},
+		{
+			name:     "flat, default half-life",
+			returns:  []float64{1, 1, 1},
+			window:   2,
+			halfLife: 0,
+			want:     []float64{0, 0, 0},
+		},
+		{name: "window of one", returns: []float64{1, 2, 3}, window: 1, halfLife: 5, want: []float64{0, 0, 0}},
+		{name: "shorter than the window", returns: []float64{1, 2}, window: 2, halfLife: 5, want: []float64{0, 0}},
+	}
+	for _, tt := range tests {
+		got := WeightedVolatility(tt.returns, tt.window, tt.halfLife)
+		for i, v := range got {
+			if want := math.Sqrt(tt.want[i]); math.Abs(v-want) > 1e-12 {
+				t.Errorf("%s: volatility %d %v, want %v", tt.name, i, v, want)
+			}
+		}
+	}
+
+	// Equal weights, as an unbounded half-life gives, are the rolling sample
+	// standard deviation
+	data := GenerateSeries(NewRand(90, 0), 500, 100)
+	ComputeReturns(data, SimpleReturns)
+	want := naiveRollingVolatility(data, 20)
+	ComputeWeightedVolatility(data, 20, 1e12)
+	for i, c := range data {
+		if math.Abs(c.Volatility-want[i]) > 1e-9 {
+			t.Errorf("long half-life: volatility %d %v, want %v", i, c.Volatility, want[i])
+			break
+		}
+	}
+}
+
+func BenchmarkRollingVolatility(b *testing.B) {
+	data := GenerateSeries(NewRand(50, 0), 50000, 100)
+	ComputeReturns(data, SimpleReturns)
+	for _, window := range []int{20, 500} {
+		b.Run(fmt.Sprintf("sliding/window=%d", window), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ComputeRollingVolatility(data, window)
+			}
+		})
+		b.Run(fmt.Sprintf("naive/window=%d", window), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				naiveRollingVolatility(data, window)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package fractal
+
+import (
+	"math"
+	"sort"
+)
+
+// HourlyPeriodsPerYear annualizes hourly candles.
+const HourlyPeriodsPerYear = 24 * 365
+
+// AnnualizeVol scales a per-period volatility to an annual one by the
+// square root of time, assuming independent periods.
+func AnnualizeVol(v, periodsPerYear float64) float64 {
+	return v * math.Sqrt(periodsPerYear)
+}
+
+// MaxDrawdown returns the largest peak-to-trough decline in prices as a
+// negative fraction of the peak, and its duration as the number of candles
+// from that peak to the trough. A series that never falls reports 0, 0.
+func MaxDrawdown(prices []float64) (drawdown float64, duration int) {
+	if len(prices) == 0 {
+		return 0, 0
+	}
+
+	peak, peakIdx := prices[0], 0
+	for i, p := range prices {
+		if p > peak {
+			peak, peakIdx = p, i
+			continue
+		}
+		if peak <= 0 {
+			continue
+		}
+		if dd := (p - peak) / peak; dd < drawdown {
+			drawdown = dd
+			duration = i - peakIdx
+		}
+	}
+	return drawdown, duration
+}
+
+// SharpeRatio returns the annualized Sharpe ratio of per-period returns.
+// riskFree is an annual rate, spread evenly over periodsPerYear. Returns 0
+// when there are fewer than two returns or they have no variance.
+func SharpeRatio(returns []float64, riskFree, periodsPerYear float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	excess := mean(returns) - riskFree/periodsPerYear
+	std := math.Sqrt(variance(returns))
+	if std < 1e-15 {
+		return 0
+	}
+	return excess / std * math.Sqrt(periodsPerYear)
+}
+
+// SortinoRatio is SharpeRatio with the denominator replaced by the downside
+// deviation, the root mean square of excess returns below zero. Returns 0
+// for fewer than two returns or when none falls below the risk-free rate.
+func SortinoRatio(returns []float64, riskFree, periodsPerYear float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	rf := riskFree / periodsPerYear
+	downside := 0.0
+	for _, r := range returns {
+		if excess := r - rf; excess < 0 {
+			downside += excess * excess
+		}
+	}
+	dd := math.Sqrt(downside / float64(len(returns)))
+	if dd < 1e-15 {
+		return 0
+	}
+	return (mean(returns) - rf) / dd * math.Sqrt(periodsPerYear)
+}
+
+// HistoricalVaR returns the historical Value-at-Risk of per-period returns
+// at confidence (e.g. 0.95): the loss, as a positive fraction, exceeded
+// with probability 1-confidence, read off the empirical return
+// distribution with linear interpolation between order statistics. The
+// tail needs at least one whole observation, so fewer than 1/(1-confidence)
+// returns, or a confidence outside (0, 1), yields NaN.
+func HistoricalVaR(returns []float64, confidence float64) float64 {
+	sorted, ok := tailSample(returns, confidence)
+	if !ok {
+		return math.NaN()
+	}
+	return -Percentile(sorted, 100*(1-confidence))
+}
+
+// ExpectedShortfall returns the mean loss over the returns at or beyond
+// HistoricalVaR at the same confidence, as a positive fraction, with the
+// same NaN cases.
+func ExpectedShortfall(returns []float64, confidence float64) float64 {
+	sorted, ok := tailSample(returns, confidence)
+	if !ok {
+		return math.NaN()
+	}
+
+	cutoff := Percentile(sorted, 100*(1-confidence))
+	sum, count := 0.0, 0
+	for _, r := range sorted {
+		if r > cutoff {
+			break
+		}
+		sum += r
+		count++
+	}
+	return -sum / float64(count)
+}
+
+// Sorted copy of returns if there are enough for a 1-confidence tail.
+func tailSample(returns []float64, confidence float64) ([]float64, bool) {
+	if confidence <= 0 || confidence >= 1 ||
+		float64(len(returns))*(1-confidence) < 1-1e-9 {
+		return nil, false
+	}
+	sorted := append([]float64(nil), returns...)
+	sort.Float64s(sorted)
+	return sorted, true
+}
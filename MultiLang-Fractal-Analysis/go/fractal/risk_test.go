@@ -0,0 +1,155 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestAnnualizeVol(t *testing.T) {
+	tests := []struct {
+		v, periods, want float64
+	}{
+		{0.01, HourlyPeriodsPerYear, 0.01 * math.Sqrt(8760)},
+		{0.02, 252, 0.02 * math.Sqrt(252)},
+		{0.05, 4, 0.1},
+		{0.3, 1, 0.3},
+		{0, 252, 0},
+	}
+	for _, tt := range tests {
+		if got := AnnualizeVol(tt.v, tt.periods); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("AnnualizeVol(%v, %v) = %v, want %v", tt.v, tt.periods, got, tt.want)
+		}
+	}
+	// Quadrupling the periods doubles the annual volatility
+	if r := AnnualizeVol(0.01, 4*252) / AnnualizeVol(0.01, 252); math.Abs(r-2) > 1e-12 {
+		t.Errorf("4x periods scales by %v, want 2", r)
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		prices   []float64
+		drawdown float64
+		duration int
+	}{
+		{"empty", nil, 0, 0},
+		{"single point", []float64{100}, 0, 0},
+		{"monotone rising", []float64{100, 101, 105, 105, 120}, 0, 0},
+		// 120 falls to 90 two candles later and recovers; the later
+		// 130 -> 117 dip is shallower
+		{"peak, trough, recovery", []float64{100, 120, 110, 90, 100, 130, 117}, -0.25, 2},
+		{"falls to the end", []float64{50, 40, 30, 20}, -0.6, 3},
+	}
+	for _, tt := range tests {
+		dd, duration := MaxDrawdown(tt.prices)
+		if math.Abs(dd-tt.drawdown) > 1e-12 || duration != tt.duration {
+			t.Errorf("%s: drawdown %v over %d candles, want %v over %d", tt.name, dd, duration, tt.drawdown, tt.duration)
+		}
+	}
+}
+
+// Four returns with mean 0.5%, sample variance 0.0017/3 and squared losses
+// summing to 0.0005; a 25.2% annual risk-free rate is 0.1% a period.
+func TestSharpeAndSortino(t *testing.T) {
+	returns := []float64{0.02, -0.01, 0.03, -0.02}
+	tests := []struct {
+		name            string
+		riskFree        float64
+		sharpe, sortino float64
+	}{
+		{"no risk-free rate", 0, 0.005 / math.Sqrt(0.0017/3) * math.Sqrt(252), 0.005 / math.Sqrt(0.0005/4) * math.Sqrt(252)},
+		// Excess losses of 1.1% and 2.1%
+		{"risk-free rate", 0.252, 0.004 / math.Sqrt(0.0017/3) * math.Sqrt(252), 0.004 / math.Sqrt(0.000562/4) * math.Sqrt(252)},
+	}
+	for _, tt := range tests {
+		sharpe, sortino := SharpeRatio(returns, tt.riskFree, 252), SortinoRatio(returns, tt.riskFree, 252)
+		if math.Abs(sharpe-tt.sharpe) > 1e-9 || math.Abs(sortino-tt.sortino) > 1e-9 {
+			t.Errorf("%s: Sharpe %v, Sortino %v, want %v, %v", tt.name, sharpe, sortino, tt.sharpe, tt.sortino)
+		}
+	}
+	// Annualizing scales both by the square root of the periods
+	if r := SharpeRatio(returns, 0, 4*252) / SharpeRatio(returns, 0, 252); math.Abs(r-2) > 1e-12 {
+		t.Errorf("4x periods scales Sharpe by %v, want 2", r)
+	}
+
+	if got := SharpeRatio([]float64{0.01, 0.01, 0.01}, 0, 252); got != 0 {
+		t.Errorf("zero volatility: Sharpe %v, want 0", got)
+	}
+	if got := SortinoRatio([]float64{0.01, 0.02, 0.03}, 0, 252); got != 0 {
+		t.Errorf("no downside: Sortino %v, want 0", got)
+	}
+	if sharpe, sortino := SharpeRatio([]float64{-0.01}, 0, 252), SortinoRatio([]float64{-0.01}, 0, 252); sharpe != 0 || sortino != 0 {
+		t.Errorf("one return: Sharpe %v, Sortino %v, want 0", sharpe, sortino)
+	}
+}
+
+// Twenty returns whose three worst are -5%, -3% and -2%: the 5th
+// percentile sits 0.95 of the way from -5% to -3%, the 10th 0.9 of the way
+// from -3% to -2%.
+func TestHistoricalVaRAndExpectedShortfall(t *testing.T) {
+	returns := []float64{-0.03, -0.05, -0.02}
+	for i := 0; i < 17; i++ {
+		returns = append(returns, 0.001*float64(i))
+	}
+	tests := []struct {
+		confidence, vaR, es float64
+	}{
+		{0.95, 0.031, 0.05},
+		{0.9, 0.021, 0.04},
+	}
+	for _, tt := range tests {
+		vaR, es := HistoricalVaR(returns, tt.confidence), ExpectedShortfall(returns, tt.confidence)
+		if math.Abs(vaR-tt.vaR) > 1e-12 || math.Abs(es-tt.es) > 1e-12 {
+			t.Errorf("confidence %v: VaR %v, ES %v, want %v, %v", tt.confidence, vaR, es, tt.vaR, tt.es)
+		}
+		if !(es >= vaR) {
+			t.Errorf("confidence %v: ES %v below VaR %v", tt.confidence, es, vaR)
+		}
+	}
+
+	for _, tt := range []struct {
+		name       string
+		returns    []float64
+		confidence float64
+	}{
+		{"empty", nil, 0.95},
+		{"19 returns at 95%", returns[:19], 0.95},
+		{"confidence 1", returns, 1},
+		{"confidence 0", returns, 0},
+	} {
+		if vaR, es := HistoricalVaR(tt.returns, tt.confidence), ExpectedShortfall(tt.returns, tt.confidence); !math.IsNaN(vaR) || !math.IsNaN(es) {
+			t.Errorf("%s: VaR %v, ES %v, want NaN", tt.name, vaR, es)
+		}
+	}
+}
+
+func TestWriteVolatilityCSV(t *testing.T) {
+	data := GenerateSeries(NewRand(71, 0), 100, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	warmUp := VolatilityWarmUp(data)
+	path := filepath.Join(t.TempDir(), "rolling_volatility.csv")
+	if err := WriteVolatilityCSV(data, 252, warmUp, path); err != nil {
+		t.Fatal(err)
+	}
+	rows := readCSVFile(t, path)
+	if len(rows) != len(data)+1 {
+		t.Fatalf("%d rows, want a header and %d", len(rows), len(data))
+	}
+	for i, row := range rows[1:] {
+		if i < warmUp {
+			if row[1] != "" || row[2] != "" {
+				t.Errorf("warm-up row %d: %v, want empty volatility", i, row)
+			}
+			continue
+		}
+		raw, _ := strconv.ParseFloat(row[1], 64)
+		annual, _ := strconv.ParseFloat(row[2], 64)
+		if row[1] != fmt.Sprintf("%.6f", data[i].Volatility) || math.Abs(annual-raw*math.Sqrt(252)) > 1e-5 {
+			t.Errorf("row %d: %v, want volatility %v annualized by sqrt(252)", i, row, data[i].Volatility)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package fractal
+
+import (
+	"context"
+	"math"
+)
+
+// RollingFractalDimension slides a window of the given size across prices
+// in increments of step and computes the box-counting dimension of each
+// position concurrently. The last window is truncated at the end of the
+// series rather than dropped, so unless step exceeds window the whole
+// series is covered.
+func RollingFractalDimension(prices []float64, window, step int) []FractalResult {
+	return Analyzer{}.Rolling(prices, window, step)
+}
+
+// Rolling is RollingFractalDimension using a's box counter and worker pool.
+func (a Analyzer) Rolling(prices []float64, window, step int) []FractalResult {
+	results, _ := a.RunRolling(context.Background(), prices, window, step)
+	return results
+}
+
+// RunRolling is Rolling that stops early with ctx.Err() if ctx is cancelled.
+func (a Analyzer) RunRolling(ctx context.Context, prices []float64, window, step int) ([]FractalResult, error) {
+	if window <= 0 || step <= 0 || len(prices) == 0 {
+		return nil, nil
+	}
+
+	return a.run(ctx, rollingWindows(len(prices), window, step), func(w Window) (FractalResult, bool, error) {
+		window := Detrend(prices[w.Start:w.Start+w.Size], a.Detrend)
+		d, r2, degenerate, err := a.Counter.fitRange(ctx, window, window)
+		if err != nil {
+			return FractalResult{}, false, err
+		}
+		return FractalResult{
+			WindowStart: w.Start,
+			WindowEnd:   w.Start + w.Size - 1,
+			Dimension:   d,
+			R2:          r2,
+			Degenerate:  degenerate,
+		}, true, nil
+	})
+}
+
+// MinRollingHurstWindow is the shortest window RollingHurst estimates on.
+// HurstRS needs a few sub-period sizes from 8 up to half the window for a
+// usable log-log fit, and below 64 returns its estimates swing too much
+// from window to window to track persistence.
+const MinRollingHurstWindow = 64
+
+// RollingHurst slides windows over returns exactly as RollingFractalDimension
+// does over prices, so entry i lines up with the i-th rolling dimension
+// when both see a series of the same length, and returns the rescaled
+// range Hurst exponent of each. Windows shorter than MinRollingHurstWindow,
+// including a truncated last window, get NaN.
+func RollingHurst(returns []float64, window, step int) []float64 {
+	if window <= 0 || step <= 0 || len(returns) == 0 {
+		return nil
+	}
+
+	windows := rollingWindows(len(returns), window, step)
+	hurst := make([]float64, len(windows))
+	for i, w := range windows {
+		if w.Size < MinRollingHurstWindow {
+			hurst[i] = math.NaN()
+			continue
+		}
+		hurst[i] = HurstRS(returns[w.Start : w.Start+w.Size])
+	}
+	return hurst
+}
+
+// DefaultFDVolWindow is how many consecutive rolling windows each rolling
+// dimension-volatility correlation spans.
+const DefaultFDVolWindow = 20
+
+// WindowVolatility returns the mean candle Volatility over each rolling
+// window, pairing the volatility with the dimension measured on the same
+// candles. The first warmUp candles are left out of the means, and a
+// window lying wholly within them gets NaN.
+func WindowVolatility(data []MarketCandle, rolling []FractalResult, warmUp int) []float64 {
+	vols := make([]float64, len(rolling))
+	for i, r := range rolling {
+		from := max(r.WindowStart, warmUp)
+		if from > r.WindowEnd {
+			vols[i] = math.NaN()
+			continue
+		}
+		vols[i] = mean(SeriesVolatility.Values(data[from : r.WindowEnd+1]))
+	}
+	return vols
+}
+
+// RollingCorrelation returns the Pearson correlation of x and y over the
+// trailing window points ending at each index, NaN until window points are
+// available or where either has no spread, as with an all-zero volatility.
+func RollingCorrelation(x, y []float64, window int) []float64 {
+	corr := make([]float64, len(x))
+	for i := range corr {
+		corr[i] = math.NaN()
+		if window >= 2 && i+1 >= window {
+			corr[i] = Pearson(x[i+1-window:i+1], y[i+1-window:i+1])
+		}
+	}
+	return corr
+}
+
+// Windows of the given size every step candles over n, the last truncated
+// at the end of the series. With step no larger than window the series is
+// covered; a wider step leaves gaps and can stop short of the end.
+func rollingWindows(n, window, step int) []Window {
+	var windows []Window
+	for start := 0; start < n; start += step {
+		size := min(window, n-start)
+		windows = append(windows, Window{start, size})
+		if start+size >= n {
+			break
+		}
+	}
+	return windows
+}
@@ -0,0 +1,138 @@
+package fractal
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestRollingWindows(t *testing.T) {
+	tests := []struct {
+		name            string
+		n, window, step int
+		want            []Window
+	}{
+		{"step equals window", 100, 25, 25, []Window{{0, 25}, {25, 25}, {50, 25}, {75, 25}}},
+		{"n not a multiple of step", 105, 50, 25, []Window{{0, 50}, {25, 50}, {50, 50}, {75, 30}}},
+		{"step beyond window", 65, 10, 30, []Window{{0, 10}, {30, 10}, {60, 5}}},
+		{"step beyond window stops short", 75, 10, 30, []Window{{0, 10}, {30, 10}, {60, 10}}},
+		{"window longer than series", 7, 10, 3, []Window{{0, 7}}},
+		{"empty series", 0, 10, 5, nil},
+	}
+	for _, tt := range tests {
+		got := rollingWindows(tt.n, tt.window, tt.step)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: windows %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// A step wider than the window used to end on a window starting past the
+// series, with a negative size.
+func TestRollingStepBeyondWindow(t *testing.T) {
+	prices := randomWalk(1, 1005)
+	for _, w := range rollingWindows(len(prices), 10, 30) {
+		if w.Size <= 0 || w.Start+w.Size > len(prices) {
+			t.Fatalf("window %+v outside %d candles", w, len(prices))
+		}
+	}
+	rolling := RollingFractalDimension(prices, 10, 30)
+	if len(rolling) != 34 {
+		t.Errorf("got %d rolling windows, want 34", len(rolling))
+	}
+	if hurst := RollingHurst(prices, 10, 30); len(hurst) != len(rolling) {
+		t.Errorf("got %d rolling Hurst windows, want %d", len(hurst), len(rolling))
+	}
+}
+
+func TestWindowVolatilityWarmUp(t *testing.T) {
+	data := make([]MarketCandle, 40)
+	for i := range data {
+		data[i].Volatility = float64(i)
+	}
+	rolling := []FractalResult{
+		{WindowStart: 0, WindowEnd: 9},   // wholly inside the warm-up
+		{WindowStart: 10, WindowEnd: 19}, // the warm-up ends part way
+		{WindowStart: 30, WindowEnd: 39}, // past it
+	}
+	vols := WindowVolatility(data, rolling, 15)
+	if !math.IsNaN(vols[0]) {
+		t.Errorf("window inside the warm-up: got %v, want NaN", vols[0])
+	}
+	if want := mean([]float64{15, 16, 17, 18, 19}); vols[1] != want {
+		t.Errorf("window straddling the warm-up: got %v, want the tail mean %v", vols[1], want)
+	}
+	if want := 34.5; vols[2] != want {
+		t.Errorf("window after the warm-up: got %v, want %v", vols[2], want)
+	}
+}
+
+// Persistent returns followed by independent ones: every window of the
+// trending regime scores H well above 0.5, and the random regime clearly
+// less on average.
+func TestRollingHurstTrendingRegime(t *testing.T) {
+	returns := append(fgn(59, 4096, 0.8), whiteNoise(59, 4096)...)
+	hurst := RollingHurst(returns, 512, 512)
+	if len(hurst) != 16 {
+		t.Fatalf("got %d windows, want 16", len(hurst))
+	}
+	for i, h := range hurst[:8] {
+		if !(h > 0.6) {
+			t.Errorf("trending window %d: H %v, want above 0.5", i, h)
+		}
+	}
+	if trending, random := mean(hurst[:8]), mean(hurst[8:]); trending-random < 0.15 {
+		t.Errorf("mean H %v trending, %v random, want the trending regime clearly higher", trending, random)
+	}
+
+	for i, h := range RollingHurst(returns[:200], MinRollingHurstWindow-1, 50) {
+		if !math.IsNaN(h) {
+			t.Errorf("window %d under the minimum: H %v, want NaN", i, h)
+		}
+	}
+}
+
+// Slow, smooth swings alternate with a wide candle-to-candle zigzag, so
+// the rolling dimension and volatility rise and fall together; flat
+// volatility leaves the correlation undefined.
+func TestFDVolCorrelation(t *testing.T) {
+	rng := NewRand(79, 0)
+	data := make([]MarketCandle, 4000)
+	for i := range data {
+		if i/500%2 == 0 {
+			data[i].Price = 100 * (1 + 0.005*math.Sin(2*math.Pi*float64(i)/100))
+		} else {
+			data[i].Price = 100 * (1 + 0.01*float64(i%2))
+		}
+		data[i].Price *= 1 + 0.0001*rng.NormFloat64()
+	}
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+
+	// The zigzag only shows below the default load of five points a box
+	a := Analyzer{Counter: BoxCounter{MinPointsPerBox: 2}}
+	rolling := a.Rolling(SeriesPrice.Values(data), 250, 250)
+	dims := make([]float64, len(rolling))
+	for i, r := range rolling {
+		dims[i] = r.Dimension
+	}
+	vols := WindowVolatility(data, rolling, VolatilityWarmUp(data))
+	if corr := Pearson(dims, vols); !(corr > 0.8) {
+		t.Errorf("overall correlation %v, want above 0.8: dimensions %v, volatilities %v", corr, dims, vols)
+	}
+	rollingCorr := RollingCorrelation(dims, vols, 4)
+	for i, c := range rollingCorr {
+		if i < 3 && !math.IsNaN(c) || i >= 3 && !(c > 0) {
+			t.Errorf("rolling correlation %d %v, want NaN before 4 windows and positive after", i, c)
+		}
+	}
+
+	flat := make([]float64, len(dims))
+	if corr := Pearson(dims, flat); !math.IsNaN(corr) {
+		t.Errorf("all-zero volatility: correlation %v, want NaN", corr)
+	}
+	for i, c := range RollingCorrelation(dims, flat, 4) {
+		if !math.IsNaN(c) {
+			t.Errorf("all-zero volatility: rolling correlation %d %v, want NaN", i, c)
+		}
+	}
+}
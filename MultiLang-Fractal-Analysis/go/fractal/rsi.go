@@ -0,0 +1,99 @@
+package fractal
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultRSIPeriod is Wilder's original RSI period.
+const DefaultRSIPeriod = 14
+
+// RSI returns Wilder's relative strength index of prices. The first
+// average gain and loss are the means of the first period changes; after
+// that each is smoothed as avg = (avg·(period-1) + change) / period, as in
+// AverageTrueRange. The first period prices have no full average and get
+// NaN, as does everything if period < 1. A window without losses scores
+// 100, and one with no movement at all 50.
+func RSI(prices []float64, period int) []float64 {
+	rsi := make([]float64, len(prices))
+	for i := range rsi {
+		rsi[i] = math.NaN()
+	}
+	if period < 1 || len(prices) <= period {
+		return rsi
+	}
+
+	gain, loss := 0.0, 0.0
+	for i := 1; i <= period; i++ {
+		if d := prices[i] - prices[i-1]; d > 0 {
+			gain += d
+		} else {
+			loss -= d
+		}
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+	rsi[period] = relativeStrength(gain, loss)
+
+	for i := period + 1; i < len(prices); i++ {
+		up, down := 0.0, 0.0
+		if d := prices[i] - prices[i-1]; d > 0 {
+			up = d
+		} else {
+			down = -d
+		}
+		gain = (gain*float64(period-1) + up) / float64(period)
+		loss = (loss*float64(period-1) + down) / float64(period)
+		rsi[i] = relativeStrength(gain, loss)
+	}
+	return rsi
+}
+
+func relativeStrength(gain, loss float64) float64 {
+	if loss == 0 {
+		if gain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// Divergence is a pivot where price and RSI disagree with the previous
+// pivot of the same kind: a bullish divergence makes a lower low with a
+// higher RSI, a bearish one a higher high with a lower RSI.
+type Divergence struct {
+	Index    int // pivot candle
+	Previous int // earlier pivot it is compared with
+	Bullish  bool
+}
+
+// RSIDivergences compares each Williams fractal pivot with the previous
+// pivot of the same kind, using the pivot low for bullish fractals and
+// the pivot high for bearish ones, and returns the divergences in index
+// order. Pivots whose RSI is still undefined are skipped.
+func RSIDivergences(data []MarketCandle, rsi []float64, bullish, bearish []int) []Divergence {
+	var out []Divergence
+	scan := func(pivots []int, isBullish bool) {
+		prev := -1
+		for _, i := range pivots {
+			if math.IsNaN(rsi[i]) {
+				continue
+			}
+			if prev >= 0 {
+				if isBullish && data[i].Low < data[prev].Low && rsi[i] > rsi[prev] {
+					out = append(out, Divergence{i, prev, true})
+				}
+				if !isBullish && data[i].High > data[prev].High && rsi[i] < rsi[prev] {
+					out = append(out, Divergence{i, prev, false})
+				}
+			}
+			prev = i
+		}
+	}
+	scan(bullish, true)
+	scan(bearish, false)
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Index < out[j].Index })
+	return out
+}
@@ -0,0 +1,72 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRSI(t *testing.T) {
+	up := make([]float64, 50)
+	down := make([]float64, 50)
+	for i := range up {
+		up[i] = 100 + float64(i)
+		down[i] = 100 - float64(i)
+	}
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		prices []float64
+		period int
+		want   []float64 // checked from the start; nil skips
+		last   float64
+	}{
+		{"monotone up", up, DefaultRSIPeriod, nil, 100},
+		{"monotone down", down, DefaultRSIPeriod, nil, 0},
+		{"flat", make([]float64, 30), DefaultRSIPeriod, nil, 50},
+		// gain 0.5, loss 0.25; then gain 0.75, loss 0.125
+		{"by hand", []float64{1, 2, 1.5, 2.5}, 2, []float64{nan, nan, 100 - 100/3.0, 100 - 100/7.0}, 100 - 100/7.0},
+	}
+	for _, tt := range tests {
+		rsi := RSI(tt.prices, tt.period)
+		for i := 0; i < tt.period; i++ {
+			if !math.IsNaN(rsi[i]) {
+				t.Errorf("%s: RSI %d %v before a full period, want NaN", tt.name, i, rsi[i])
+			}
+		}
+		for i, want := range tt.want {
+			if !math.IsNaN(want) && math.Abs(rsi[i]-want) > 1e-9 {
+				t.Errorf("%s: RSI %d %v, want %v", tt.name, i, rsi[i], want)
+			}
+		}
+		if got := rsi[len(rsi)-1]; math.Abs(got-tt.last) > 1e-9 {
+			t.Errorf("%s: last RSI %v, want %v", tt.name, got, tt.last)
+		}
+	}
+
+	for i, v := range RSI(up[:DefaultRSIPeriod], DefaultRSIPeriod) {
+		if !math.IsNaN(v) {
+			t.Errorf("too short: RSI %d %v, want NaN", i, v)
+		}
+	}
+}
+
+// A lower low on a higher RSI is bullish, a higher high on a lower RSI
+// bearish; pivots without RSI are skipped.
+func TestRSIDivergences(t *testing.T) {
+	data := make([]MarketCandle, 8)
+	data[1].Low, data[4].Low = 10, 9 // lower low
+	data[2].High, data[6].High = 20, 21
+	data[5].Low = 8
+	nan := math.NaN()
+	rsi := []float64{nan, 30, 60, nan, 35, nan, 55, 40}
+	got := RSIDivergences(data, rsi, []int{1, 4, 5}, []int{2, 6})
+	want := []Divergence{{4, 1, true}, {6, 2, false}}
+	if len(got) != len(want) {
+		t.Fatalf("divergences %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("divergence %d %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
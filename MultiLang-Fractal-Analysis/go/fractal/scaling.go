@@ -0,0 +1,46 @@
+package fractal
+
+import "context"
+
+// DefaultScalingPoints is how many window sizes the scaling sweep uses.
+const DefaultScalingPoints = 12
+
+// ScalingWindowSizes returns a geometric sweep of up to points window
+// sizes from min to max, as LogSpacedBoxSizes does for box sizes.
+func ScalingWindowSizes(min, max, points int) []int {
+	return LogSpacedBoxSizes(min, max, points)
+}
+
+// RunScaling computes the box-counting dimension of the trailing window
+// of values for each size, giving the dimension as a function of window
+// length. A self-affine series gives a roughly flat curve. Sizes outside
+// [1, len(values)] are skipped; results are in increasing size.
+func (a Analyzer) RunScaling(ctx context.Context, values []float64, sizes []int) ([]FractalResult, error) {
+	var windows []Window
+	for _, size := range sizes {
+		if size >= 1 && size <= len(values) {
+			windows = append(windows, Window{len(values) - size, size})
+		}
+	}
+
+	// Trailing windows share their end, so the WindowStart ordering of run
+	// puts the largest first
+	results, err := a.run(ctx, windows, func(w Window) (FractalResult, bool, error) {
+		window := Detrend(values[w.Start:w.Start+w.Size], a.Detrend)
+		d, r2, degenerate, err := a.Counter.fitRange(ctx, window, window)
+		if err != nil {
+			return FractalResult{}, false, err
+		}
+		return FractalResult{
+			WindowStart: w.Start,
+			WindowEnd:   w.Start + w.Size - 1,
+			Dimension:   d,
+			R2:          r2,
+			Degenerate:  degenerate,
+		}, true, nil
+	})
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results, err
+}
@@ -0,0 +1,40 @@
+package fractal
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRunScalingWindowSizes(t *testing.T) {
+	prices := randomWalk(62, 5000)
+	tests := []struct{ min, max, points int }{
+		{64, 5000, DefaultScalingPoints},
+		{100, 800, 6},
+		{64, 64000, 20}, // sizes past the series are skipped
+	}
+	for _, tt := range tests {
+		sizes := ScalingWindowSizes(tt.min, tt.max, tt.points)
+		if len(sizes) == 0 || sizes[0] != tt.min || sizes[len(sizes)-1] != tt.max {
+			t.Errorf("%d..%d: sizes %v, want from min to max", tt.min, tt.max, sizes)
+		}
+
+		results, err := Analyzer{}.RunScaling(context.Background(), prices, sizes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prev := 0
+		for _, r := range results {
+			size := r.WindowEnd - r.WindowStart + 1
+			if size <= prev || size > len(prices) {
+				t.Errorf("%d..%d: window size %d after %d, want increasing within %d", tt.min, tt.max, size, prev, len(prices))
+			}
+			if r.WindowEnd != len(prices)-1 {
+				t.Errorf("%d..%d: window ends at %d, want the series end", tt.min, tt.max, r.WindowEnd)
+			}
+			prev = size
+		}
+		if tt.max <= len(prices) && len(results) != len(sizes) {
+			t.Errorf("%d..%d: %d results for %d sizes", tt.min, tt.max, len(results), len(sizes))
+		}
+	}
+}
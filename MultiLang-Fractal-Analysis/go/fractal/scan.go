@@ -0,0 +1,79 @@
+package fractal
+
+import (
+	"context"
+	"math"
+)
+
+// ScanResult holds the headline metrics of one input of a scan. Err is
+// set, with the metrics NaN, when the input could not be loaded.
+type ScanResult struct {
+	Input       string
+	Points      int
+	TotalReturn float64
+	MaxDrawdown float64
+	Dimension   float64 // of the full series, as Run gives its first window
+	R2          float64
+	Hurst       float64
+	Err         error
+}
+
+// Scan loads each input with load and measures its total return, maximum
+// drawdown and full-series dimension and Hurst exponent with a's settings.
+// Inputs are spread over the worker pool with each on a single worker, so
+// many files are triaged without writing their per-file outputs. An input
+// that fails to load gets its error in the result rather than stopping
+// the scan. Results are in input order; ctx cancellation returns ctx.Err()
+// with no results.
+func (a Analyzer) Scan(ctx context.Context, inputs []string, load func(string) ([]MarketCandle, error)) ([]ScanResult, error) {
+	inner := a
+	inner.Workers = 1
+	jobs := make([]int, len(inputs))
+	for i := range jobs {
+		jobs[i] = i
+	}
+
+	type indexed struct {
+		i int
+		r ScanResult
+	}
+	out, err := pool(ctx, a.Workers, jobs, func(i int) (indexed, bool, error) {
+		r := ScanResult{
+			Input:       inputs[i],
+			TotalReturn: math.NaN(),
+			MaxDrawdown: math.NaN(),
+			Dimension:   math.NaN(),
+			R2:          math.NaN(),
+			Hurst:       math.NaN(),
+		}
+		data, err := load(inputs[i])
+		if err != nil {
+			r.Err = err
+			return indexed{i, r}, true, nil
+		}
+
+		r.Points = len(data)
+		if len(data) > 0 {
+			first, last := data[0].Price, data[len(data)-1].Price
+			r.TotalReturn = (last - first) / first
+			r.MaxDrawdown, _ = MaxDrawdown(SeriesPrice.Values(data))
+		}
+		results, err := inner.Run(ctx, data, []Window{{Start: 0, Size: len(data)}})
+		if err != nil {
+			return indexed{}, false, err
+		}
+		if len(results) > 0 {
+			r.Dimension, r.R2, r.Hurst = results[0].Dimension, results[0].R2, results[0].Hurst
+		}
+		return indexed{i, r}, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ScanResult, len(inputs))
+	for _, o := range out {
+		results[o.i] = o.r
+	}
+	return results, nil
+}
@@ -0,0 +1,72 @@
+package fractal
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Header names ReadMarketCSV recognises for each MarketCandle field,
+// compared after normaliseColumn. Exchanges and data vendors mostly differ
+// in case, separators and abbreviations.
+var columnAliases = map[string][]string{
+	"Timestamp":  {"timestamp", "time", "datetime", "date", "ts", "opentime", "timestamputc"},
+	"Price":      {"price", "last", "lastprice", "adjclose"},
+	"Volume":     {"volume", "vol", "qty", "quantity", "basevolume", "size", "amount"},
+	"Returns":    {"returns", "return", "ret"},
+	"Volatility": {"volatility", "sigma"},
+	"Open":       {"open", "openprice"},
+	"High":       {"high", "hi", "highprice"},
+	"Low":        {"low", "lo", "lowprice"},
+	"Close":      {"close", "closeprice", "closingprice"},
+}
+
+// Lower-cases a header name and drops spaces, underscores and other
+// punctuation, so "Close Price", "close_price" and "ClosePrice" agree.
+func normaliseColumn(name string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// Maps each recognised header column to its MarketCandle field name,
+// ignoring unknown columns. When several columns alias the same field the
+// first wins. Price and Close stand in for each other, since a close-only
+// file and a Price-only file describe the same series.
+func mapColumns(header []string) map[string]int {
+	field := map[string]string{}
+	for name, aliases := range columnAliases {
+		for _, alias := range aliases {
+			field[alias] = name
+		}
+	}
+
+	cols := map[string]int{}
+	for i, name := range header {
+		if f, ok := field[normaliseColumn(name)]; ok {
+			if _, seen := cols[f]; !seen {
+				cols[f] = i
+			}
+		}
+	}
+
+	if _, ok := cols["Price"]; !ok {
+		if i, ok := cols["Close"]; ok {
+			cols["Price"] = i
+		}
+	}
+	if _, ok := cols["Close"]; !ok {
+		if i, ok := cols["Price"]; ok {
+			cols["Close"] = i
+		}
+	}
+	return cols
+}
+
+// Header names accepted for field, for error messages.
+func aliasList(field string) string {
+	return strings.Join(columnAliases[field], ", ")
+}
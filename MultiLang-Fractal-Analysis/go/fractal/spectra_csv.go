@@ -0,0 +1,123 @@
+package fractal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+)
+
+// WriteHolderCSV writes each WTMM Hölder exponent at the candle its maxima
+// line converges to.
+func WriteHolderCSV(data []MarketCandle, exponents []HolderExponent, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Index", "Timestamp", "Holder", "R2", "Scales"})
+
+	for _, e := range exponents {
+		writer.Write([]string{
+			strconv.Itoa(e.Index),
+			data[e.Index].Timestamp.Format(timestampLayout),
+			fmt.Sprintf("%.6f", e.Exponent),
+			fmt.Sprintf("%.6f", e.R2),
+			strconv.Itoa(e.Scales),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteMFDFACSV writes the generalized Hurst spectrum in increasing q.
+func WriteMFDFACSV(hq map[float64]float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Q", "H"})
+
+	for _, q := range sortedQs(hq) {
+		writer.Write([]string{
+			strconv.FormatFloat(q, 'g', -1, 64),
+			fmt.Sprintf("%.6f", hq[q]),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteRenyiCSV writes generalized dimensions as Q,D rows in order of q.
+func WriteRenyiCSV(dq map[float64]float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Q", "D"})
+
+	for _, q := range sortedQs(dq) {
+		writer.Write([]string{
+			strconv.FormatFloat(q, 'g', -1, 64),
+			fmt.Sprintf("%.6f", dq[q]),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteACFCSV writes autocorrelations indexed by lag from 0.
+func WriteACFCSV(acf []float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Lag", "ACF"})
+
+	for lag, v := range acf {
+		writer.Write([]string{strconv.Itoa(lag), fmt.Sprintf("%.6f", v)})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WritePSDCSV writes a power spectrum as Frequency,Power rows.
+func WritePSDCSV(freqs, power []float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Frequency", "Power"})
+
+	for i := range freqs {
+		writer.Write([]string{
+			strconv.FormatFloat(freqs[i], 'g', 8, 64),
+			strconv.FormatFloat(power[i], 'g', 8, 64),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
@@ -0,0 +1,127 @@
+package fractal
+
+import (
+	"math"
+	"math/cmplx"
+	"slices"
+)
+
+// PowerSpectrum returns the periodogram of series at the positive
+// frequencies k/m cycles per sample, k = 1..m/2, where m is len(series)
+// rounded up to a power of two for the FFT. The line through the first
+// and last points is removed first, which keeps the jump between the ends
+// from leaking into every frequency without stripping the low-frequency
+// power a least-squares detrend would, then the series is Hann-tapered and
+// zero-padded to m. NaN values, such as FillGaps placeholders, are
+// interpolated linearly from their neighbours so the grid stays uniform.
+// Fewer than four points, or no finite ones, yield nil slices.
+func PowerSpectrum(series []float64) (freqs, power []float64) {
+	n := len(series)
+	if n < 4 {
+		return nil, nil
+	}
+	series, ok := interpolateNaN(series)
+	if !ok {
+		return nil, nil
+	}
+
+	// End-matching: subtract the line through the first and last points
+	slope := (series[n-1] - series[0]) / float64(n-1)
+
+	// A Hann taper keeps leakage from the window edges from flattening
+	// steep spectra; power is rescaled by the taper's mean square
+	m := nextPow2(n)
+	a := make([]complex128, m)
+	taperSq := 0.0
+	for i, v := range series {
+		w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		taperSq += w * w
+		a[i] = complex(w*(v-series[0]-slope*float64(i)), 0)
+	}
+	fft(a, false)
+
+	freqs = make([]float64, m/2)
+	power = make([]float64, m/2)
+	for k := 1; k <= m/2; k++ {
+		freqs[k-1] = float64(k) / float64(m)
+		mag := cmplx.Abs(a[k])
+		power[k-1] = mag * mag / taperSq
+	}
+	return freqs, power
+}
+
+// SpectralExponent fits S(f) ∝ f^(-β) to the power spectrum of series and
+// maps β onto a fractal dimension with D = (5-β)/2, which is 2-H for
+// fractional Brownian motion (β = 2H+1). The periodogram is averaged in
+// logarithmically spaced frequency bins before the fit so the many high
+// frequencies don't swamp the slope. Degenerate inputs yield NaN for both.
+func SpectralExponent(series []float64) (beta, dimension float64) {
+	freqs, power := PowerSpectrum(series)
+	if len(freqs) < 4 {
+		return math.NaN(), math.NaN()
+	}
+
+	const binsPerDecade = 10
+	var logF, logS []float64
+	lo := 0
+	for lo < len(freqs) {
+		edge := freqs[lo] * math.Pow(10, 1.0/binsPerDecade)
+		hi := lo
+		sumF, sumS := 0.0, 0.0
+		for hi < len(freqs) && (hi == lo || freqs[hi] < edge) {
+			sumF += math.Log(freqs[hi])
+			sumS += power[hi]
+			hi++
+		}
+		if sumS > 0 {
+			logF = append(logF, sumF/float64(hi-lo))
+			logS = append(logS, math.Log(sumS/float64(hi-lo)))
+		}
+		lo = hi
+	}
+
+	slope, ok := LinearSlope(logF, logS)
+	if !ok {
+		return math.NaN(), math.NaN()
+	}
+	beta = -slope
+	return beta, (5 - beta) / 2
+}
+
+// Returns series with each run of NaN values replaced by the line between
+// the finite values either side of it, or by the nearest one at the ends.
+// series itself is returned when it has no NaN; ok is false when it has
+// nothing else.
+func interpolateNaN(series []float64) (filled []float64, ok bool) {
+	prev := -1 // index of the last finite value
+	for i, v := range series {
+		if math.IsNaN(v) {
+			continue
+		}
+		if i > prev+1 {
+			if filled == nil {
+				filled = slices.Clone(series)
+			}
+			for j := prev + 1; j < i; j++ {
+				if prev < 0 {
+					filled[j] = v
+				} else {
+					filled[j] = series[prev] + (v-series[prev])*float64(j-prev)/float64(i-prev)
+				}
+			}
+		}
+		prev = i
+	}
+	switch {
+	case prev < 0:
+		return nil, false
+	case filled == nil && prev == len(series)-1:
+		return series, true
+	case filled == nil:
+		filled = slices.Clone(series)
+	}
+	for j := prev + 1; j < len(series); j++ {
+		filled[j] = series[prev]
+	}
+	return filled, true
+}
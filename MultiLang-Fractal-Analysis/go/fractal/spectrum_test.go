@@ -0,0 +1,93 @@
+package fractal
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestInterpolateNaN(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		series []float64
+		want   []float64
+		ok     bool
+	}{
+		{"no NaN", []float64{1, 2, 3}, []float64{1, 2, 3}, true},
+		{"interior run", []float64{1, nan, nan, 4}, []float64{1, 2, 3, 4}, true},
+		{"leading run", []float64{nan, nan, 5, 6}, []float64{5, 5, 5, 6}, true},
+		{"trailing run", []float64{1, 2, nan}, []float64{1, 2, 2}, true},
+		{"both ends", []float64{nan, 3, nan, 5, nan}, []float64{3, 3, 4, 5, 5}, true},
+		{"all NaN", []float64{nan, nan}, nil, false},
+	}
+	for _, tt := range tests {
+		input := slices.Clone(tt.series)
+		got, ok := interpolateNaN(input)
+		if ok != tt.ok || !slices.Equal(got, tt.want) {
+			t.Errorf("%s: %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+		if !slices.EqualFunc(input, tt.series, func(a, b float64) bool { return a == b || math.IsNaN(a) && math.IsNaN(b) }) {
+			t.Errorf("%s: input modified to %v", tt.name, input)
+		}
+	}
+}
+
+// A FillGaps-style series with NaN placeholders has the spectrum of the
+// series interpolated across them, not NaN power.
+func TestPowerSpectrumAcrossGaps(t *testing.T) {
+	walk := randomWalk(6, 1024)
+	gappy := slices.Clone(walk)
+	for i := 300; i < 340; i++ {
+		gappy[i] = math.NaN()
+	}
+	line, _ := interpolateNaN(gappy)
+
+	freqs, power := PowerSpectrum(gappy)
+	wantFreqs, wantPower := PowerSpectrum(line)
+	if len(power) == 0 || !slices.Equal(freqs, wantFreqs) || !slices.Equal(power, wantPower) {
+		t.Fatalf("spectrum across gaps differs from the interpolated series'")
+	}
+	for i, p := range power {
+		if math.IsNaN(p) || math.IsInf(p, 0) {
+			t.Fatalf("power %v at frequency %v", p, freqs[i])
+		}
+	}
+	if beta, d := SpectralExponent(gappy); math.IsNaN(beta) || math.IsNaN(d) {
+		t.Errorf("spectral exponent %v, dimension %v, want finite", beta, d)
+	}
+
+	if freqs, power := PowerSpectrum([]float64{math.NaN(), math.NaN(), math.NaN(), math.NaN()}); freqs != nil || power != nil {
+		t.Errorf("all NaN: %v, %v, want nil", freqs, power)
+	}
+}
+
+// On fBm the spectral exponent tracks β = 2H+1, and so D = 2-H. A single
+// periodogram is noisy, so estimates are averaged over a few paths.
+func TestSpectralExponentFBM(t *testing.T) {
+	const paths = 4
+	prev := math.Inf(-1)
+	for _, hurst := range []float64{0.3, 0.5, 0.7} {
+		beta, d := 0.0, 0.0
+		for s := 0; s < paths; s++ {
+			b, dim := SpectralExponent(GenerateFBM(NewRand(51, s), 4096, hurst))
+			beta += b / paths
+			d += dim / paths
+		}
+		if want := 2*hurst + 1; math.Abs(beta-want) > 0.2 {
+			t.Errorf("H %v: beta %v, want %v", hurst, beta, want)
+		}
+		if math.Abs(d-(2-hurst)) > 0.1 {
+			t.Errorf("H %v: dimension %v, want %v", hurst, d, 2-hurst)
+		}
+		if beta <= prev {
+			t.Errorf("H %v: beta %v not above %v at the lower H", hurst, beta, prev)
+		}
+		prev = beta
+	}
+
+	freqs, power := PowerSpectrum(make([]float64, 1000))
+	if len(freqs) != 512 || freqs[0] != 1.0/1024 || freqs[511] != 0.5 || len(power) != 512 {
+		t.Errorf("1000 points: %d frequencies %v..%v, want 512 from 1/1024 to 0.5", len(freqs), freqs[0], freqs[len(freqs)-1])
+	}
+}
@@ -0,0 +1,46 @@
+package fractal
+
+import (
+	"math"
+	"sort"
+)
+
+// Percentile returns the p-th percentile (0-100) of already sorted values,
+// interpolating linearly between the closest ranks (Hyndman-Fan type 7,
+// the default of most spreadsheets and NumPy). p is clamped to [0, 100];
+// an empty slice yields NaN.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	p = math.Max(0, math.Min(100, p))
+
+	h := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(h))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (h-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+// Distribution summary rows for returns: extremes, percentiles, shape and
+// distance from a fitted normal.
+func returnStats(returns []float64) []Metric {
+	sorted := append([]float64(nil), returns...)
+	sort.Float64s(sorted)
+
+	ks, ksP := NormalKS(returns)
+	return []Metric{
+		{"ReturnMin", Percentile(sorted, 0)},
+		{"ReturnP05", Percentile(sorted, 5)},
+		{"ReturnP25", Percentile(sorted, 25)},
+		{"ReturnMedian", Percentile(sorted, 50)},
+		{"ReturnP75", Percentile(sorted, 75)},
+		{"ReturnP95", Percentile(sorted, 95)},
+		{"ReturnMax", Percentile(sorted, 100)},
+		{"ReturnSkewness", skewness(returns)},
+		{"ReturnExcessKurtosis", kurtosis(returns)},
+		{"ReturnNormalKS", ks},
+		{"ReturnNormalKSPValue", ksP},
+	}
+}
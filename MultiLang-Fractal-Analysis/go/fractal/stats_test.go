@@ -0,0 +1,60 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	uniform := make([]float64, 101) // 0, 0.01, ..., 1
+	for i := range uniform {
+		uniform[i] = float64(i) / 100
+	}
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{"uniform min", uniform, 0, 0},
+		{"uniform P05", uniform, 5, 0.05},
+		{"uniform P25", uniform, 25, 0.25},
+		{"uniform median", uniform, 50, 0.5},
+		{"uniform P95", uniform, 95, 0.95},
+		{"uniform max", uniform, 100, 1},
+		{"between ranks", []float64{1, 2, 3, 4}, 50, 2.5}, // h = 1.5
+		{"quarter rank", []float64{10, 20, 30}, 25, 15},   // h = 0.5
+		{"clamped low", []float64{1, 2, 3}, -10, 1},
+		{"clamped high", []float64{1, 2, 3}, 250, 3},
+		{"single value", []float64{7}, 40, 7},
+	}
+	for _, tt := range tests {
+		if got := Percentile(tt.sorted, tt.p); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: Percentile(%v) = %v, want %v", tt.name, tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile(nil, 50); !math.IsNaN(got) {
+		t.Errorf("empty: %v, want NaN", got)
+	}
+}
+
+// All-zero returns, as before any are computed, summarise without
+// panicking: zero percentiles and undefined shape.
+func TestReturnStatsAllZero(t *testing.T) {
+	for _, n := range []int{0, 1, 50} {
+		stats := returnStats(make([]float64, n))
+		for _, m := range stats {
+			v := m.Value.(float64)
+			switch m.Name {
+			case "ReturnSkewness", "ReturnExcessKurtosis":
+				if !math.IsNaN(v) {
+					t.Errorf("n %d: %s %v, want NaN", n, m.Name, v)
+				}
+			case "ReturnMin", "ReturnP05", "ReturnP25", "ReturnMedian", "ReturnP75", "ReturnP95", "ReturnMax":
+				if n > 0 && v != 0 || n == 0 && !math.IsNaN(v) {
+					t.Errorf("n %d: %s %v", n, m.Name, v)
+				}
+			}
+		}
+	}
+}
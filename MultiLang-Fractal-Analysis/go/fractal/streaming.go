@@ -0,0 +1,75 @@
+package fractal
+
+// StreamingFractal tracks the box-counting dimension of the last window
+// prices of a live feed. Prices are kept in a fixed ring buffer, so memory
+// is bounded by the window, and Push is O(1). The min-max normalization
+// moves with every new extreme, so box counts can't be updated in place;
+// instead the fit is recomputed lazily by Dimension or Fit, at most once
+// per Push however often it is read.
+//
+// A StreamingFractal is not safe for concurrent use.
+type StreamingFractal struct {
+	counter BoxCounter
+	ring    []float64
+	next    int // ring index the next price is written to
+	count   int
+
+	scratch   []float64 // ring unrolled oldest first
+	stale     bool
+	dimension float64
+	r2        float64
+}
+
+// NewStreamingFractal returns a tracker over the last window prices using
+// counter's box sizes. A window below 1 is treated as 1.
+func NewStreamingFractal(window int, counter BoxCounter) *StreamingFractal {
+	if window < 1 {
+		window = 1
+	}
+	return &StreamingFractal{
+		counter:   counter,
+		ring:      make([]float64, window),
+		scratch:   make([]float64, 0, window),
+		dimension: 1.0,
+	}
+}
+
+// Push appends a price, evicting the oldest once the window is full.
+func (s *StreamingFractal) Push(price float64) {
+	s.ring[s.next] = price
+	s.next = (s.next + 1) % len(s.ring)
+	if s.count < len(s.ring) {
+		s.count++
+	}
+	s.stale = true
+}
+
+// Len reports how many prices are buffered, at most the window.
+func (s *StreamingFractal) Len() int { return s.count }
+
+// Full reports whether a whole window has been pushed.
+func (s *StreamingFractal) Full() bool { return s.count == len(s.ring) }
+
+// Dimension returns the box-counting dimension of the buffered prices.
+// Until the window fills it covers what has arrived so far.
+func (s *StreamingFractal) Dimension() float64 {
+	d, _ := s.Fit()
+	return d
+}
+
+// Fit is Dimension that also returns the R² of the log-log regression.
+func (s *StreamingFractal) Fit() (dimension, r2 float64) {
+	if s.stale {
+		s.scratch = s.scratch[:0]
+		start := s.next - s.count
+		if start < 0 {
+			start += len(s.ring)
+		}
+		for i := 0; i < s.count; i++ {
+			s.scratch = append(s.scratch, s.ring[(start+i)%len(s.ring)])
+		}
+		s.dimension, s.r2 = s.counter.Fit(s.scratch)
+		s.stale = false
+	}
+	return s.dimension, s.r2
+}
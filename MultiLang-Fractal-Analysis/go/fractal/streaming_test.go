@@ -0,0 +1,22 @@
+package fractal
+
+import "testing"
+
+// Pushing a series a point at a time tracks the batch dimension of the
+// same trailing window after every push.
+func TestStreamingFractalMatchesBatch(t *testing.T) {
+	walk := randomWalk(31, 800)
+	for _, window := range []int{1, 50, 300} {
+		s := NewStreamingFractal(window, BoxCounter{})
+		for i, p := range walk {
+			s.Push(p)
+			start := max(0, i+1-window)
+			if got, want := s.Dimension(), BoxCountingFractalDimension(walk[start:i+1]); got != want {
+				t.Fatalf("window %d, after %d pushes: dimension %v, batch %v", window, i+1, got, want)
+			}
+			if s.Len() != i+1-start || s.Full() != (i+1 >= window) {
+				t.Fatalf("window %d, after %d pushes: Len %d Full %v", window, i+1, s.Len(), s.Full())
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Metric is one named session statistic. Value holds an int, a float64
+// or a string.
+type Metric struct {
+	Name  string
+	Value any
+}
+
+// String formats the value the way the summary CSV reports it; undefined
+// (NaN) floats are left empty.
+func (m Metric) String() string {
+	switch v := m.Value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		if math.IsNaN(v) {
+			return ""
+		}
+		return fmt.Sprintf("%.6f", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// SummaryConfig holds the parameters of the summary's risk metrics.
+type SummaryConfig struct {
+	PeriodsPerYear float64 // annualization factor, <= 0 uses HourlyPeriodsPerYear
+	RiskFree       float64 // annual risk-free rate
+	// TrimWarmUp leaves the VolatilityWarmUp candles out of the volatility
+	// statistics; the count left out is reported as VolatilityWarmUp.
+	TrimWarmUp bool
+}
+
+// Summarize computes the session-level metrics shared by the CSV and JSON
+// summary outputs, in reporting order, with the default SummaryConfig.
+// Extra metrics describing the run are appended at the end. Metrics an
+// empty or one-candle series cannot define are NaN rather than errors.
+func Summarize(data []MarketCandle, results []FractalResult, extra ...Metric) []Metric {
+	return SummaryConfig{}.Summarize(data, results, extra...)
+}
+
+// Summarize is the package-level Summarize using cfg's parameters.
+func (cfg SummaryConfig) Summarize(data []MarketCandle, results []FractalResult, extra ...Metric) []Metric {
+	periods := cfg.PeriodsPerYear
+	if periods <= 0 {
+		periods = HourlyPeriodsPerYear
+	}
+
+	// An empty series still gets every row, with undefined prices
+	first, last := math.NaN(), math.NaN()
+	if len(data) > 0 {
+		first, last = data[0].Price, data[len(data)-1].Price
+	}
+
+	metrics := []Metric{
+		{"Points", len(data)},
+		{"StartPrice", first},
+		{"EndPrice", last},
+		{"TotalReturn", (last - first) / first},
+	}
+
+	drawdown, duration := MaxDrawdown(SeriesPrice.Values(data))
+	metrics = append(metrics,
+		Metric{"MaxDrawdown", drawdown},
+		Metric{"MaxDrawdownDuration", duration},
+	)
+
+	// The first candle has no return
+	var returns []float64
+	if len(data) > 1 {
+		returns = SeriesReturns.Values(data[1:])
+	}
+	metrics = append(metrics,
+		Metric{"SharpeRatio", SharpeRatio(returns, cfg.RiskFree, periods)},
+		Metric{"SortinoRatio", SortinoRatio(returns, cfg.RiskFree, periods)},
+		Metric{"VaR95", HistoricalVaR(returns, 0.95)},
+		Metric{"ES95", ExpectedShortfall(returns, 0.95)},
+		Metric{"VaR99", HistoricalVaR(returns, 0.99)},
+		Metric{"ES99", ExpectedShortfall(returns, 0.99)},
+	)
+	metrics = append(metrics, returnStats(returns)...)
+
+	warmUp := 0
+	if cfg.TrimWarmUp {
+		warmUp = VolatilityWarmUp(data)
+	}
+	vols := SeriesVolatility.Values(data[warmUp:])
+	sort.Float64s(vols)
+	metrics = append(metrics,
+		Metric{"VolatilityWarmUp", warmUp},
+		Metric{"VolatilityMean", mean(vols)},
+		Metric{"VolatilityMedian", Percentile(vols, 50)},
+		Metric{"VolatilityMax", Percentile(vols, 100)},
+	)
+
+	for i, r := range results {
+		metrics = append(metrics, Metric{fmt.Sprintf("FD_Window_%d", i), r.Dimension})
+	}
+
+	return append(metrics, extra...)
+}
@@ -0,0 +1,138 @@
+package fractal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// WriteSummary writes session-level metrics as Metric,Value rows.
+func WriteSummary(data []MarketCandle, results []FractalResult, filename string, extra ...Metric) error {
+	return WriteMetricsCSV(Summarize(data, results, extra...), filename)
+}
+
+// WriteMetricsCSV writes already computed metrics as Metric,Value rows.
+func WriteMetricsCSV(metrics []Metric, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Metric", "Value"})
+
+	for _, m := range metrics {
+		writer.Write([]string{m.Name, m.String()})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteSeedSweepCSV writes the per-window dimension spread of a seed
+// sweep; a single seed leaves StdDimension empty.
+func WriteSeedSweepCSV(stats []SweepStats, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write([]string{"Window", "WindowStart", "WindowEnd", "Seeds", "MeanDimension", "StdDimension", "MinDimension", "MaxDimension"})
+
+	for _, s := range stats {
+		std := ""
+		if !math.IsNaN(s.Std) {
+			std = fmt.Sprintf("%.6f", s.Std)
+		}
+		writer.Write([]string{
+			s.Name,
+			strconv.Itoa(s.WindowStart),
+			strconv.Itoa(s.WindowEnd),
+			strconv.Itoa(s.Seeds),
+			fmt.Sprintf("%.6f", s.Mean),
+			std,
+			fmt.Sprintf("%.6f", s.Min),
+			fmt.Sprintf("%.6f", s.Max),
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteScanCSV writes one row per scanned input with its headline metrics.
+// Undefined metrics are left empty, and an input that failed to load has
+// its error in the Error column.
+func WriteScanCSV(results []ScanResult, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	value := func(v float64) string {
+		if math.IsNaN(v) {
+			return ""
+		}
+		return fmt.Sprintf("%.6f", v)
+	}
+
+	writer.Write([]string{"Input", "Points", "TotalReturn", "MaxDrawdown", "Dimension", "R2", "Hurst", "Error"})
+
+	for _, r := range results {
+		errText := ""
+		if r.Err != nil {
+			errText = r.Err.Error()
+		}
+		writer.Write([]string{
+			r.Input,
+			strconv.Itoa(r.Points),
+			value(r.TotalReturn),
+			value(r.MaxDrawdown),
+			value(r.Dimension),
+			value(r.R2),
+			value(r.Hurst),
+			errText,
+		})
+	}
+
+	return finishCSV(writer, file)
+}
+
+// WriteCorrelationCSV writes a correlation matrix with the series names
+// as both the header and the first column; NaN entries are left empty.
+func WriteCorrelationCSV(names []string, matrix [][]float64, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	writer.Write(append([]string{"Series"}, names...))
+
+	for i, row := range matrix {
+		record := []string{names[i]}
+		for _, v := range row {
+			if math.IsNaN(v) {
+				record = append(record, "")
+			} else {
+				record = append(record, fmt.Sprintf("%.6f", v))
+			}
+		}
+		writer.Write(record)
+	}
+
+	return finishCSV(writer, file)
+}
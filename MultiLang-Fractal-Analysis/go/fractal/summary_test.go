@@ -0,0 +1,84 @@
+package fractal
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// The value of the named metric, failing the test if it is missing.
+func metricValue(t *testing.T, metrics []Metric, name string) any {
+	t.Helper()
+	for _, m := range metrics {
+		if m.Name == name {
+			return m.Value
+		}
+	}
+	t.Fatalf("no %s metric", name)
+	return nil
+}
+
+// Trimming the warm-up gives the volatility statistics of the valid tail.
+func TestSummarizeTrimWarmUp(t *testing.T) {
+	data := GenerateSeries(NewRand(1, 0), 500, 100)
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	warmUp := VolatilityWarmUp(data)
+	if warmUp == 0 {
+		t.Fatal("generated series has no warm-up")
+	}
+
+	trimmed := SummaryConfig{TrimWarmUp: true}.Summarize(data, nil)
+	tail := SummaryConfig{}.Summarize(data[warmUp:], nil)
+	if got := metricValue(t, trimmed, "VolatilityWarmUp"); got != warmUp {
+		t.Errorf("VolatilityWarmUp %v, want %d", got, warmUp)
+	}
+	for _, name := range []string{"VolatilityMean", "VolatilityMedian", "VolatilityMax"} {
+		got, want := metricValue(t, trimmed, name), metricValue(t, tail, name)
+		if got != want {
+			t.Errorf("%s %v, want %v from the tail alone", name, got, want)
+		}
+	}
+	untrimmed := SummaryConfig{}.Summarize(data, nil)
+	if metricValue(t, untrimmed, "VolatilityMean") == metricValue(t, trimmed, "VolatilityMean") {
+		t.Error("trimming left VolatilityMean unchanged")
+	}
+}
+
+// Empty and one-candle series run through returns, analysis and the
+// summary without panicking, with their documented sentinels.
+func TestShortSeries(t *testing.T) {
+	for _, n := range []int{0, 1} {
+		data := GenerateSeries(NewRand(69, 0), n, 100)
+		ComputeReturnsAndVol(data, DefaultVolWindow)
+		if n == 1 && (data[0].Returns != 0 || data[0].Volatility != 0) {
+			t.Errorf("n %d: candle %+v, want no return or volatility", n, data[0])
+		}
+		prices := SeriesPrice.Values(data)
+		if d := BoxCountingFractalDimension(prices); d != 1 {
+			t.Errorf("n %d: dimension %v, want the degenerate 1", n, d)
+		}
+
+		results, err := Analyzer{}.Run(context.Background(), data, []Window{{0, n}})
+		if err != nil {
+			t.Fatalf("n %d: %v", n, err)
+		}
+		for _, r := range results {
+			if !r.Degenerate {
+				t.Errorf("n %d: window %+v not degenerate", n, r)
+			}
+		}
+
+		metrics := Summarize(data, results)
+		if metricValue(t, metrics, "Points") != n {
+			t.Errorf("n %d: Points %v", n, metricValue(t, metrics, "Points"))
+		}
+		start := metricValue(t, metrics, "StartPrice").(float64)
+		if n == 0 && !math.IsNaN(start) || n == 1 && start != data[0].Price {
+			t.Errorf("n %d: StartPrice %v", n, start)
+		}
+		if err := WriteSummary(data, results, filepath.Join(t.TempDir(), "summary.csv")); err != nil {
+			t.Errorf("n %d: %v", n, err)
+		}
+	}
+}
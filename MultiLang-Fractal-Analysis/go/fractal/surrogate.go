@@ -0,0 +1,107 @@
+package fractal
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sort"
+)
+
+// PhaseRandomizedSurrogate returns a series with the same mean and power
+// spectrum as series, hence the same linear autocorrelation, but with
+// every Fourier phase redrawn uniformly. Any structure beyond what a
+// linear Gaussian process would show is destroyed, which makes surrogates
+// the null distribution for a nonlinearity test. Lengths that are not a
+// power of two are transformed exactly rather than padded.
+func PhaseRandomizedSurrogate(rng *rand.Rand, series []float64) []float64 {
+	n := len(series)
+	if n < 3 {
+		return append([]float64(nil), series...)
+	}
+
+	mean := 0.0
+	for _, v := range series {
+		mean += v
+	}
+	mean /= float64(n)
+	x := make([]complex128, n)
+	for i, v := range series {
+		x[i] = complex(v-mean, 0)
+	}
+	spec := dft(x, false)
+
+	// Conjugate pairs keep the result real; the zero frequency and, for
+	// even n, the Nyquist term are real and stay as they are
+	for k := 1; 2*k < n; k++ {
+		phase := 2 * math.Pi * rng.Float64()
+		spec[k] = complex(cmplx.Abs(spec[k]), 0) * cmplx.Exp(complex(0, phase))
+		spec[n-k] = cmplx.Conj(spec[k])
+	}
+
+	out := make([]float64, n)
+	for i, v := range dft(spec, true) {
+		out[i] = real(v) + mean
+	}
+	return out
+}
+
+type surrogateDraw struct {
+	result    int
+	dimension float64
+}
+
+// Fills the surrogate fields of results. Each window's series, or a price
+// window's increments, is phase-randomized a.Surrogates times on the
+// worker pool and fitted as fitReplicate does; the p-value is the
+// two-sided rank of the window's own dimension among the surrogates,
+// (1 + #{|d_s - m| >= |d - m|}) / (N + 1) around the surrogate mean m.
+// Streams follow the bootstrap's so enabling one test does not change the
+// other's draws.
+func (a Analyzer) surrogates(ctx context.Context, data []MarketCandle, results []FractalResult) error {
+	var jobs []bootstrapJob
+	for i := range results {
+		for s := 0; s < a.Surrogates; s++ {
+			jobs = append(jobs, bootstrapJob{i, s})
+		}
+	}
+
+	offset := 1 + len(results)*a.Bootstrap
+	draws, err := pool(ctx, a.Workers, jobs, func(job bootstrapJob) (surrogateDraw, bool, error) {
+		r := results[job.result]
+		rng := NewRand(a.Seed, offset+job.result*a.Surrogates+job.replicate)
+		d, err := a.fitReplicate(ctx, data[r.WindowStart:r.WindowEnd+1], func(x []float64) []float64 {
+			return PhaseRandomizedSurrogate(rng, x)
+		})
+		if err != nil {
+			return surrogateDraw{}, false, err
+		}
+		return surrogateDraw{job.result, d}, true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	dims := make([][]float64, len(results))
+	for _, d := range draws {
+		dims[d.result] = append(dims[d.result], d.dimension)
+	}
+	for i, ds := range dims {
+		if len(ds) == 0 {
+			continue
+		}
+		sort.Float64s(ds) // the pool's completion order would vary the mean's last bits
+		m := mean(ds)
+
+		extreme := 0
+		gap := math.Abs(results[i].Dimension - m)
+		for _, d := range ds {
+			if math.Abs(d-m) >= gap {
+				extreme++
+			}
+		}
+		results[i].SurrogateMean = m
+		results[i].SurrogateP = float64(1+extreme) / float64(len(ds)+1)
+	}
+	return nil
+}
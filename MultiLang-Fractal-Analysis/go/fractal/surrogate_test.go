@@ -0,0 +1,89 @@
+package fractal
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// A surrogate keeps the series' mean and every Fourier magnitude, for
+// even and odd lengths alike.
+func TestPhaseRandomizedSurrogate(t *testing.T) {
+	for _, n := range []int{64, 101} {
+		series := whiteNoise(72, n)
+		for i := range series {
+			series[i] += 5
+		}
+		s := PhaseRandomizedSurrogate(NewRand(72, n), series)
+		if len(s) != n || math.Abs(mean(s)-mean(series)) > 1e-9 {
+			t.Errorf("n %d: %d points with mean %v, want %d with %v", n, len(s), mean(s), n, mean(series))
+			continue
+		}
+		same := true
+		for i := range s {
+			same = same && math.Abs(s[i]-series[i]) < 1e-9
+		}
+		if same {
+			t.Errorf("n %d: surrogate equals the series", n)
+		}
+		want, got := dft(toComplex(series), false), dft(toComplex(s), false)
+		for k := range want {
+			if math.Abs(cmplx.Abs(got[k])-cmplx.Abs(want[k])) > 1e-6 {
+				t.Errorf("n %d: |X[%d]| %v, want %v", n, k, cmplx.Abs(got[k]), cmplx.Abs(want[k]))
+				break
+			}
+		}
+	}
+}
+
+func toComplex(series []float64) []complex128 {
+	x := make([]complex128, len(series))
+	for i, v := range series {
+		x[i] = complex(v, 0)
+	}
+	return x
+}
+
+// The p-value stays high on an AR(1) process, which phase
+// randomization reproduces, and falls to 0.01 on the Hénon map, whose
+// structure it destroys.
+func TestSurrogatesNonlinearity(t *testing.T) {
+	const n = 4000
+	noise := whiteNoise(72, n)
+	ar := make([]float64, n)
+	for i := 1; i < n; i++ {
+		ar[i] = 0.7*ar[i-1] + noise[i]
+	}
+	henon := make([]float64, n)
+	x, y := 0.1, 0.1
+	for i := range henon {
+		x, y = 1-1.4*x*x+y, 0.3*x
+		henon[i] = x
+	}
+
+	tests := []struct {
+		name   string
+		series []float64
+		reject bool
+	}{
+		{"AR(1)", ar, false},
+		{"Hénon", henon, true},
+	}
+	for _, tt := range tests {
+		data := make([]MarketCandle, n)
+		for i := range data {
+			data[i].Price = 100
+			data[i].Returns = tt.series[i]
+		}
+		results, err := Analyzer{Series: SeriesReturns, Surrogates: 99, Seed: 72}.Run(context.Background(), data, []Window{{0, n / 2}, {n / 2, n / 2}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range results {
+			if rejected := r.SurrogateP <= 0.05; rejected != tt.reject {
+				t.Errorf("%s window %d: p %v, want rejection %v", tt.name, r.WindowStart, r.SurrogateP, tt.reject)
+			}
+		}
+	}
+}
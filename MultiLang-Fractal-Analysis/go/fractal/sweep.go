@@ -0,0 +1,86 @@
+package fractal
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// SweepRun is one seed's analysis in a seed sweep.
+type SweepRun struct {
+	Seed    int64
+	Results []FractalResult
+}
+
+// SweepStats summarises one window's dimension across a seed sweep. Name
+// is left for the caller, which knows what the window stands for.
+type SweepStats struct {
+	Name        string
+	WindowStart int
+	WindowEnd   int
+	Seeds       int
+	Mean        float64
+	Std         float64 // sample standard deviation, NaN for one seed
+	Min         float64
+	Max         float64
+}
+
+// SeedSweep generates a series for each seed with gen, from that seed's
+// own NewRand(seed, 0) so each run matches a normal run with that seed,
+// and analyses windows of it as Run does. Seeds are spread over the worker
+// pool with each analysis on a single worker. Runs come back in seed
+// order; ctx cancellation returns ctx.Err() with no runs.
+func (a Analyzer) SeedSweep(ctx context.Context, seeds []int64, gen func(*rand.Rand) []MarketCandle, windows []Window) ([]SweepRun, error) {
+	inner := a
+	inner.Workers = 1
+	runs, err := pool(ctx, a.Workers, seeds, func(seed int64) (SweepRun, bool, error) {
+		results, err := inner.Run(ctx, gen(NewRand(seed, 0)), windows)
+		if err != nil {
+			return SweepRun{}, false, err
+		}
+		return SweepRun{seed, results}, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Seed < runs[j].Seed })
+	return runs, nil
+}
+
+// SummarizeSweep groups the runs' results by window and returns the
+// spread of the dimension per window, in window order.
+func SummarizeSweep(runs []SweepRun) []SweepStats {
+	type key struct{ start, end int }
+	dims := map[key][]float64{}
+	var order []key
+	for _, run := range runs {
+		for _, r := range run.Results {
+			k := key{r.WindowStart, r.WindowEnd}
+			if _, ok := dims[k]; !ok {
+				order = append(order, k)
+			}
+			dims[k] = append(dims[k], r.Dimension)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].start != order[j].start {
+			return order[i].start < order[j].start
+		}
+		return order[i].end < order[j].end
+	})
+
+	stats := make([]SweepStats, len(order))
+	for i, k := range order {
+		ds := dims[k]
+		s := SweepStats{WindowStart: k.start, WindowEnd: k.end, Seeds: len(ds), Min: math.Inf(1), Max: math.Inf(-1)}
+		for _, d := range ds {
+			s.Min = math.Min(s.Min, d)
+			s.Max = math.Max(s.Max, d)
+		}
+		s.Mean = mean(ds)
+		s.Std = math.Sqrt(variance(ds)) // NaN for a single seed
+		stats[i] = s
+	}
+	return stats
+}
@@ -0,0 +1,64 @@
+package fractal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSeedSweep(t *testing.T) {
+	gen := func(rng *rand.Rand) []MarketCandle {
+		data := GenerateSeries(rng, 1000, 100)
+		ComputeReturnsAndVol(data, DefaultVolWindow)
+		return data
+	}
+	windows := []Window{{0, 500}, {500, 500}, {0, 1000}}
+	seeds := []int64{67, 68, 69, 70, 71}
+	a := Analyzer{Workers: 3}
+	runs, err := a.SeedSweep(context.Background(), seeds, gen, windows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != len(seeds) {
+		t.Fatalf("%d runs, want %d", len(runs), len(seeds))
+	}
+
+	seen := map[float64]bool{}
+	for i, run := range runs {
+		if run.Seed != seeds[i] {
+			t.Errorf("run %d has seed %d, want %d", i, run.Seed, seeds[i])
+		}
+		// Each run is the normal run with its seed
+		want, err := Analyzer{Workers: 1}.Run(context.Background(), gen(NewRand(run.Seed, 0)), windows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range want {
+			want[j].ComputeMicros, run.Results[j].ComputeMicros = 0, 0
+		}
+		// Printed, as the unset bootstrap fields are NaN and never DeepEqual
+		if fmt.Sprint(run.Results) != fmt.Sprint(want) {
+			t.Errorf("seed %d: sweep results differ from a plain run", run.Seed)
+		}
+		seen[run.Results[2].Dimension] = true
+	}
+	if len(seen) != len(seeds) {
+		t.Errorf("%d distinct whole-series dimensions over %d seeds, want distinct series", len(seen), len(seeds))
+	}
+
+	stats := SummarizeSweep(runs)
+	if len(stats) != len(windows) {
+		t.Fatalf("%d window stats, want %d", len(stats), len(windows))
+	}
+	for _, s := range stats {
+		finite := !math.IsNaN(s.Mean) && !math.IsNaN(s.Std) && !math.IsInf(s.Mean, 0) && !math.IsInf(s.Std, 0)
+		if s.Seeds != len(seeds) || !finite || s.Std <= 0 || !(s.Min <= s.Mean && s.Mean <= s.Max) {
+			t.Errorf("window %d-%d: %+v, want finite stats over %d seeds", s.WindowStart, s.WindowEnd, s, len(seeds))
+		}
+	}
+	if stats[0].WindowStart != 0 || stats[0].WindowEnd != 499 || stats[1].WindowEnd != 999 || stats[2].WindowStart != 500 {
+		t.Errorf("stats in order %+v, want by start then end", stats)
+	}
+}
@@ -0,0 +1,81 @@
+package fractal
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SlopeMethod selects the regression used for log-log scaling fits.
+type SlopeMethod string
+
+const (
+	SlopeOLS      SlopeMethod = "ols"      // ordinary least squares
+	SlopeTheilSen SlopeMethod = "theilsen" // median of pairwise slopes
+)
+
+// ParseSlopeMethod validates a slope method name.
+func ParseSlopeMethod(name string) (SlopeMethod, error) {
+	switch m := SlopeMethod(name); m {
+	case SlopeOLS, SlopeTheilSen:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown slope method %q (want ols or theilsen)", name)
+}
+
+// Fit regresses y on x with m, returning the slope, the R² of the fitted
+// line and whether a line was defined. An empty method means SlopeOLS.
+func (m SlopeMethod) Fit(x, y []float64) (slope, r2 float64, ok bool) {
+	if m == SlopeTheilSen {
+		return TheilSenFit(x, y)
+	}
+	return LinearFit(x, y)
+}
+
+// TheilSenSlope returns the median of the slopes between every pair of
+// points with distinct x. Up to about 29% of the points can be arbitrarily
+// wrong without moving it far, unlike LinearSlope. ok is false under the
+// same conditions as LinearSlope. The cost is O(n² log n).
+func TheilSenSlope(x, y []float64) (slope float64, ok bool) {
+	if len(x) != len(y) || len(x) < 2 {
+		return 0, false
+	}
+
+	var slopes []float64
+	for i := range x {
+		for j := i + 1; j < len(x); j++ {
+			if dx := x[j] - x[i]; dx != 0 {
+				slopes = append(slopes, (y[j]-y[i])/dx)
+			}
+		}
+	}
+	if len(slopes) == 0 {
+		return 0, false
+	}
+	return median(slopes), true
+}
+
+// TheilSenFit is TheilSenSlope with the R² of the line through the median
+// of the residual intercepts y - slope*x.
+func TheilSenFit(x, y []float64) (slope, r2 float64, ok bool) {
+	slope, ok = TheilSenSlope(x, y)
+	if !ok {
+		return 0, 0, false
+	}
+
+	intercepts := make([]float64, len(x))
+	for i := range x {
+		intercepts[i] = y[i] - slope*x[i]
+	}
+	intercept := median(intercepts)
+	return slope, rSquared(x, y, slope, intercept), true
+}
+
+// Median of values, which is reordered in place.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
@@ -0,0 +1,54 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// One outlier barely moves the Theil-Sen slope but drags least squares.
+func TestTheilSenResistsOutlier(t *testing.T) {
+	noise := whiteNoise(39, 20)
+	x := make([]float64, 20)
+	y := make([]float64, 20)
+	for i := range x {
+		x[i] = float64(i)
+		y[i] = 2*x[i] + 1 + 0.1*noise[i]
+	}
+	olsClean, _ := LinearSlope(x, y)
+	tsClean, _ := TheilSenSlope(x, y)
+
+	y[19] += 50
+	olsOutlier, _ := LinearSlope(x, y)
+	tsOutlier, _ := TheilSenSlope(x, y)
+
+	if shift := math.Abs(tsOutlier - tsClean); shift > 0.05 {
+		t.Errorf("Theil-Sen moved %v with one outlier", shift)
+	}
+	if shift := math.Abs(olsOutlier - olsClean); shift < 0.5 {
+		t.Errorf("least squares moved only %v with one outlier", shift)
+	}
+	if math.Abs(tsClean-2) > 0.05 {
+		t.Errorf("Theil-Sen slope %v on clean data, want 2", tsClean)
+	}
+}
+
+func TestTheilSenSlope(t *testing.T) {
+	tests := []struct {
+		name      string
+		x, y      []float64
+		wantSlope float64
+		wantOK    bool
+	}{
+		{"perfect line", []float64{0, 1, 2, 3}, []float64{1, 4, 7, 10}, 3, true},
+		{"even pair count", []float64{0, 1, 2}, []float64{0, 1, 4}, 2, true},       // slopes 1, 2, 3
+		{"repeated x skipped", []float64{1, 1, 2}, []float64{0, 5, 2}, -0.5, true}, // slopes 2, -3
+		{"vertical x", []float64{1, 1, 1}, []float64{0, 1, 2}, 0, false},
+		{"mismatched lengths", []float64{0, 1}, []float64{0}, 0, false},
+	}
+	for _, tt := range tests {
+		slope, ok := TheilSenSlope(tt.x, tt.y)
+		if ok != tt.wantOK || math.Abs(slope-tt.wantSlope) > 1e-12 {
+			t.Errorf("%s: slope %v ok %v, want %v %v", tt.name, slope, ok, tt.wantSlope, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package fractal
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tick is one trade from a raw feed.
+type Tick struct {
+	Timestamp time.Time
+	Price     float64
+	Size      float64
+}
+
+// EmptyBuckets selects what AggregateTicks does with intervals that saw
+// no trades.
+type EmptyBuckets string
+
+const (
+	SkipEmpty EmptyBuckets = "skip"  // leave the interval out
+	FillEmpty EmptyBuckets = "ffill" // flat candle at the last close, zero volume
+)
+
+// ParseEmptyBuckets validates an empty-bucket policy name.
+func ParseEmptyBuckets(name string) (EmptyBuckets, error) {
+	switch e := EmptyBuckets(name); e {
+	case SkipEmpty, FillEmpty:
+		return e, nil
+	}
+	return "", fmt.Errorf("unknown empty-bucket policy %q (want skip or ffill)", name)
+}
+
+// AggregateTicks buckets ticks into OHLCV candles of the given interval,
+// each stamped with the start of its interval (truncated from the zero
+// time, so minute and hour buckets fall on clock boundaries in UTC).
+// Ticks need not be sorted; ones with equal timestamps keep their order.
+// Price is the close and Volume the summed trade size, and Returns and a
+// DefaultVolWindow volatility are filled in as ReadMarketCSV does.
+func AggregateTicks(ticks []Tick, interval time.Duration, empty EmptyBuckets) []MarketCandle {
+	if len(ticks) == 0 || interval <= 0 {
+		return nil
+	}
+	sorted := append([]Tick(nil), ticks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	var data []MarketCandle
+	for _, t := range sorted {
+		bucket := t.Timestamp.Truncate(interval)
+		if n := len(data); n > 0 && data[n-1].Timestamp.Equal(bucket) {
+			c := &data[n-1]
+			c.High = max(c.High, t.Price)
+			c.Low = min(c.Low, t.Price)
+			c.Close, c.Price = t.Price, t.Price
+			c.Volume += t.Size
+			continue
+		}
+		if n := len(data); n > 0 && empty == FillEmpty {
+			last := data[n-1].Close
+			for at := data[n-1].Timestamp.Add(interval); at.Before(bucket); at = at.Add(interval) {
+				data = append(data, MarketCandle{Timestamp: at, Price: last, Open: last, High: last, Low: last, Close: last})
+			}
+		}
+		data = append(data, MarketCandle{
+			Timestamp: bucket,
+			Price:     t.Price,
+			Volume:    t.Size,
+			Open:      t.Price,
+			High:      t.Price,
+			Low:       t.Price,
+			Close:     t.Price,
+		})
+	}
+
+	ComputeReturnsAndVol(data, DefaultVolWindow)
+	return data
+}
+
+// ReadTicksCSV reads trades from a (possibly gzipped) CSV with timestamp,
+// price and optional size columns, under any of the header names
+// ReadMarketCSV accepts for Timestamp, Price and Volume. timeFormat is as
+// for MarketCSVReader, but an unparseable timestamp is an error, since
+// trades cannot be bucketed without their times.
+func ReadTicksCSV(filename, timeFormat string) ([]Tick, error) {
+	file, err := openInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%s: missing header", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	cols := mapColumns(header)
+	if _, ok := cols["Price"]; !ok {
+		return nil, fmt.Errorf("%s: no price column (want a header such as %s)", filename, aliasList("Price"))
+	}
+	if _, ok := cols["Timestamp"]; !ok {
+		return nil, fmt.Errorf("%s: no timestamp column (want a header such as %s)", filename, aliasList("Timestamp"))
+	}
+	sizeCol, hasSize := cols["Volume"]
+
+	var ticks []Tick
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue // blank line
+		}
+
+		field := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		var t Tick
+		if t.Timestamp, err = ParseTimestamp(field(cols["Timestamp"]), timeFormat); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", filename, line, err)
+		}
+		if t.Price, err = strconv.ParseFloat(field(cols["Price"]), 64); err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid Price: %w", filename, line, err)
+		}
+		if hasSize {
+			if t.Size, err = strconv.ParseFloat(field(sizeCol), 64); err != nil {
+				return nil, fmt.Errorf("%s line %d: invalid Size: %w", filename, line, err)
+			}
+		}
+		ticks = append(ticks, t)
+	}
+	return ticks, nil
+}
@@ -0,0 +1,71 @@
+package fractal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Five unsorted trades over two minutes make two OHLCV candles on the
+// minute boundaries.
+func TestAggregateTicks(t *testing.T) {
+	at := func(min, sec int) time.Time { return time.Date(2024, 3, 1, 9, min, sec, 0, time.UTC) }
+	ticks := []Tick{
+		{at(30, 40), 101, 2},
+		{at(30, 5), 100, 1},
+		{at(31, 59), 99, 4},
+		{at(30, 50), 102.5, 3},
+		{at(31, 1), 98, 5},
+	}
+	got := AggregateTicks(ticks, time.Minute, SkipEmpty)
+	want := []MarketCandle{
+		{Timestamp: at(30, 0), Open: 100, High: 102.5, Low: 100, Close: 102.5, Price: 102.5, Volume: 6},
+		{Timestamp: at(31, 0), Open: 98, High: 99, Low: 98, Close: 99, Price: 99, Volume: 9},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d candles, want %d: %+v", len(got), len(want), got)
+	}
+	for i, c := range got {
+		w := want[i]
+		if !c.Timestamp.Equal(w.Timestamp) || c.Open != w.Open || c.High != w.High || c.Low != w.Low ||
+			c.Close != w.Close || c.Price != w.Price || c.Volume != w.Volume {
+			t.Errorf("candle %d %+v, want %+v", i, c, w)
+		}
+	}
+	if got[0].Returns != 0 || got[1].Returns != 99/102.5-1 {
+		t.Errorf("returns %v and %v, want 0 and %v", got[0].Returns, got[1].Returns, 99/102.5-1)
+	}
+}
+
+func TestAggregateTicksEmptyBuckets(t *testing.T) {
+	at := func(min int) time.Time { return time.Date(2024, 3, 1, 9, min, 0, 0, time.UTC) }
+	ticks := []Tick{{at(0), 100, 1}, {at(3), 103, 1}}
+	tests := []struct {
+		empty   EmptyBuckets
+		minutes []int
+		prices  []float64
+		volumes []float64
+	}{
+		{SkipEmpty, []int{0, 3}, []float64{100, 103}, []float64{1, 1}},
+		{FillEmpty, []int{0, 1, 2, 3}, []float64{100, 100, 100, 103}, []float64{1, 0, 0, 1}},
+	}
+	for _, tt := range tests {
+		got := AggregateTicks(ticks, time.Minute, tt.empty)
+		if len(got) != len(tt.prices) {
+			t.Errorf("%s: %d candles, want %d", tt.empty, len(got), len(tt.prices))
+			continue
+		}
+		for i, c := range got {
+			if !c.Timestamp.Equal(at(tt.minutes[i])) || c.Price != tt.prices[i] || c.Volume != tt.volumes[i] {
+				t.Errorf("%s: candle %d %+v, want minute %d price %v volume %v", tt.empty, i, c, tt.minutes[i], tt.prices[i], tt.volumes[i])
+			}
+		}
+	}
+
+	if got := AggregateTicks(nil, time.Minute, SkipEmpty); got != nil {
+		t.Errorf("no ticks: %v, want nil", got)
+	}
+	if _, err := ParseEmptyBuckets("zero"); err == nil || !strings.Contains(err.Error(), "skip or ffill") {
+		t.Errorf("ParseEmptyBuckets: error %v", err)
+	}
+}
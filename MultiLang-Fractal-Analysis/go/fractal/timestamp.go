@@ -0,0 +1,61 @@
+package fractal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout names, accepted wherever a timestamp layout is, for integer
+// seconds and milliseconds since the Unix epoch.
+const (
+	UnixSeconds = "unix"
+	UnixMillis  = "unixms"
+)
+
+// DefaultTimeLayouts are the layouts ParseTimestamp tries, in order, when
+// no layout is given.
+var DefaultTimeLayouts = []string{
+	time.RFC3339,
+	timestampLayout,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// Integers at least this large are taken as epoch milliseconds; as
+// seconds they would fall after the year 5000.
+const epochMillisFrom = 100_000_000_000
+
+// ParseTimestamp parses raw with a Go time layout, UnixSeconds or
+// UnixMillis. An empty layout detects the format: integers are epoch
+// seconds or milliseconds by magnitude, and anything else is tried
+// against DefaultTimeLayouts.
+func ParseTimestamp(raw, layout string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	switch layout {
+	case "":
+		if epoch, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			if epoch >= epochMillisFrom || epoch <= -epochMillisFrom {
+				return time.UnixMilli(epoch).UTC(), nil
+			}
+			return time.Unix(epoch, 0).UTC(), nil
+		}
+		for _, l := range DefaultTimeLayouts {
+			if t, err := time.Parse(l, raw); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("timestamp %q is not an epoch and matches none of %s", raw, strings.Join(DefaultTimeLayouts, ", "))
+	case UnixSeconds, UnixMillis:
+		epoch, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("timestamp %q is not an integer epoch", raw)
+		}
+		if layout == UnixMillis {
+			return time.UnixMilli(epoch).UTC(), nil
+		}
+		return time.Unix(epoch, 0).UTC(), nil
+	}
+	return time.Parse(layout, raw)
+}
@@ -0,0 +1,59 @@
+package fractal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		raw, layout string
+		want        time.Time
+		wantErr     bool
+	}{
+		{"2024-03-01T14:30:00Z", "", want, false},
+		{"2024-03-01T16:30:00+02:00", "", want, false},
+		{"2024-03-01 14:30:00", "", want, false},
+		{"2024-03-01T14:30:00", "", want, false},
+		{"2024-03-01", "", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"1709303400", "", want, false},
+		{"1709303400000", "", want, false},
+		{" 1709303400 ", "", want, false},
+		{"1709303400", UnixSeconds, want, false},
+		{"1709303400000", UnixMillis, want, false},
+		{"1709303", UnixMillis, time.UnixMilli(1709303).UTC(), false}, // small, but the layout says millis
+		{"01/03/2024 14:30", "02/01/2006 15:04", want, false},
+		{"yesterday", "", time.Time{}, true},
+		{"2024-03-01", UnixSeconds, time.Time{}, true},
+		{"2024-03-01", "02/01/2006", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.raw, tt.layout)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimestamp(%q, %q): error %v, want error %v", tt.raw, tt.layout, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("ParseTimestamp(%q, %q) = %v, want %v", tt.raw, tt.layout, got, tt.want)
+		}
+	}
+}
+
+// One bad timestamp falls back to sequential hourly timestamps rather
+// than failing the read.
+func TestReadMarketCSVTimestampFallback(t *testing.T) {
+	path := writeTempCSV(t, "in.csv", "time,price\n1709303400,100\nnot a time,101\n1709310600,102\n")
+	data, err := ReadMarketCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range data {
+		if want := time.Unix(0, 0).UTC().Add(time.Duration(i) * time.Hour); !c.Timestamp.Equal(want) {
+			t.Errorf("candle %d at %v, want %v", i, c.Timestamp, want)
+		}
+	}
+	if len(data) != 3 || data[1].Price != 101 {
+		t.Errorf("read %+v, want all three rows", data)
+	}
+}
@@ -0,0 +1,40 @@
+package fractal
+
+import (
+	"fmt"
+	"math"
+)
+
+// Validate checks candles before they enter the pipeline: every price
+// field must be positive and finite, and volume, returns and volatility
+// finite. A zero price would otherwise turn the next return into ±Inf and
+// poison every estimate downstream. The error names the first offending
+// candle by index.
+func Validate(data []MarketCandle) error {
+	for i, c := range data {
+		prices := []struct {
+			name  string
+			value float64
+		}{
+			{"price", c.Price}, {"open", c.Open}, {"high", c.High}, {"low", c.Low}, {"close", c.Close},
+		}
+		for _, p := range prices {
+			if math.IsNaN(p.value) || math.IsInf(p.value, 0) || p.value <= 0 {
+				return fmt.Errorf("candle %d: %s %v is not a positive finite number", i, p.name, p.value)
+			}
+		}
+
+		others := []struct {
+			name  string
+			value float64
+		}{
+			{"volume", c.Volume}, {"returns", c.Returns}, {"volatility", c.Volatility},
+		}
+		for _, o := range others {
+			if math.IsNaN(o.value) || math.IsInf(o.value, 0) {
+				return fmt.Errorf("candle %d: %s is %v", i, o.name, o.value)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package fractal
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*MarketCandle)
+		errors string // part of the error, empty for none
+	}{
+		{"clean", func(*MarketCandle) {}, ""},
+		{"NaN price", func(c *MarketCandle) { c.Price = math.NaN() }, "candle 7: price NaN"},
+		{"zero close", func(c *MarketCandle) { c.Close = 0 }, "candle 7: close 0"},
+		{"negative low", func(c *MarketCandle) { c.Low = -1 }, "candle 7: low -1"},
+		{"infinite high", func(c *MarketCandle) { c.High = math.Inf(1) }, "candle 7: high +Inf"},
+		{"NaN returns", func(c *MarketCandle) { c.Returns = math.NaN() }, "candle 7: returns is NaN"},
+		{"infinite volume", func(c *MarketCandle) { c.Volume = math.Inf(-1) }, "candle 7: volume is -Inf"},
+	}
+	for _, tt := range tests {
+		data := GenerateSeries(NewRand(40, 0), 20, 100)
+		ComputeReturnsAndVol(data, DefaultVolWindow)
+		tt.modify(&data[7])
+		err := Validate(data)
+		if tt.errors == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.errors) {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.errors)
+		}
+	}
+}
+
+// An injected NaN occupies no box and stretches no range, so the dimension
+// stays finite and close to that of the clean series.
+func TestBoxCountingSkipsNaN(t *testing.T) {
+	prices := randomWalk(40, 500)
+	clean, _ := BoxCounter{}.Fit(prices)
+
+	prices[250] = math.NaN()
+	d, r2 := BoxCounter{}.Fit(prices)
+	if math.IsNaN(d) || math.IsInf(d, 0) || math.IsNaN(r2) {
+		t.Fatalf("dimension %v r2 %v with one NaN", d, r2)
+	}
+	if math.Abs(d-clean) > 0.05 {
+		t.Errorf("dimension %v with one NaN, %v without", d, clean)
+	}
+}
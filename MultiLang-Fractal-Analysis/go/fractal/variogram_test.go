@@ -0,0 +1,43 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// On fBm the variogram dimension lands near 2 - H, through the registry as
+// well as directly.
+func TestVariogramFBM(t *testing.T) {
+	e, ok := LookupEstimator("variogram")
+	if !ok {
+		t.Fatal("variogram not registered")
+	}
+	for _, hurst := range []float64{0.3, 0.5, 0.7} {
+		path := GenerateFBM(NewRand(41, 0), 4096, hurst)
+		d, r2 := VariogramFit(path, DefaultVariogramMaxLag)
+		if math.Abs(d-(2-hurst)) > 0.05 {
+			t.Errorf("H %v: dimension %v, want %v", hurst, d, 2-hurst)
+		}
+		if r2 < 0.99 {
+			t.Errorf("H %v: r2 %v, want a near-straight variogram", hurst, r2)
+		}
+		if got, err := e.Dimension(path); err != nil || got != VariogramFractalDimension(path) {
+			t.Errorf("H %v: registered estimator %v, %v", hurst, got, err)
+		}
+	}
+}
+
+func TestVariogramDegenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		series []float64
+	}{
+		{"constant", []float64{5, 5, 5, 5, 5, 5, 5, 5}},
+		{"two points", []float64{1, 2}},
+	}
+	for _, tt := range tests {
+		if d, r2 := VariogramFit(tt.series, DefaultVariogramMaxLag); d != 1 || r2 != 0 {
+			t.Errorf("%s: dimension %v r2 %v, want 1 and 0", tt.name, d, r2)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package fractal
+
+import "math"
+
+// Levels with fewer detail coefficients than this are too noisy to fit.
+const minWaveletCoefficients = 8
+
+// WaveletFractalDimension estimates the dimension of a series from the
+// variance of its Haar wavelet detail coefficients across scales. For a
+// self-affine path with Hurst exponent H the variance at level j grows as
+// 2^(j(2H+1)), so H = (slope-1)/2 of log2 variance against j and D = 2-H.
+// Each level only compares neighbouring averages, so the estimate is less
+// disturbed than box counting by slow changes in level or volatility.
+func WaveletFractalDimension(series []float64) float64 {
+	d, _ := WaveletFit(series)
+	return d
+}
+
+// WaveletFit returns the wavelet dimension with the R² of the scale fit,
+// using every level with at least eight coefficients; an odd point left
+// over at a level is dropped. Degenerate inputs report 1.0 with R² 0.
+func WaveletFit(series []float64) (dimension, r2 float64) {
+	approx := append([]float64(nil), series...)
+	var levels, logVar []float64
+	for j := 1; len(approx)/2 >= minWaveletCoefficients; j++ {
+		half := len(approx) / 2
+		next := make([]float64, half)
+		sum := 0.0
+		for i := 0; i < half; i++ {
+			a, b := approx[2*i], approx[2*i+1]
+			next[i] = (a + b) / math.Sqrt2
+			d := (a - b) / math.Sqrt2
+			sum += d * d
+		}
+		if v := sum / float64(half); v > 0 {
+			levels = append(levels, float64(j))
+			logVar = append(logVar, math.Log2(v))
+		}
+		approx = next
+	}
+
+	if len(levels) < 3 {
+		return 1.0, 0
+	}
+	slope, r2, ok := LinearFit(levels, logVar)
+	if !ok {
+		return 1.0, 0
+	}
+	return 2 - (slope-1)/2, r2
+}
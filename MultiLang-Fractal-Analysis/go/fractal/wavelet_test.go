@@ -0,0 +1,30 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// On fBm the Haar wavelet dimension tracks 2 - H, rising as H falls.
+func TestWaveletFBM(t *testing.T) {
+	prev := math.Inf(1)
+	for _, hurst := range []float64{0.3, 0.5, 0.7} {
+		d, r2 := WaveletFit(GenerateFBM(NewRand(68, 0), 8192, hurst))
+		if math.Abs(d-(2-hurst)) > 0.1 {
+			t.Errorf("H %v: dimension %v, want %v", hurst, d, 2-hurst)
+		}
+		if r2 < 0.95 {
+			t.Errorf("H %v: r2 %v, want a straight scale fit", hurst, r2)
+		}
+		if d >= prev {
+			t.Errorf("H %v: dimension %v not below %v at the lower H", hurst, d, prev)
+		}
+		prev = d
+	}
+
+	for _, series := range [][]float64{make([]float64, 64), randomWalk(68, 15)} {
+		if d, r2 := WaveletFit(series); d != 1 || r2 != 0 {
+			t.Errorf("%d points: dimension %v r2 %v, want 1 and 0", len(series), d, r2)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package fractal
+
+// DetectWilliamsFractals locates Bill Williams' five-bar fractals in data,
+// returning the indices of bullish (pivot low) and bearish (pivot high)
+// fractals in increasing order, using each candle's Low and High. Data
+// loaded without OHLC has Low == High == Price.
+func DetectWilliamsFractals(data []MarketCandle) (bullish, bearish []int) {
+	lows := make([]float64, len(data))
+	highs := make([]float64, len(data))
+	for i, c := range data {
+		lows[i], highs[i] = c.Low, c.High
+	}
+	return WilliamsFractals(lows, highs)
+}
+
+// WilliamsFractals finds five-bar pivots: a bullish fractal is a bar whose
+// low is strictly below the lows of the two bars on each side, a bearish
+// fractal a bar whose high is strictly above the neighbouring highs. lows
+// and highs must have the same length.
+func WilliamsFractals(lows, highs []float64) (bullish, bearish []int) {
+	for i := 2; i+2 < len(lows); i++ {
+		if lows[i] < lows[i-2] && lows[i] < lows[i-1] && lows[i] < lows[i+1] && lows[i] < lows[i+2] {
+			bullish = append(bullish, i)
+		}
+		if highs[i] > highs[i-2] && highs[i] > highs[i-1] && highs[i] > highs[i+1] && highs[i] > highs[i+2] {
+			bearish = append(bearish, i)
+		}
+	}
+	return bullish, bearish
+}
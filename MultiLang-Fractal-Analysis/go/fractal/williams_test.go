@@ -0,0 +1,67 @@
+package fractal
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWilliamsFractals(t *testing.T) {
+	tests := []struct {
+		name             string
+		prices           []float64
+		bullish, bearish []int
+	}{
+		{
+			// Peaks at 4 and 12, a trough at 8; the ends lack two neighbours
+			name:    "zig-zag",
+			prices:  []float64{0, 1, 2, 3, 4, 3, 2, 1, 0, 1, 2, 3, 4, 3, 2},
+			bullish: []int{8},
+			bearish: []int{4, 12},
+		},
+		{
+			name:    "sawtooth",
+			prices:  []float64{5, 3, 1, 3, 5, 3, 1, 3, 5},
+			bullish: []int{2, 6},
+			bearish: []int{4},
+		},
+		{
+			// A tie with a neighbour is not strictly below it
+			name:   "flat bottom",
+			prices: []float64{3, 2, 1, 1, 2, 3},
+		},
+		{name: "too short", prices: []float64{2, 1, 0, 1}},
+	}
+	for _, tt := range tests {
+		bullish, bearish := WilliamsFractals(tt.prices, tt.prices)
+		if !slices.Equal(bullish, tt.bullish) || !slices.Equal(bearish, tt.bearish) {
+			t.Errorf("%s: bullish %v bearish %v, want %v %v", tt.name, bullish, bearish, tt.bullish, tt.bearish)
+		}
+	}
+}
+
+// Pivots are written in index order with the pivot low or high as price.
+func TestWritePivotsCSV(t *testing.T) {
+	closes := []float64{0, 1, 2, 3, 4, 3, 2, 1, 0, 1, 2, 3, 4, 3, 2}
+	data := make([]MarketCandle, len(closes))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, p := range closes {
+		data[i] = MarketCandle{Timestamp: start.Add(time.Duration(i) * time.Hour), Price: p, Low: p - 0.5, High: p + 0.5}
+	}
+	bullish, bearish := DetectWilliamsFractals(data)
+
+	path := filepath.Join(t.TempDir(), "fractals_pivots.csv")
+	if err := WritePivotsCSV(data, bullish, bearish, path); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Index", "Timestamp", "Price", "Type"},
+		{"4", "2024-01-01 04:00:00", "4.500000", "bearish"},
+		{"8", "2024-01-01 08:00:00", "-0.500000", "bullish"},
+		{"12", "2024-01-01 12:00:00", "4.500000", "bearish"},
+	}
+	if got := readCSVFile(t, path); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("rows %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+package fractal
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// WTMM scale grid: log-spaced from the finest scale the sampling resolves
+// up to a sixteenth of the series, and how many consecutive scales a
+// maxima line must span before its exponent is fitted.
+const (
+	wtmmMinScale  = 2.0
+	wtmmScales    = 20
+	wtmmMinLength = 10
+)
+
+// HolderExponent is the local Hölder exponent estimated along one wavelet
+// modulus maxima line, positioned where the line reaches the finest scale.
+type HolderExponent struct {
+	Index    int     // candle the line converges to
+	Exponent float64 // slope of log |W| against log scale
+	R2       float64
+	Scales   int // scales the line spans and the fit uses
+}
+
+// WTMM estimates local Hölder exponents of series with the wavelet
+// transform modulus maxima method. The continuous wavelet transform with
+// the Mexican hat (second derivative of a Gaussian, blind to linear
+// trends) is taken on log-spaced scales, the local maxima of its modulus
+// at each scale are chained into lines from fine to coarse scales, and
+// along every line long enough |W(a)| ~ a^h gives the exponent h at the
+// point the line converges to. With two vanishing moments exponents up to
+// 2 are resolved: a cusp |t-t0|^h shows h and a jump 0.
+//
+// Along each line |W| is replaced by its running maximum from the finest
+// scale (the supremum rule of Arneodo et al.), so a line that fades among
+// fine-scale noise cannot report a negative exponent. Lines that survive
+// to the coarsest scales give the steadiest estimates, about 0.5 on
+// Brownian motion; shorter ones read low, so weigh them by Scales and R2.
+//
+// The series is mirrored at both ends to limit edge effects, and maxima
+// within two scale widths of an end are ignored. Series too short for the
+// scale grid yield nil.
+func WTMM(series []float64) []HolderExponent {
+	n := len(series)
+	maxScale := float64(n) / 16
+	if maxScale < 4*wtmmMinScale {
+		return nil
+	}
+	scales := make([]float64, wtmmScales)
+	for j := range scales {
+		scales[j] = wtmmMinScale * math.Pow(maxScale/wtmmMinScale, float64(j)/float64(wtmmScales-1))
+	}
+
+	maxima := make([][]int, len(scales))
+	modulus := make([][]float64, len(scales))
+	for j, w := range cwtMexicanHat(series, scales) {
+		modulus[j] = w
+		maxima[j] = modulusMaxima(w, int(math.Ceil(2*scales[j])))
+	}
+	return holderAlongLines(scales, maxima, modulus)
+}
+
+// Mexican hat transform of series at each scale, normalized by 1/a so |W|
+// scales as a^h at a Hölder-h point. Computed by FFT convolution on the
+// mean-removed series mirrored at both ends; rows have the input's length.
+func cwtMexicanHat(series []float64, scales []float64) [][]float64 {
+	n := len(series)
+	mu := mean(series)
+	m := nextPow2(3 * n)
+	x := make([]complex128, m)
+	for i := 0; i < 3*n; i++ {
+		// Reflect n points either side of the series, then zero-pad
+		k := i - n
+		switch {
+		case k < 0:
+			k = -k - 1
+		case k >= n:
+			k = 2*n - k - 1
+		}
+		x[i] = complex(series[k]-mu, 0)
+	}
+	fft(x, false)
+
+	rows := make([][]float64, len(scales))
+	buf := make([]complex128, m)
+	for j, a := range scales {
+		for k := range buf {
+			f := k
+			if f > m/2 {
+				f -= m
+			}
+			aw := a * 2 * math.Pi * float64(f) / float64(m)
+			buf[k] = x[k] * complex(math.Sqrt(2*math.Pi)*aw*aw*math.Exp(-aw*aw/2), 0)
+		}
+		fft(buf, true)
+		row := make([]float64, n)
+		for i := range row {
+			row[i] = cmplx.Abs(buf[n+i])
+		}
+		rows[j] = row
+	}
+	return rows
+}
+
+// Indices of the local maxima of w at least margin points from either end,
+// ignoring ones lost in rounding noise. The left neighbour must be
+// strictly smaller, so a flat-topped maximum is reported once.
+func modulusMaxima(w []float64, margin int) []int {
+	peak := 0.0
+	for _, v := range w {
+		peak = math.Max(peak, v)
+	}
+	var idx []int
+	for i := max(margin, 1); i < len(w)-max(margin, 1); i++ {
+		if w[i] > w[i-1] && w[i] >= w[i+1] && w[i] > 1e-9*peak {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Chains maxima from the finest scale upwards, each line moving to the
+// nearest unclaimed maximum at the next scale within half that scale's
+// width, and fits the exponent of every line spanning wtmmMinLength or
+// more scales. Results are in candle order.
+func holderAlongLines(scales []float64, maxima [][]int, modulus [][]float64) []HolderExponent {
+	var out []HolderExponent
+	finish := func(path []int) {
+		if len(path) < wtmmMinLength {
+			return
+		}
+		logA := make([]float64, len(path))
+		logW := make([]float64, len(path))
+		sup := 0.0
+		for j, pos := range path {
+			sup = math.Max(sup, modulus[j][pos])
+			logA[j] = math.Log(scales[j])
+			logW[j] = math.Log(sup)
+		}
+		if h, r2, ok := LinearFit(logA, logW); ok {
+			out = append(out, HolderExponent{Index: path[0], Exponent: h, R2: r2, Scales: len(path)})
+		}
+	}
+
+	// Each line is its maxima positions from the finest scale up
+	var lines [][]int
+	for _, p := range maxima[0] {
+		lines = append(lines, []int{p})
+	}
+	for j := 1; j < len(scales); j++ {
+		tol := math.Max(1, scales[j]/2)
+		claimed := map[int]bool{}
+		var next [][]int
+		for _, path := range lines {
+			last := path[len(path)-1]
+			p := nearest(maxima[j], last)
+			if p < 0 || claimed[p] || math.Abs(float64(p-last)) > tol {
+				finish(path)
+				continue
+			}
+			claimed[p] = true
+			next = append(next, append(path, p))
+		}
+		lines = next
+	}
+	for _, path := range lines {
+		finish(path)
+	}
+
+	sort.Slice(out, func(a, b int) bool { return out[a].Index < out[b].Index })
+	return out
+}
+
+// The value of sorted closest to p, or -1 when sorted is empty.
+func nearest(sorted []int, p int) int {
+	i := sort.SearchInts(sorted, p)
+	switch {
+	case len(sorted) == 0:
+		return -1
+	case i == len(sorted):
+		return sorted[i-1]
+	case i > 0 && p-sorted[i-1] < sorted[i]-p:
+		return sorted[i-1]
+	}
+	return sorted[i]
+}
@@ -0,0 +1,57 @@
+package fractal
+
+import (
+	"math"
+	"testing"
+)
+
+// The longest maxima line converging within a few candles of t0, or false.
+func lineNear(exps []HolderExponent, t0 int) (HolderExponent, bool) {
+	var best HolderExponent
+	found := false
+	for _, e := range exps {
+		if d := e.Index - t0; d >= -3 && d <= 3 && (!found || e.Scales > best.Scales) {
+			best, found = e, true
+		}
+	}
+	return best, found
+}
+
+// A cusp |t - t0|^h in an otherwise smooth series is found at t0 with its
+// exponent h, and a jump with an exponent near 0.
+func TestWTMMCusp(t *testing.T) {
+	const n, t0 = 2048, 1024
+	tests := []struct {
+		name string
+		h    float64
+		f    func(x float64) float64
+	}{
+		{"cusp 0.3", 0.3, func(x float64) float64 { return -math.Pow(math.Abs(x), 0.3) }},
+		{"cusp 0.5", 0.5, func(x float64) float64 { return -math.Pow(math.Abs(x), 0.5) }},
+		{"cusp 0.7", 0.7, func(x float64) float64 { return -math.Pow(math.Abs(x), 0.7) }},
+		{"jump", 0, func(x float64) float64 {
+			if x < 0 {
+				return 0
+			}
+			return 1
+		}},
+	}
+	for _, tt := range tests {
+		series := make([]float64, n)
+		for i := range series {
+			series[i] = tt.f(float64(i-t0) / n)
+		}
+		line, ok := lineNear(WTMM(series), t0)
+		if !ok {
+			t.Errorf("%s: no maxima line near candle %d", tt.name, t0)
+			continue
+		}
+		if math.Abs(line.Exponent-tt.h) > 0.1 || line.Scales < wtmmMinLength {
+			t.Errorf("%s: exponent %v over %d scales at candle %d, want %v", tt.name, line.Exponent, line.Scales, line.Index, tt.h)
+		}
+	}
+
+	if exps := WTMM(make([]float64, 100)); exps != nil {
+		t.Errorf("short series: %v, want nil", exps)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		format  string
+		verbose bool
+		want    string // part of the output, empty for none
+		wantErr bool
+	}{
+		{"text", false, "level=WARN msg=careful", false},
+		{"text", true, "level=INFO msg=progress step=1", false},
+		{"json", true, `"msg":"progress","step":1`, false},
+		{"xml", false, "", true},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		logger, err := newLogger(&buf, tt.format, tt.verbose)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.format, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		logger.Info("progress", "step", 1)
+		logger.Warn("careful")
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("%s verbose %v: %q, want %q", tt.format, tt.verbose, buf.String(), tt.want)
+		}
+		if !tt.verbose && strings.Contains(buf.String(), "progress") {
+			t.Errorf("%s: info record %q logged without -v", tt.format, buf.String())
+		}
+	}
+}
+
+// A verbose JSON run logs one structured record per window and the
+// pipeline timing; a quiet run logs nothing.
+func TestRunLogsRecords(t *testing.T) {
+	_, stderr, err := runCommand(t, "-n", "600", "-v", "-log-format", "json", "-outdir", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(stderr), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("record %q: %v", line, err)
+		}
+		msg, _ := rec["msg"].(string)
+		counts[msg]++
+		if rec["level"] != slog.LevelInfo.String() {
+			t.Errorf("record %q: want level INFO", line)
+		}
+		var keys []string
+		switch msg {
+		case "window":
+			keys = []string{"window_start", "window_end", "dimension", "r2", "compute_us"}
+		case "analysis complete":
+			keys = []string{"duration_ms"}
+		}
+		for _, k := range keys {
+			if _, ok := rec[k]; !ok {
+				t.Errorf("%s record %q: no %s", msg, line, k)
+			}
+		}
+	}
+	for _, msg := range []string{"generating candles", "analysis complete", "output written"} {
+		if counts[msg] == 0 {
+			t.Errorf("no %q record in %v", msg, counts)
+		}
+	}
+	if counts["window"] < 2 {
+		t.Errorf("%d window records, want one per window", counts["window"])
+	}
+
+	_, stderr, err = runCommand(t, "-n", "600", "-outdir", t.TempDir())
+	if err != nil || stderr != "" {
+		t.Errorf("quiet run: error %v, stderr %q", err, stderr)
+	}
+}
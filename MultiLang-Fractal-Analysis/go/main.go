@@ -2,330 +2,1113 @@
 package main
 
 import (
-	"encoding/csv"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"math"
+	"log/slog"
 	"math/rand"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
+
+	"fractal-analysis/fractal"
 )
 
-type MarketCandle struct {
-	Timestamp  time.Time
-	Price      float64
-	Volume     float64
-	Returns    float64
-	Volatility float64
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Go: %v\n", err)
+		var usage usageError
+		if errors.As(err, &usage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
 }
 
-type FractalResult struct {
-	WindowStart int
-	WindowEnd   int
-	Dimension   float64
+// An invalid command line, which exits with status 2 rather than 1.
+type usageError struct{ error }
+
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Errorf(format, args...)}
 }
 
-func main() {
-	rand.Seed(42)
-	n := 10000
-	initial := 100.0
-
-	fmt.Println("Go: Generating 10,000 candles...")
-	data := generateSeries(n, initial)
-	computeReturnsAndVol(data, 30)
-
-	fmt.Println("Go: Computing fractal dimensions in parallel...")
-	
-	// Parallel computation of fractal dimensions for different windows
-	var wg sync.WaitGroup
-	results := make(chan FractalResult, 10)
-	
-	// Multiple window sizes for fractal analysis
-	windows := []struct{ start, size int }{
-		{0, n},           // Full series
-		{n - 1000, 1000}, // Last 1000
-		{n - 500, 500},   // Last 500
-		{0, 2000},        // First 2000
-		{2000, 2000},     // Middle 2000
-		{6000, 2000},     // Another 2000
-	}
-
-	for i, w := range windows {
-		wg.Add(1)
-		go func(idx int, start, size int) {
-			defer wg.Done()
-			if start+size > len(data) {
-				size = len(data) - start
-			}
-			prices := make([]float64, size)
-			for j := 0; j < size; j++ {
-				prices[j] = data[start+j].Price
-			}
-			fd := boxCountingFractalDimension(prices)
-			results <- FractalResult{start, start + size - 1, fd}
-		}(i, w.start, w.size)
-	}
-
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// Collect results
-	var fractalResults []FractalResult
-	for result := range results {
-		fractalResults = append(fractalResults, result)
-	}
-
-	// Create output directory
-	os.MkdirAll("out-go", 0755)
-
-	// Write CSV files
-	writeMarketCSV(data, "out-go/market_data.csv")
-	writeFractalCSV(fractalResults, "out-go/fractal_patterns.csv")
-	writeSummary(data, fractalResults, "out-go/session_summary.csv")
-
-	fmt.Printf("Go: Fractal analysis complete. Results:\n")
-	for _, r := range fractalResults {
-		windowName := "unknown"
-		switch r.WindowStart {
-		case 0:
-			if r.WindowEnd == n-1 {
-				windowName = "full"
-			} else {
-				windowName = "first2k"
-			}
-		case n - 1000:
-			windowName = "last1k"
-		case n - 500:
-			windowName = "last500"
-		case 2000:
-			windowName = "mid2k"
-		case 6000:
-			windowName = "late2k"
+// Runs the command, returning a usageError for an invalid command line.
+func run() error {
+	var inputs inputList
+	flag.Var(&inputs, "input", "read candles from this CSV or Parquet file instead of generating them; repeat or comma-separate to add series to correlation.csv")
+	timeFormat := flag.String("time-format", "", "Go layout of -input timestamps, or unix or unixms for epochs (default detect)")
+	limit := flag.Int("limit", 0, "read only the first N candles of each -input (0 reads all)")
+	tail := flag.Bool("tail", false, "with -limit, keep the last N candles instead of the first")
+	tickInterval := flag.Duration("tick-interval", 0, "read each -input as trades (timestamp, price, size) and aggregate them into candles of this interval, e.g. 1m")
+	gapsFlag := flag.String("gaps", "ignore", "timestamp gaps: ignore, flag (write gaps.csv) or fill (also pad them with NaN placeholder candles on a uniform grid)")
+	gapInterval := flag.Duration("gap-interval", 0, "regular candle spacing -gaps compares against (0 uses the median spacing)")
+	tickEmpty := flag.String("tick-empty", string(fractal.SkipEmpty), "intervals without trades under -tick-interval: skip, or ffill to repeat the last close")
+	count := flag.Int("n", 10000, "number of candles to generate")
+	seed := flag.Int64("seed", 42, "random seed for generation")
+	volWindow := flag.Int("vol-window", fractal.DefaultVolWindow, "rolling volatility window in candles")
+	resample := flag.Int("resample", 1, "aggregate every N candles into one bar before analysis")
+	returns := flag.String("returns", "simple", "return definition: simple or log")
+	initial := flag.Float64("initial-price", 100.0, "starting price for generated series")
+	regimeTrending := flag.Float64("regime-trending", 0, "rolling dimensions below this are labelled trending (0 uses their lower tercile)")
+	regimeChoppy := flag.Float64("regime-choppy", 0, "rolling dimensions above this are labelled choppy (0 uses their upper tercile)")
+	cpPenalty := flag.Float64("cp-penalty", fractal.DefaultChangePenalty, "cost of each change point in the rolling dimension, in units of log n; higher finds fewer")
+	bandsWindow := flag.Int("bands-window", 20, "Bollinger band window in candles")
+	bandsK := flag.Float64("bands-k", 2, "Bollinger band width in price standard deviations")
+	atrWindow := flag.Int("atr-window", fractal.DefaultATRWindow, "Wilder smoothing period of the average true range in atr.csv")
+	rsiPeriod := flag.Int("rsi-period", fractal.DefaultRSIPeriod, "Wilder RSI period for divergences.csv")
+	swingMove := flag.Float64("swing-move", fractal.DefaultSwingMove, "smallest reversal, as a fraction of price, that starts a new swing for fib_levels.csv")
+	macdFast := flag.Int("macd-fast", fractal.DefaultMACDFast, "fast EMA period of the MACD in macd.csv")
+	macdSlow := flag.Int("macd-slow", fractal.DefaultMACDSlow, "slow EMA period of the MACD")
+	macdSignal := flag.Int("macd-signal", fractal.DefaultMACDSignal, "EMA period of the MACD signal line")
+	acfLags := flag.Int("acf-lags", 50, "largest lag of the returns autocorrelation in acf.csv")
+	rollWindow := flag.Int("rolling-window", 500, "window size for the rolling fractal dimension")
+	rollStep := flag.Int("rolling-step", 100, "step between rolling fractal dimension windows")
+	windowSpec := flag.String("windows", "", "analysis windows as comma-separated start:size pairs (default the six named windows)")
+	fdVolWindow := flag.Int("fdvol-window", fractal.DefaultFDVolWindow, "rolling windows per dimension-volatility correlation in fd_vol_corr.csv")
+	scalingMin := flag.Int("scaling-min", 64, "smallest trailing window of the dimension scaling sweep in scaling.csv")
+	scalingMax := flag.Int("scaling-max", 0, "largest trailing window of the scaling sweep (default the whole series)")
+	scalingPoints := flag.Int("scaling-points", fractal.DefaultScalingPoints, "number of geometrically spaced window sizes in the scaling sweep")
+	generator := flag.String("generator", "fractal", "price generator: fractal, gbm, fbm, ou or merton")
+	drift := flag.Float64("drift", 0.00005, "per-candle drift for the gbm and merton generators")
+	vol := flag.Float64("vol", 0.015, "per-candle volatility for the gbm, fbm, ou and merton generators")
+	jumpIntensity := flag.Float64("jump-intensity", 0.01, "mean jumps per candle for the merton generator")
+	jumpMean := flag.Float64("jump-mean", -0.02, "mean log jump size for the merton generator")
+	jumpStd := flag.Float64("jump-std", 0.05, "standard deviation of log jump size for the merton generator")
+	ouTheta := flag.Float64("ou-theta", 0.05, "mean-reversion rate per candle for the ou generator")
+	ouMu := flag.Float64("ou-mu", 0, "long-run price level for the ou generator (default -initial-price)")
+	trimWarmUp := flag.Bool("trim-warmup", false, "leave the leading zero-volatility warm-up candles out of volatility statistics and outputs")
+	volMethod := flag.String("vol-method", "rolling", "volatility estimator: rolling, ewma or weighted")
+	lambda := flag.Float64("ewma-lambda", fractal.DefaultEWMALambda, "decay factor for -vol-method ewma")
+	halfLife := flag.Float64("halflife", fractal.DefaultVolHalfLife, "half-life in candles of the -vol-method weighted weights over each -vol-window")
+	boxSizes := flag.String("box-sizes", "", "box-counting sizes: \"auto\" for log spacing or a comma-separated list")
+	boxMinPoints := flag.Float64("box-min-points", fractal.DefaultMinPointsPerBox, "fit only box sizes whose occupied boxes average at least this many points, widened to at least four")
+	boxOverlap := flag.Bool("box-overlap", false, "slide box-counting columns one candle at a time instead of tiling them")
+	boxImpl := flag.String("box-impl", string(fractal.BoxMap), "disjoint box-counting bookkeeping: map, or grid for large windows")
+	normalize := flag.String("normalize", "minmax", "box-counting price scaling: minmax, zscore or robust (median/IQR, less swayed by spikes)")
+	clipQuantile := flag.Float64("normalize-clip", fractal.DefaultClipQuantile, "tail quantile -normalize robust clips at, between 0 and 0.5")
+	export := flag.String("export", "", "also write the settings, summary, window results and candles to this one JSON document in -outdir")
+	exportCandles := flag.Bool("export-candles", true, "include the candle array in the -export document; false keeps it small")
+	compareInput := flag.String("compare-input", "", "also analyse this CSV or Parquet file over the same windows, taken at the same fractions of its length, and write compare.csv")
+	compareEstimators := flag.Bool("compare-estimators", false, "run every registered method on each window and write estimator_comparison.csv with their agreement")
+	method := flag.String("method", "", "extra dimension methods per window, comma-separated from: "+strings.Join(fractal.EstimatorNames(), ", "))
+	slope := flag.String("slope", "ols", "box-counting log-log regression: ols or theilsen")
+	seriesName := flag.String("series", "price", "series to measure the dimension on: price, returns or volatility")
+	periodsPerYear := flag.Float64("periods-per-year", fractal.HourlyPeriodsPerYear, "candles per year for annualized ratios and volatility")
+	riskFree := flag.Float64("risk-free", 0, "annual risk-free rate for Sharpe and Sortino ratios")
+	bootstrap := flag.Int("bootstrap", 0, "block-bootstrap replicates per window for a dimension confidence interval (0 disables)")
+	blockSize := flag.Int("block-size", 0, "block length for -bootstrap (default cube root of the window)")
+	surrogates := flag.Int("surrogates", 0, "phase-randomized surrogates per window for a nonlinearity p-value (0 disables)")
+	highLow := flag.Bool("high-low", false, "box-count each bar's high-low range instead of the close")
+	detrendName := flag.String("detrend", "none", "trend removed from each window before its dimension is estimated: none, linear or log (log price, -series price only)")
+	watch := flag.Bool("watch", false, "follow the -input CSV as rows are appended and print its -rolling-window dimension on each new bar")
+	serve := flag.String("serve", "", "serve POST /fractal on this address (e.g. :8080) instead of running a batch")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of concurrent window workers")
+	toStdout := flag.Bool("stdout", false, "print the window results and summary as tables instead of writing -outdir")
+	outDir := flag.String("outdir", "out-go", "directory the output files are written to, created if needed")
+	gzipOut := flag.Bool("gzip", false, "gzip-compress CSV outputs, adding .gz to their names")
+	format := flag.String("format", "csv", "output formats, comma-separated: csv, json, parquet, or both (csv,json)")
+	hurst := flag.Float64("hurst", 0.7, "target Hurst exponent for the fbm generator, in (0,1)")
+	repeat := flag.Int("repeat", 1, "run the whole pipeline this many times, writing outputs only on the first, and print per-phase timing percentiles")
+	summaryOnly := flag.Bool("summary-only", false, "scan every -input for its headline metrics, write one row per file to scan.csv and exit")
+	seedSweep := flag.Int("seed-sweep", 0, "generate and analyse this many consecutive seeds from -seed, write seed_sweep.csv and exit")
+	benchmark := flag.Bool("benchmark", false, "time each estimator at several series lengths and exit")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile of the run, across every -repeat pass, to this file")
+	memProfile := flag.String("memprofile", "", "write a heap profile taken after the last pass to this file")
+	verbose := flag.Bool("v", false, "log progress and per-window results to stderr")
+	logFormat := flag.String("log-format", "text", "log record format: text or json")
+	dryRun := flag.Bool("dry-run", false, "run the analysis and log the files and row counts it would write, without writing them")
+	replay := flag.String("replay", "", "rerun with the flags and timestamps recorded in this run.json")
+	configFile := flag.String("config", "", "load run settings from this JSON file; command-line flags override it")
+	flag.Parse()
+
+	var replayed *runRecord
+	if *replay != "" {
+		rec, err := loadRunRecord(*replay)
+		if err == nil {
+			err = applyRunRecord(rec)
+		}
+		if err != nil {
+			return usagef("-replay: %w", err)
+		}
+		replayed = &rec
+	}
+	if *configFile != "" {
+		cfg, err := loadConfig(*configFile)
+		if err == nil {
+			err = applyConfig(cfg)
+		}
+		if err != nil {
+			return usagef("-config: %w", err)
 		}
-		fmt.Printf("Go: FD (%s): %.3f\n", windowName, r.Dimension)
 	}
-	fmt.Println("Go: CSV written to ./out-go/")
-}
 
-func generateSeries(n int, initial float64) []MarketCandle {
-	data := make([]MarketCandle, n)
-	price := initial
-	start := time.Now().Add(-time.Duration(n) * time.Hour)
+	logger, err := newLogger(os.Stderr, *logFormat, *verbose || *dryRun)
+	if err != nil {
+		return usagef("-log-format: %w", err)
+	}
+	slog.SetDefault(logger)
 
-	for i := 0; i < n; i++ {
-		// Multi-octave fractal noise
-		noise := 0.0
-		amp, freq := 1.0, 1.0
-		for o := 0; o < 5; o++ {
-			phase := math.Mod(float64(i)*freq*0.07, 2*math.Pi)
-			sine := math.Sin(phase) + 0.5*math.Sin(phase*1.618)
-			noise += amp * sine * gaussian() * 0.08
-			amp *= 0.55
-			freq *= 2
-		}
+	n := *count
+	if *benchmark && !flagSet("n") {
+		n = benchSizes[len(benchSizes)-1]
+	}
+	if n <= 0 {
+		return usagef("-n must be positive, got %d", n)
+	}
+	if *volWindow < 2 || *volWindow >= n {
+		return usagef("-vol-window must be at least 2 and less than -n (%d), got %d", n, *volWindow)
+	}
+
+	if *regimeTrending < 0 || *regimeChoppy < 0 {
+		return usagef("-regime-trending and -regime-choppy must not be negative")
+	}
+	if *regimeChoppy > 0 && *regimeTrending > *regimeChoppy {
+		return usagef("-regime-trending (%g) must not exceed -regime-choppy (%g)", *regimeTrending, *regimeChoppy)
+	}
+	if !(*cpPenalty > 0) {
+		return usagef("-cp-penalty must be positive, got %g", *cpPenalty)
+	}
 
-		drift := 0.00005
-		vol := 0.015
-		rnd := gaussian()
-		dP := drift + vol*(rnd+0.3*noise)
-		price *= (1 + dP)
+	if *bandsWindow < 1 {
+		return usagef("-bands-window must be positive, got %d", *bandsWindow)
+	}
 
-		volume := 1000 + math.Abs(rnd)*400
+	if *atrWindow < 1 {
+		return usagef("-atr-window must be positive, got %d", *atrWindow)
+	}
 
-		data[i] = MarketCandle{
-			Timestamp: start.Add(time.Duration(i) * time.Hour),
-			Price:     price,
-			Volume:    volume,
-		}
+	if *rsiPeriod < 1 {
+		return usagef("-rsi-period must be positive, got %d", *rsiPeriod)
 	}
-	return data
-}
 
-func computeReturnsAndVol(data []MarketCandle, window int) {
-	// Compute returns
-	for i := 1; i < len(data); i++ {
-		data[i].Returns = (data[i].Price - data[i-1].Price) / data[i-1].Price
+	if *macdFast < 1 || *macdSignal < 1 || *macdSlow <= *macdFast {
+		return usagef("-macd-fast and -macd-signal must be positive and -macd-slow longer than -macd-fast")
 	}
 
-	// Compute rolling volatility
-	for i := 0; i < len(data); i++ {
-		if i < window {
-			data[i].Volatility = 0
-			continue
+	if *acfLags < 0 {
+		return usagef("-acf-lags must not be negative, got %d", *acfLags)
+	}
+
+	if *resample < 1 {
+		return usagef("-resample must be at least 1, got %d", *resample)
+	}
+
+	if *rollWindow <= 0 || *rollStep <= 0 {
+		return usagef("-rolling-window and -rolling-step must be positive")
+	}
+	if *fdVolWindow < 2 {
+		return usagef("-fdvol-window must be at least 2, got %d", *fdVolWindow)
+	}
+
+	if *scalingMin < 2 || *scalingMax < 0 || (*scalingMax > 0 && *scalingMax < *scalingMin) || *scalingPoints < 1 {
+		return usagef("-scaling-min must be at least 2, -scaling-max 0 or at least -scaling-min, and -scaling-points positive")
+	}
+
+	switch *generator {
+	case "fractal", "gbm":
+	case "fbm":
+		if *hurst <= 0 || *hurst >= 1 {
+			return usagef("-hurst must be in (0,1), got %g", *hurst)
+		}
+	case "merton":
+		if *jumpIntensity < 0 || *jumpStd < 0 {
+			return usagef("-jump-intensity and -jump-std must not be negative")
+		}
+	case "ou":
+		if *ouTheta <= 0 {
+			return usagef("-ou-theta must be positive, got %g", *ouTheta)
 		}
+		if *ouMu == 0 {
+			*ouMu = *initial
+		}
+		if *ouMu < 0 {
+			return usagef("-ou-mu must be positive, got %g", *ouMu)
+		}
+	default:
+		return usagef("unknown -generator %q (want fractal, gbm, fbm, ou or merton)", *generator)
+	}
 
-		mean := 0.0
-		for j := i - window; j < i; j++ {
-			mean += data[j].Returns
+	formats, err := parseFormats(*format)
+	if err != nil {
+		return usagef("-format: %w", err)
+	}
+
+	switch *volMethod {
+	case "rolling", "ewma", "weighted":
+	default:
+		return usagef("unknown -vol-method %q (want rolling, ewma or weighted)", *volMethod)
+	}
+	if *lambda <= 0 || *lambda >= 1 {
+		return usagef("-ewma-lambda must be in (0,1), got %g", *lambda)
+	}
+	if *swingMove < 0 {
+		return usagef("-swing-move must not be negative, got %g", *swingMove)
+	}
+	if !(*halfLife > 0) {
+		return usagef("-halflife must be positive, got %g", *halfLife)
+	}
+
+	if *periodsPerYear <= 0 {
+		return usagef("-periods-per-year must be positive, got %g", *periodsPerYear)
+	}
+	if *bootstrap < 0 || *blockSize < 0 || *surrogates < 0 {
+		return usagef("-bootstrap, -block-size and -surrogates must not be negative")
+	}
+	if *seedSweep < 0 {
+		return usagef("-seed-sweep must not be negative, got %d", *seedSweep)
+	}
+	if *seedSweep > 0 && len(inputs) > 0 {
+		return usagef("-seed-sweep generates its series and cannot be combined with -input")
+	}
+	if *repeat < 1 {
+		return usagef("-repeat must be at least 1, got %d", *repeat)
+	}
+	if *repeat > 1 && (*seedSweep > 0 || *summaryOnly || *benchmark || *serve != "" || *watch) {
+		return usagef("-repeat cannot be combined with -seed-sweep, -summary-only, -benchmark, -serve or -watch")
+	}
+	if *summaryOnly && len(inputs) == 0 {
+		return usagef("-summary-only scans -input files; give at least one")
+	}
+	if *summaryOnly && (*seedSweep > 0 || *compareInput != "") {
+		return usagef("-summary-only cannot be combined with -seed-sweep or -compare-input")
+	}
+	if *seedSweep > 0 && *compareInput != "" {
+		return usagef("-seed-sweep cannot be combined with -compare-input")
+	}
+	if *limit < 0 {
+		return usagef("-limit must not be negative, got %d", *limit)
+	}
+	if *tail && *limit == 0 {
+		return usagef("-tail needs -limit")
+	}
+	if *tickInterval < 0 {
+		return usagef("-tick-interval must not be negative, got %v", *tickInterval)
+	}
+	if *tickInterval > 0 && *limit > 0 {
+		return usagef("-limit cannot be combined with -tick-interval")
+	}
+	emptyBuckets, err := fractal.ParseEmptyBuckets(*tickEmpty)
+	if err != nil {
+		return usagef("-tick-empty: %w", err)
+	}
+	gapMode, err := fractal.ParseGapMode(*gapsFlag)
+	if err != nil {
+		return usagef("-gaps: %w", err)
+	}
+	if *gapInterval < 0 {
+		return usagef("-gap-interval must not be negative, got %v", *gapInterval)
+	}
+	if *workers <= 0 {
+		return usagef("-workers must be positive, got %d", *workers)
+	}
+	writesFiles := *serve == "" && !*benchmark && !*dryRun && (!*toStdout || *seedSweep > 0)
+	if writesFiles {
+		if err := checkOutputDir(*outDir); err != nil {
+			return usagef("-outdir: %w", err)
 		}
-		mean /= float64(window)
+	}
+
+	series, err := fractal.ParseSeries(*seriesName)
+	if err != nil {
+		return usagef("-series: %w", err)
+	}
+
+	detrend, err := fractal.ParseDetrendMethod(*detrendName)
+	if err != nil {
+		return usagef("-detrend: %w", err)
+	}
+	if detrend == fractal.DetrendLog && series != fractal.SeriesPrice {
+		return usagef("-detrend log needs -series price, got %s", series)
+	}
+
+	returnKind, err := fractal.ParseReturnKind(*returns)
+	if err != nil {
+		return usagef("-returns: %w", err)
+	}
+
+	counter, err := parseBoxSizes(*boxSizes)
+	if err != nil {
+		return usagef("-box-sizes: %w", err)
+	}
+	counter.Overlap = *boxOverlap
+	if !(*boxMinPoints >= 0) {
+		return usagef("-box-min-points must not be negative, got %g", *boxMinPoints)
+	}
+	counter.MinPointsPerBox = *boxMinPoints
+	if counter.Impl, err = fractal.ParseBoxImpl(*boxImpl); err != nil {
+		return usagef("-box-impl: %w", err)
+	}
+	if counter.Normalize, err = fractal.ParseNormalization(*normalize); err != nil {
+		return usagef("-normalize: %w", err)
+	}
+	if !(*clipQuantile > 0 && *clipQuantile < 0.5) {
+		return usagef("-normalize-clip must be between 0 and 0.5, got %g", *clipQuantile)
+	}
+	counter.ClipQuantile = *clipQuantile
+	if counter.Slope, err = fractal.ParseSlopeMethod(*slope); err != nil {
+		return usagef("-slope: %w", err)
+	}
 
-		ss := 0.0
-		for j := i - window; j < i; j++ {
-			dev := data[j].Returns - mean
-			ss += dev * dev
+	methods := *method
+	if *compareEstimators {
+		methods = strings.Join(fractal.EstimatorNames(), ",")
+	}
+	estimators, err := fractal.ParseEstimators(methods)
+	if err != nil {
+		return usagef("-method: %w", err)
+	}
+	// The registered box estimator has default settings; use the flags'
+	// counter so the -box-* settings apply
+	for i, e := range estimators {
+		if e.Name() == counter.Name() {
+			estimators[i] = counter
 		}
-		data[i].Volatility = math.Sqrt(ss / float64(window-1))
 	}
-}
 
-func boxCountingFractalDimension(prices []float64) float64 {
-	if len(prices) < 4 {
-		return 1.0
+	if *serve != "" {
+		slog.Info("serving", "addr", *serve, "path", "/fractal")
+		return http.ListenAndServe(*serve, newServeMux(counter))
 	}
 
-	// Normalize prices
-	min, max := prices[0], prices[0]
-	for _, p := range prices {
-		if p < min {
-			min = p
+	if *watch {
+		if len(inputs) != 1 {
+			return usagef("-watch follows exactly one -input")
 		}
-		if p > max {
-			max = p
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		slog.Info("watching", "input", inputs[0], "window", *rollWindow)
+		return watchCSV(ctx, inputs[0], *timeFormat, *rollWindow, counter, os.Stdout)
+	}
+
+	// Generation is shared by the main run and every seed of -seed-sweep
+	generate := func(rng *rand.Rand) []fractal.MarketCandle {
+		var data []fractal.MarketCandle
+		switch *generator {
+		case "gbm":
+			data = fractal.GenerateGBM(rng, n, *initial, *drift, *vol)
+		case "fbm":
+			data = fractal.GenerateFBMSeries(rng, n, *initial, *vol, *hurst)
+		case "ou":
+			data = fractal.GenerateOU(rng, n, *initial, *ouTheta, *ouMu, *vol)
+		case "merton":
+			data = fractal.GenerateJumpDiffusion(rng, n, *initial, *drift, *vol, *jumpIntensity, *jumpMean, *jumpStd)
+		default:
+			data = fractal.GenerateSeries(rng, n, *initial)
 		}
+		fractal.ComputeReturns(data, returnKind)
+		fractal.ComputeRollingVolatility(data, *volWindow)
+		return data
 	}
-	
-	rang := max - min
-	if rang <= 0 {
-		return 1.0
+	// As is the preparation that follows loading or generating
+	prepare := func(data []fractal.MarketCandle) []fractal.MarketCandle {
+		if *resample > 1 {
+			data = fractal.Resample(data, *resample)
+			fractal.ComputeReturns(data, returnKind)
+			fractal.ComputeRollingVolatility(data, *volWindow)
+		}
+		switch *volMethod {
+		case "ewma":
+			fractal.ComputeEWMAVolatility(data, *lambda)
+		case "weighted":
+			fractal.ComputeWeightedVolatility(data, *volWindow, *halfLife)
+		}
+		return data
+	}
+
+	load := func(path string) ([]fractal.MarketCandle, error) {
+		slog.Info("reading candles", "input", path)
+		if *tickInterval > 0 {
+			return readTickCandles(path, *timeFormat, *tickInterval, emptyBuckets)
+		}
+		// -vol-method is applied on top by prepare
+		return readCandles(path, fractal.MarketCSVReader{TimeFormat: *timeFormat, Limit: *limit, Tail: *tail, VolWindow: *volWindow})
 	}
 
-	norm := make([]float64, len(prices))
-	for i, p := range prices {
-		norm[i] = (p - min) / rang
+	if *summaryOnly {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		slog.Info("scanning inputs", "inputs", len(inputs), "workers", *workers)
+		scanner := fractal.Analyzer{Counter: counter, Workers: *workers, Series: series, HighLow: *highLow, Detrend: detrend}
+		scan, err := scanner.Scan(ctx, inputs, func(path string) ([]fractal.MarketCandle, error) {
+			data, err := load(path)
+			if err != nil {
+				return nil, err
+			}
+			if flagSet("vol-window") || flagSet("returns") {
+				fractal.ComputeReturns(data, returnKind)
+				fractal.ComputeRollingVolatility(data, *volWindow)
+			}
+			if err := fractal.Validate(data); err != nil {
+				return nil, fmt.Errorf("invalid data: %w", err)
+			}
+			return prepare(data), nil
+		})
+		if err != nil {
+			return fmt.Errorf("scan stopped: %w", err)
+		}
+		for _, r := range scan {
+			if r.Err != nil {
+				slog.Warn("input not scanned", "input", r.Input, "error", r.Err)
+			}
+		}
+		scanOutput := output{"scan.csv", len(scan), func(name string) error {
+			return fractal.WriteScanCSV(scan, name)
+		}}
+		if err := writeOutputs(*outDir, []output{scanOutput}, *dryRun); err != nil {
+			return err
+		}
+		if !*dryRun {
+			slog.Info("output written", "dir", *outDir, "file", "scan.csv")
+		}
+		return nil
 	}
 
-	boxSizes := []int{1, 2, 3, 4, 5, 8, 10, 16, 20, 25, 32}
-	var logInv, logCount []float64
+	// One pass of the pipeline from reading or generating the candles to
+	// writing the outputs. -repeat runs it again, timing each phase, and
+	// writes only on the first pass.
+	timings := map[string][]time.Duration{}
+	var fingerprints []string
+	pipeline := func(pass int) error {
+		rng := fractal.NewRand(*seed, 0)
+		phaseStart := time.Now()
 
-	for _, bs := range boxSizes {
-		if bs >= len(prices)/2 {
-			break
+		var data []fractal.MarketCandle
+		var correlations [][]float64
+		if len(inputs) > 0 {
+			var basket [][]fractal.MarketCandle
+			for i, path := range inputs {
+				candles, err := load(path)
+				if err != nil {
+					return err
+				}
+				if i == 0 {
+					data = candles
+				}
+				basket = append(basket, candles)
+			}
+			// Correlations use the inputs as read, before any resampling
+			if len(basket) > 1 {
+				correlations = fractal.ReturnCorrelations(basket, returnKind)
+				slog.Info("correlated inputs", "series", len(basket))
+			}
+			if flagSet("vol-window") || flagSet("returns") {
+				fractal.ComputeReturns(data, returnKind)
+				fractal.ComputeRollingVolatility(data, *volWindow)
+			}
+			n = len(data)
+		} else {
+			slog.Info("generating candles", "n", n, "generator", *generator)
+			data = generate(rng)
+			if replayed != nil && replayed.Start != nil {
+				shiftTimestamps(data, *replayed.Start)
+				fractal.ComputeReturns(data, returnKind)
+				fractal.ComputeRollingVolatility(data, *volWindow)
+			}
 		}
 
-		boxes := make(map[string]bool)
-		for i := 0; i < len(norm)-1; i++ {
-			x := i / bs
-			y := int(norm[i] * float64(bs))
-			key := fmt.Sprintf("%d,%d", x, y)
-			boxes[key] = true
+		record := newRunRecord(*seed, n, *generator)
+		if len(inputs) == 0 {
+			record.Start = &data[0].Timestamp
 		}
 
-		if len(boxes) > 0 {
-			logInv = append(logInv, math.Log(1.0/float64(bs)))
-			logCount = append(logCount, math.Log(float64(len(boxes))))
+		if err := fractal.Validate(data); err != nil {
+			return fmt.Errorf("invalid data: %w", err)
 		}
-	}
 
-	if len(logInv) < 3 {
-		return 1.0
-	}
+		data = prepare(data)
+		if *resample > 1 {
+			n = len(data)
+			slog.Info("resampled", "bars", n, "factor", *resample)
+		}
 
-	return linearSlope(logInv, logCount)
-}
+		var gaps []fractal.Gap
+		if gapMode != fractal.GapsIgnore {
+			gaps = fractal.DetectGaps(data, *gapInterval)
+			slog.Info("timestamp gaps", "gaps", len(gaps))
+			if gapMode == fractal.GapsFill {
+				data = fractal.FillGaps(data, *gapInterval)
+				n = len(data)
+			}
+		}
+
+		var compareData []fractal.MarketCandle
+		if *compareInput != "" {
+			if compareData, err = load(*compareInput); err != nil {
+				return err
+			}
+			if flagSet("vol-window") || flagSet("returns") {
+				fractal.ComputeReturns(compareData, returnKind)
+				fractal.ComputeRollingVolatility(compareData, *volWindow)
+			}
+			if err := fractal.Validate(compareData); err != nil {
+				return fmt.Errorf("invalid -compare-input data: %w", err)
+			}
+			compareData = prepare(compareData)
+			if gapMode == fractal.GapsFill {
+				compareData = fractal.FillGaps(compareData, *gapInterval)
+			}
+		}
 
-func linearSlope(x, y []float64) float64 {
-	n := float64(len(x))
-	var sx, sy, sxx, sxy float64
+		timings["generate"] = append(timings["generate"], time.Since(phaseStart))
 
-	for i := 0; i < len(x); i++ {
-		sx += x[i]
-		sy += y[i]
-		sxx += x[i] * x[i]
-		sxy += x[i] * y[i]
-	}
+		if *benchmark {
+			runBenchmarks(data, counter)
+			return nil
+		}
+
+		windows := fixedWindows(n)
+		if *windowSpec != "" {
+			if windows, err = parseWindows(*windowSpec, n); err != nil {
+				return usagef("-windows: %w", err)
+			}
+		}
+		slog.Info("analysis windows", "windows", len(windows), "candles", n)
+
+		// Ctrl-C abandons the run before anything is written
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if *seedSweep > 0 {
+			seeds := make([]int64, *seedSweep)
+			for k := range seeds {
+				seeds[k] = *seed + int64(k)
+			}
+			slog.Info("sweeping seeds", "from", *seed, "seeds", len(seeds), "workers", *workers)
+			sweeper := fractal.Analyzer{Counter: counter, Workers: *workers, Series: series, HighLow: *highLow, Detrend: detrend}
+			runs, err := sweeper.SeedSweep(ctx, seeds, func(rng *rand.Rand) []fractal.MarketCandle {
+				return prepare(generate(rng))
+			}, windows)
+			if err != nil {
+				return fmt.Errorf("seed sweep stopped: %w", err)
+			}
+			stats := fractal.SummarizeSweep(runs)
+			for i := range stats {
+				stats[i].Name = windowName(fractal.FractalResult{WindowStart: stats[i].WindowStart, WindowEnd: stats[i].WindowEnd}, n)
+			}
+			sweepOutput := output{"seed_sweep.csv", len(stats), func(name string) error {
+				return fractal.WriteSeedSweepCSV(stats, name)
+			}}
+			if err := writeOutputs(*outDir, []output{sweepOutput}, *dryRun); err != nil {
+				return err
+			}
+			if !*dryRun {
+				slog.Info("output written", "dir", *outDir, "file", "seed_sweep.csv")
+			}
+			return nil
+		}
+
+		slog.Info("analysing windows", "windows", len(windows), "workers", *workers)
+		analysisStart := time.Now()
+		analyzer := fractal.Analyzer{
+			Counter:    counter,
+			Workers:    *workers,
+			Series:     series,
+			HighLow:    *highLow,
+			Bootstrap:  *bootstrap,
+			BlockSize:  *blockSize,
+			Surrogates: *surrogates,
+			Seed:       *seed,
+			Estimators: estimators,
+			Detrend:    detrend,
+		}
+		fractalResults, err := analyzer.Run(ctx, data, windows)
+		if err != nil {
+			return fmt.Errorf("analysis stopped: %w", err)
+		}
+
+		var profiles []fractal.ProfileDiff
+		if compareData != nil {
+			// Only the dimension and Hurst exponent are compared
+			profiler := fractal.Analyzer{Counter: counter, Workers: *workers, Series: series, HighLow: *highLow, Detrend: detrend}
+			if profiles, err = profiler.CompareProfiles(ctx, data, compareData, windows); err != nil {
+				return fmt.Errorf("analysis stopped: %w", err)
+			}
+			slog.Info("compared inputs", "windows", len(profiles), "candles", len(compareData))
+		}
+
+		rolling, err := analyzer.RunRolling(ctx, series.Values(data), *rollWindow, *rollStep)
+		if err != nil {
+			return fmt.Errorf("analysis stopped: %w", err)
+		}
+		maxWindow := n
+		if *scalingMax > 0 && *scalingMax < n {
+			maxWindow = *scalingMax
+		}
+		scaling, err := analyzer.RunScaling(ctx, series.Values(data), fractal.ScalingWindowSizes(*scalingMin, maxWindow, *scalingPoints))
+		if err != nil {
+			return fmt.Errorf("analysis stopped: %w", err)
+		}
+
+		rollingHurst := fractal.RollingHurst(fractal.SeriesReturns.Values(data), *rollWindow, *rollStep)
+		rollingDims := make([]float64, len(rolling))
+		for i, r := range rolling {
+			rollingDims[i] = r.Dimension
+		}
+		warmUp := 0
+		if *trimWarmUp {
+			warmUp = fractal.VolatilityWarmUp(data)
+		}
+		regimes := fractal.RegimeTerciles(rolling)
+		if *regimeTrending > 0 {
+			regimes.TrendingBelow = *regimeTrending
+		}
+		if *regimeChoppy > 0 {
+			regimes.ChoppyAbove = *regimeChoppy
+		}
+		if regimes.TrendingBelow > regimes.ChoppyAbove {
+			return fmt.Errorf("regime thresholds: trending below %g exceeds choppy above %g; set both -regime-trending and -regime-choppy", regimes.TrendingBelow, regimes.ChoppyAbove)
+		}
+		windowVols := fractal.WindowVolatility(data, rolling, warmUp)
+		fdVolCorr := fractal.RollingCorrelation(rollingDims, windowVols, *fdVolWindow)
+		changePoints := fractal.ChangePoints(rollingDims, *cpPenalty)
+		slog.Info("analysis complete", "duration_ms", time.Since(analysisStart).Milliseconds())
+
+		bullish, bearish := fractal.DetectWilliamsFractals(data)
+		rsi := fractal.RSI(fractal.SeriesPrice.Values(data), *rsiPeriod)
+		divergences := fractal.RSIDivergences(data, rsi, bullish, bearish)
+		swings := fractal.SwingPoints(data, bullish, bearish, *swingMove)
+		fibRows := 0
+		if start, end, ok := fractal.LastSwing(swings); ok && fractal.SwingLevels(start, end) != nil {
+			fibRows = 2 + len(fractal.FibonacciRatios)
+		}
+		renyi := counter.RenyiDimensions(series.Values(data), fractal.DefaultRenyiQs)
+		holder := fractal.WTMM(series.Values(data))
+		hq := fractal.MFDFA(fractal.SeriesReturns.Values(data), fractal.DefaultMFDFAQs, 1)
 
-	d := n*sxx - sx*sx
-	if math.Abs(d) < 1e-12 {
-		return 1.0
+		// The first candle has no return
+		acf := fractal.Autocorrelation(fractal.SeriesReturns.Values(data[1:]), *acfLags)
+
+		prices := fractal.SeriesPrice.Values(data)
+		freqs, power := fractal.PowerSpectrum(prices)
+		beta, spectralDim := fractal.SpectralExponent(prices)
+		mid, upper, lower := fractal.BollingerBands(data, *bandsWindow, *bandsK)
+		atr := fractal.AverageTrueRange(data, *atrWindow)
+		macd, macdSig, macdHist := fractal.MACD(prices, *macdFast, *macdSlow, *macdSignal)
+
+		runMetrics := []fractal.Metric{
+			{Name: "Multifractality", Value: fractal.Multifractality(hq)},
+			{Name: "SpectralBeta", Value: beta},
+			{Name: "SpectralDimension", Value: spectralDim},
+			{Name: "FDVolCorrelation", Value: fractal.Pearson(rollingDims, windowVols)},
+			{Name: "Series", Value: string(series)},
+		}
+		runMetrics = append(runMetrics, fractal.RegimeCounts(rolling, regimes)...)
+		summaryCfg := fractal.SummaryConfig{PeriodsPerYear: *periodsPerYear, RiskFree: *riskFree, TrimWarmUp: *trimWarmUp}
+		summary := summaryCfg.Summarize(data, fractalResults, runMetrics...)
+		timings["analyse"] = append(timings["analyse"], time.Since(analysisStart))
+		fingerprints = append(fingerprints, fingerprint(fractalResults, summary))
+
+		writeStart := time.Now()
+		if pass > 0 {
+			// Later passes only repeat the analysis
+		} else if *toStdout {
+			if err := printTables(os.Stdout, fractalResults, summary, n); err != nil {
+				return err
+			}
+		} else {
+			csvExt := ""
+			if *gzipOut {
+				csvExt = ".gz"
+			}
+			var outputs []output
+			if formats.marketCSV() {
+				outputs = append(outputs, output{"market_data.csv" + csvExt, len(data), func(name string) error {
+					return fractal.WriteMarketCSV(data, name)
+				}})
+			}
+			if formats.parquet {
+				outputs = append(outputs, output{"market_data.parquet", len(data), func(name string) error {
+					return fractal.WriteMarketParquet(data, name)
+				}})
+			}
+			if formats.resultsCSV() {
+				outputs = append(outputs,
+					output{"fractal_patterns.csv" + csvExt, len(fractalResults), func(name string) error {
+						return fractal.WriteFractalCSV(fractalResults, name)
+					}},
+					output{"session_summary.csv" + csvExt, len(summary), func(name string) error {
+						return fractal.WriteMetricsCSV(summary, name)
+					}},
+				)
+			}
+			if formats.json {
+				outputs = append(outputs, output{"fractal_patterns.json", len(fractalResults), func(name string) error {
+					return fractal.WriteResultsJSON(fractalResults, summary, name)
+				}})
+			}
+			outputs = append(outputs,
+				output{"rolling_fd.csv" + csvExt, len(rolling), func(name string) error {
+					return fractal.WriteRollingCSV(rolling, regimes, name)
+				}},
+				output{"changepoints.csv" + csvExt, len(changePoints), func(name string) error {
+					return fractal.WriteChangePointsCSV(data, rolling, changePoints, name)
+				}},
+				output{"scaling.csv" + csvExt, len(scaling), func(name string) error {
+					return fractal.WriteScalingCSV(scaling, name)
+				}},
+				output{"rolling_hurst.csv" + csvExt, len(rollingHurst), func(name string) error {
+					return fractal.WriteRollingHurstCSV(rolling, rollingHurst, name)
+				}},
+				output{"fd_vol_corr.csv" + csvExt, len(rolling), func(name string) error {
+					return fractal.WriteFDVolCSV(rolling, windowVols, fdVolCorr, name)
+				}},
+				output{"fractals_pivots.csv" + csvExt, len(bullish) + len(bearish), func(name string) error {
+					return fractal.WritePivotsCSV(data, bullish, bearish, name)
+				}},
+				output{"divergences.csv" + csvExt, len(divergences), func(name string) error {
+					return fractal.WriteDivergencesCSV(data, rsi, divergences, name)
+				}},
+				output{"fib_levels.csv" + csvExt, fibRows, func(name string) error {
+					return fractal.WriteFibonacciCSV(data, swings, name)
+				}},
+				output{"renyi.csv" + csvExt, len(renyi), func(name string) error {
+					return fractal.WriteRenyiCSV(renyi, name)
+				}},
+				output{"holder.csv" + csvExt, len(holder), func(name string) error {
+					return fractal.WriteHolderCSV(data, holder, name)
+				}},
+				output{"mfdfa.csv" + csvExt, len(hq), func(name string) error {
+					return fractal.WriteMFDFACSV(hq, name)
+				}},
+				output{"acf.csv" + csvExt, len(acf), func(name string) error {
+					return fractal.WriteACFCSV(acf, name)
+				}},
+				output{"psd.csv" + csvExt, len(freqs), func(name string) error {
+					return fractal.WritePSDCSV(freqs, power, name)
+				}},
+				output{"bands.csv" + csvExt, len(data), func(name string) error {
+					return fractal.WriteBandsCSV(data, mid, upper, lower, name)
+				}},
+				output{"macd.csv" + csvExt, len(data), func(name string) error {
+					return fractal.WriteMACDCSV(data, macd, macdSig, macdHist, name)
+				}},
+				output{"rolling_volatility.csv" + csvExt, len(data), func(name string) error {
+					return fractal.WriteVolatilityCSV(data, *periodsPerYear, warmUp, name)
+				}},
+				output{"atr.csv" + csvExt, len(data), func(name string) error {
+					return fractal.WriteATRCSV(data, atr, name)
+				}},
+				output{"run.json", 1, func(name string) error {
+					return writeRunRecord(record, name)
+				}},
+			)
+			if *compareEstimators {
+				names := make([]string, len(fractalResults))
+				for i, r := range fractalResults {
+					names[i] = windowName(r, n)
+				}
+				agreement := fractal.EstimatorAgreement(fractalResults)
+				outputs = append(outputs, output{"estimator_comparison.csv" + csvExt, len(fractalResults) + len(agreement), func(name string) error {
+					return fractal.WriteEstimatorComparisonCSV(names, fractalResults, agreement, name)
+				}})
+			}
+			if *export != "" {
+				doc := fractal.Export{Config: allFlags(), Summary: summary, Results: fractalResults}
+				if *exportCandles {
+					doc.Candles = data
+				}
+				outputs = append(outputs, output{*export, len(fractalResults), func(name string) error {
+					return fractal.WriteExportJSON(doc, name)
+				}})
+			}
+			if gapMode != fractal.GapsIgnore {
+				outputs = append(outputs, output{"gaps.csv" + csvExt, len(gaps), func(name string) error {
+					return fractal.WriteGapsCSV(gaps, name)
+				}})
+			}
+			if profiles != nil {
+				names := make([]string, len(profiles))
+				for i, p := range profiles {
+					names[i] = windowName(p.A, n)
+				}
+				outputs = append(outputs, output{"compare.csv" + csvExt, len(profiles), func(name string) error {
+					return fractal.WriteCompareCSV(names, profiles, name)
+				}})
+			}
+			if correlations != nil {
+				outputs = append(outputs, output{"correlation.csv" + csvExt, len(correlations), func(name string) error {
+					return fractal.WriteCorrelationCSV(inputs.names(), correlations, name)
+				}})
+			}
+			if err := writeOutputs(*outDir, outputs, *dryRun); err != nil {
+				return err
+			}
+		}
+		if pass == 0 {
+			timings["write"] = append(timings["write"], time.Since(writeStart))
+		}
+
+		for _, r := range fractalResults {
+			slog.Info("window",
+				"name", windowName(r, n),
+				"window_start", r.WindowStart,
+				"window_end", r.WindowEnd,
+				"dimension", r.Dimension,
+				"r2", r.R2,
+				"hurst", r.Hurst,
+				"hurst_aggvar", r.HurstAggVar,
+				"dfa", r.DFA,
+				"compute_us", r.ComputeMicros,
+				"degenerate", r.Degenerate,
+			)
+		}
+		slog.Info("williams fractals", "bullish", len(bullish), "bearish", len(bearish), "divergences", len(divergences))
+		slog.Info("rolling dimension", "windows", len(rolling), "window", *rollWindow, "step", *rollStep,
+			"trending_below", regimes.TrendingBelow, "choppy_above", regimes.ChoppyAbove)
+		if !*toStdout && !*dryRun {
+			slog.Info("output written", "dir", *outDir)
+		}
+		return nil
 	}
 
-	return (n*sxy - sx*sy) / d
+	// One profile spans every pass, since a CPU profile can't be paused
+	// between them, and the heap profile is taken after the last
+	stopProfiling, err := startProfiling(*cpuProfile, *memProfile)
+	if err != nil {
+		return fmt.Errorf("profiling: %w", err)
+	}
+	defer stopProfiling()
+	for pass := 0; pass < *repeat; pass++ {
+		if err := pipeline(pass); err != nil {
+			return err
+		}
+	}
+	if *repeat > 1 {
+		return printRepeatStats(os.Stdout, *repeat, timings, fingerprints)
+	}
+	return nil
 }
 
-func gaussian() float64 {
-	u1 := 1.0 - rand.Float64()
-	u2 := 1.0 - rand.Float64()
-	return math.Sqrt(-2.0*math.Log(u1)) * math.Sin(2.0*math.Pi*u2)
+// The default analysis windows with their names. A negative start counts
+// back from the end of the series and a zero size runs to it.
+var namedWindows = []struct {
+	name        string
+	start, size int
+}{
+	{"full", 0, 0},
+	{"last1k", -1000, 1000},
+	{"last500", -500, 500},
+	{"first2k", 0, 2000},
+	{"mid2k", 2000, 2000},
+	{"late2k", 6000, 2000},
 }
 
-func writeMarketCSV(data []MarketCandle, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+// Resolves namedWindows[i] against a series of n candles.
+func namedWindow(i, n int) fractal.Window {
+	w := fractal.Window{Start: namedWindows[i].start, Size: namedWindows[i].size}
+	if w.Start < 0 {
+		w.Start += n
 	}
-	defer file.Close()
+	if w.Size == 0 {
+		w.Size = n - w.Start
+	}
+	return w
+}
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+// The fixed analysis windows that fit in n candles. The full series is
+// always analysed; the others are dropped on shorter series rather than
+// truncated, so a named window never silently shrinks or duplicates
+// another.
+func fixedWindows(n int) []fractal.Window {
+	var windows []fractal.Window
+	for i := range namedWindows {
+		w := namedWindow(i, n)
+		if i == 0 || (w.Start >= 0 && w.Start+w.Size <= n && w.Size < n) {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
 
-	// Header
-	writer.Write([]string{"Timestamp", "Price", "Volume", "Returns", "Volatility"})
+// Parses the -windows value, comma-separated start:size pairs such as
+// "0:10000,9000:1000", and checks each names a distinct window inside n
+// candles; n <= 0 skips the bounds check.
+func parseWindows(spec string, n int) ([]fractal.Window, error) {
+	var windows []fractal.Window
+	seen := map[fractal.Window]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		startText, sizeText, ok := strings.Cut(part, ":")
+		start, errStart := strconv.Atoi(strings.TrimSpace(startText))
+		size, errSize := strconv.Atoi(strings.TrimSpace(sizeText))
+		if !ok || errStart != nil || errSize != nil {
+			return nil, fmt.Errorf("invalid window %q (want start:size)", part)
+		}
+		w := fractal.Window{Start: start, Size: size}
+		switch {
+		case start < 0 || size < 1:
+			return nil, fmt.Errorf("window %q needs a non-negative start and a positive size", part)
+		case n > 0 && start+size > n:
+			return nil, fmt.Errorf("window %q ends at candle %d, past the %d-candle series", part, start+size, n)
+		case seen[w]:
+			return nil, fmt.Errorf("window %q is given twice", part)
+		}
+		seen[w] = true
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
 
-	// Data
-	for _, candle := range data {
-		record := []string{
-			candle.Timestamp.Format("2006-01-02 15:04:05"),
-			fmt.Sprintf("%.6f", candle.Price),
-			fmt.Sprintf("%.2f", candle.Volume),
-			fmt.Sprintf("%.6f", candle.Returns),
-			fmt.Sprintf("%.6f", candle.Volatility),
+// Names an analysis window of an n-candle run for logs and tables: the
+// name of the default window it matches, or start:size.
+func windowName(r fractal.FractalResult, n int) string {
+	w := fractal.Window{Start: r.WindowStart, Size: r.WindowEnd - r.WindowStart + 1}
+	for i := range namedWindows {
+		if namedWindow(i, n) == w {
+			return namedWindows[i].name
 		}
-		writer.Write(record)
 	}
+	return fmt.Sprintf("%d:%d", w.Start, w.Size)
+}
 
-	return nil
+// Output formats selected by -format. Market data goes to Parquet instead
+// of CSV when parquet is chosen alone; window results and the summary go
+// to JSON instead of CSV when json is chosen without csv.
+type outputFormats struct {
+	csv, json, parquet bool
 }
 
-func writeFractalCSV(results []FractalResult, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+func (f outputFormats) marketCSV() bool  { return f.csv || !f.parquet }
+func (f outputFormats) resultsCSV() bool { return f.csv || !f.json }
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+func parseFormats(spec string) (outputFormats, error) {
+	var f outputFormats
+	for _, part := range strings.Split(spec, ",") {
+		switch strings.TrimSpace(part) {
+		case "csv":
+			f.csv = true
+		case "json":
+			f.json = true
+		case "parquet":
+			f.parquet = true
+		case "both":
+			f.csv, f.json = true, true
+		default:
+			return f, fmt.Errorf("unknown format %q (want csv, json, parquet or both)", part)
+		}
+	}
+	return f, nil
+}
 
-	writer.Write([]string{"WindowStart", "WindowEnd", "WindowSize", "FractalDimension"})
+// Parses the -box-sizes value. Empty keeps the default schedule.
+func parseBoxSizes(spec string) (fractal.BoxCounter, error) {
+	var counter fractal.BoxCounter
+	switch strings.TrimSpace(spec) {
+	case "":
+		return counter, nil
+	case "auto":
+		counter.Sizes = fractal.LogSpacedBoxSizes(1, 64, 12)
+		return counter, nil
+	}
 
-	for _, r := range results {
-		record := []string{
-			strconv.Itoa(r.WindowStart),
-			strconv.Itoa(r.WindowEnd),
-			strconv.Itoa(r.WindowEnd - r.WindowStart + 1),
-			fmt.Sprintf("%.6f", r.Dimension),
+	for _, part := range strings.Split(spec, ",") {
+		bs, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || bs < 1 {
+			return counter, fmt.Errorf("invalid box size %q", part)
+		}
+		if len(counter.Sizes) > 0 && bs <= counter.Sizes[len(counter.Sizes)-1] {
+			return counter, fmt.Errorf("box sizes must be strictly increasing")
 		}
-		writer.Write(record)
+		counter.Sizes = append(counter.Sizes, bs)
 	}
+	return counter, nil
+}
 
-	return nil
+// Reads candles from a Parquet file or a (possibly gzipped) CSV with
+// csvReader's options. Parquet files are loaded whole and then cut to the
+// same limit, recomputing volatility over the same window.
+func readCandles(path string, csvReader fractal.MarketCSVReader) ([]fractal.MarketCandle, error) {
+	var data []fractal.MarketCandle
+	var err error
+	if strings.HasSuffix(strings.ToLower(path), ".parquet") {
+		data, err = fractal.ReadMarketParquet(path)
+		if limit := csvReader.Limit; err == nil && limit > 0 && len(data) > limit {
+			if csvReader.Tail {
+				data = data[len(data)-limit:]
+			} else {
+				data = data[:limit]
+			}
+			window := csvReader.VolWindow
+			if window <= 0 {
+				window = fractal.DefaultVolWindow
+			}
+			fractal.ComputeReturnsAndVol(data, window)
+		}
+	} else {
+		data, err = csvReader.Read(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%s contains no candles", path)
+	}
+	return data, nil
 }
 
-func writeSummary(data []MarketCandle, results []FractalResult, filename string) error {
-	file, err := os.Create(filename)
+// Reads trades from a CSV and aggregates them into candles of interval.
+func readTickCandles(path, timeFormat string, interval time.Duration, empty fractal.EmptyBuckets) ([]fractal.MarketCandle, error) {
+	ticks, err := fractal.ReadTicksCSV(path, timeFormat)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
+	data := fractal.AggregateTicks(ticks, interval, empty)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%s contains no trades", path)
+	}
+	slog.Info("aggregated ticks", "input", path, "ticks", len(ticks), "candles", len(data), "interval", interval)
+	return data, nil
+}
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+// Values of a repeatable -input flag, in command-line order.
+type inputList []string
 
-	writer.Write([]string{"Metric", "Value"})
+func (l *inputList) String() string { return strings.Join(*l, ",") }
 
-	writer.Write([]string{"Points", strconv.Itoa(len(data))})
-	writer.Write([]string{"StartPrice", fmt.Sprintf("%.6f", data[0].Price)})
-	writer.Write([]string{"EndPrice", fmt.Sprintf("%.6f", data[len(data)-1].Price)})
-	totalReturn := (data[len(data)-1].Price - data[0].Price) / data[0].Price
-	writer.Write([]string{"TotalReturn", fmt.Sprintf("%.6f", totalReturn)})
+// Set also splits on commas, the form String gives when recorded.
+func (l *inputList) Set(paths string) error {
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			*l = append(*l, path)
+		}
+	}
+	return nil
+}
 
-	for i, r := range results {
-		writer.Write([]string{fmt.Sprintf("FD_Window_%d", i), fmt.Sprintf("%.6f", r.Dimension)})
+// Names each input by its file name without extensions, falling back to
+// the full path when two inputs would share a name.
+func (l inputList) names() []string {
+	names := make([]string, len(l))
+	count := make(map[string]int)
+	for i, path := range l {
+		base := filepath.Base(path)
+		if dot := strings.Index(base, "."); dot > 0 {
+			base = base[:dot]
+		}
+		names[i] = base
+		count[base]++
 	}
+	for i, name := range names {
+		if count[name] > 1 {
+			names[i] = l[i]
+		}
+	}
+	return names
+}
 
-	return nil
-}
\ No newline at end of file
+// Reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"fractal-analysis/fractal"
+)
+
+func TestParseBoxSizes(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"auto", fractal.LogSpacedBoxSizes(1, 64, 12), false},
+		{"2, 4,8", []int{2, 4, 8}, false},
+		{"1", []int{1}, false},
+		{"4,2", nil, true},
+		{"2,2", nil, true},
+		{"0,2", nil, true},
+		{"2,x", nil, true},
+	}
+	for _, tt := range tests {
+		counter, err := parseBoxSizes(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBoxSizes(%q): error %v, want error %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !slices.Equal(counter.Sizes, tt.want) {
+			t.Errorf("parseBoxSizes(%q) = %v, want %v", tt.spec, counter.Sizes, tt.want)
+		}
+	}
+}
+
+func TestParseWindows(t *testing.T) {
+	tests := []struct {
+		spec   string
+		n      int
+		want   []fractal.Window
+		errors string // part of the error, empty for none
+	}{
+		{"0:10000,9000:1000,5000:2000", 10000, []fractal.Window{{Start: 0, Size: 10000}, {Start: 9000, Size: 1000}, {Start: 5000, Size: 2000}}, ""},
+		{" 0 : 10 , 5:5", 10, []fractal.Window{{Start: 0, Size: 10}, {Start: 5, Size: 5}}, ""},
+		{"100:50", 0, []fractal.Window{{Start: 100, Size: 50}}, ""},
+		{"0:10,0:10", 10, nil, "given twice"},
+		{"0:11", 10, nil, "past the 10-candle series"},
+		{"-1:5", 10, nil, "non-negative start"},
+		{"0:0", 10, nil, "positive size"},
+		{"5", 10, nil, "want start:size"},
+		{"a:5", 10, nil, "want start:size"},
+		{"0:5,", 10, nil, "want start:size"},
+		{"", 10, nil, "want start:size"},
+	}
+	for _, tt := range tests {
+		got, err := parseWindows(tt.spec, tt.n)
+		if tt.errors != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errors) {
+				t.Errorf("parseWindows(%q, %d): error %v, want %q", tt.spec, tt.n, err, tt.errors)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("parseWindows(%q, %d) = %v, %v, want %v", tt.spec, tt.n, got, err, tt.want)
+		}
+	}
+}
+
+func TestWindowName(t *testing.T) {
+	tests := []struct {
+		w    fractal.Window
+		want string
+	}{
+		{fractal.Window{Start: 0, Size: 10000}, "full"},
+		{fractal.Window{Start: 9000, Size: 1000}, "last1k"},
+		{fractal.Window{Start: 9500, Size: 500}, "last500"},
+		{fractal.Window{Start: 2000, Size: 2000}, "mid2k"},
+		{fractal.Window{Start: 5000, Size: 2000}, "5000:2000"},
+	}
+	for _, tt := range tests {
+		r := fractal.FractalResult{WindowStart: tt.w.Start, WindowEnd: tt.w.Start + tt.w.Size - 1}
+		if got := windowName(r, 10000); got != tt.want {
+			t.Errorf("window %v: name %q, want %q", tt.w, got, tt.want)
+		}
+	}
+}
+
+// Runs the command with args on a fresh flag set, returning what it wrote
+// to stdout and stderr.
+func runCommand(t *testing.T, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+	savedFlags, savedArgs := flag.CommandLine, os.Args
+	savedOut, savedErr, savedLog := os.Stdout, os.Stderr, slog.Default()
+	defer func() {
+		flag.CommandLine, os.Args = savedFlags, savedArgs
+		os.Stdout, os.Stderr = savedOut, savedErr
+		slog.SetDefault(savedLog)
+	}()
+	flag.CommandLine = flag.NewFlagSet("fractal-analysis", flag.ContinueOnError)
+	os.Args = append([]string{"fractal-analysis"}, args...)
+
+	dir := t.TempDir()
+	capture := func(name string) *os.File {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	os.Stdout, os.Stderr = capture("stdout"), capture("stderr")
+	err = run()
+	os.Stdout.Close()
+	os.Stderr.Close()
+
+	out, _ := os.ReadFile(filepath.Join(dir, "stdout"))
+	errOut, _ := os.ReadFile(filepath.Join(dir, "stderr"))
+	return string(out), string(errOut), err
+}
+
+func TestScalingFlags(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := runCommand(t, "-n", "3000", "-scaling-min", "100", "-scaling-max", "2000", "-scaling-points", "5", "-outdir", dir); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "scaling.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sizes []string
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n")[1:] {
+		sizes = append(sizes, strings.Split(line, ",")[0])
+	}
+	if want := []string{"100", "211", "447", "946", "2000"}; !slices.Equal(sizes, want) {
+		t.Errorf("scaling.csv window sizes %v, want %v", sizes, want)
+	}
+}
+
+// The whole pipeline turns away or completes on 0-, 1- and 2-candle
+// series without panicking.
+func TestShortSeriesPipeline(t *testing.T) {
+	header := "Timestamp,Price\n"
+	row := func(h int) string { return fmt.Sprintf("2024-01-01 %02d:00:00,%d\n", h, 100+h) }
+	tests := []struct {
+		name  string
+		args  []string
+		input string // written to in.csv and read with -input when set
+		usage bool   // rejected as a bad command line
+		fails bool
+	}{
+		{name: "n 0", args: []string{"-n", "0"}, usage: true},
+		{name: "n 1", args: []string{"-n", "1"}, usage: true},
+		{name: "n 1, vol window 2", args: []string{"-n", "1", "-vol-window", "2"}, usage: true},
+		{name: "n 3, vol window 2", args: []string{"-n", "3", "-vol-window", "2"}},
+		{name: "empty input", input: header, fails: true},
+		{name: "one candle", input: header + row(0)},
+		{name: "two candles", input: header + row(0) + row(1)},
+		{name: "one candle to stdout", input: header + row(0), args: []string{"-stdout"}},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		args := append([]string{"-outdir", filepath.Join(dir, "out")}, tt.args...)
+		if tt.input != "" {
+			args = append(args, "-input", writeTemp(t, "in.csv", tt.input))
+		}
+		_, _, err := runCommand(t, args...)
+		var usage usageError
+		if isUsage := errors.As(err, &usage); isUsage != tt.usage || (err != nil) != (tt.usage || tt.fails) {
+			t.Errorf("%s: error %v, want usage error %v, failure %v", tt.name, err, tt.usage, tt.fails)
+		}
+	}
+}
+
+// A third-party estimator registered before the command runs is selectable
+// with -method and gets its own fractal_patterns.csv column.
+func TestCustomEstimatorEndToEnd(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := runCommand(t, "-n", "2000", "-windows", "0:500,500:1500", "-method", "test-length,katz", "-outdir", dir); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "fractal_patterns.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	header := strings.Split(lines[0], ",")
+	col := slices.Index(header, "Dimension_test-length")
+	if col < 0 || header[col+1] != "Dimension_katz" {
+		t.Fatalf("header %v, want Dimension_test-length then Dimension_katz", header)
+	}
+	for i, want := range []string{"500.000000", "1500.000000"} {
+		if got := strings.Split(lines[i+1], ",")[col]; got != want {
+			t.Errorf("window %d: Dimension_test-length %s, want %s", i, got, want)
+		}
+	}
+
+	if _, _, err := runCommand(t, "-method", "no-such", "-outdir", dir); err == nil || !strings.Contains(err.Error(), `unknown method "no-such"`) {
+		t.Errorf("unknown method: error %v", err)
+	}
+}
+
+// -limit and -tail cut an input file to exactly that many candles, whose
+// returns start fresh at the first one kept.
+func TestLimitFlag(t *testing.T) {
+	var in strings.Builder
+	in.WriteString("Timestamp,Price,Returns,Volatility\n")
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&in, "2024-01-01 00:%02d:%02d,%d,0.5,0.5\n", i/60, i%60, 100+i)
+	}
+	input := writeTemp(t, "in.csv", in.String())
+	for _, tail := range []bool{false, true} {
+		dir := t.TempDir()
+		args := []string{"-input", input, "-limit", "100", "-windows", "0:100", "-outdir", dir}
+		if tail {
+			args = append(args, "-tail")
+		}
+		if _, _, err := runCommand(t, args...); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, "market_data.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+		if len(lines) != 101 {
+			t.Fatalf("tail %v: %d candles, want 100", tail, len(lines)-1)
+		}
+		header := strings.Split(lines[0], ",")
+		price, returns := slices.Index(header, "Price"), slices.Index(header, "Returns")
+		first, second := strings.Split(lines[1], ","), strings.Split(lines[2], ",")
+		want := "100.000000"
+		if tail {
+			want = "500.000000"
+		}
+		if first[price] != want || first[returns] != "0.000000" || second[returns] == "0.500000" {
+			t.Errorf("tail %v: first candles %v and %v, want price %s and returns recomputed from 0", tail, first, second, want)
+		}
+	}
+}
+
+// A limited CSV or Parquet input recomputes its volatility over the
+// reader's window, not the default one.
+func TestReadCandlesVolWindow(t *testing.T) {
+	data := fractal.GenerateSeries(fractal.NewRand(85, 0), 300, 100)
+	fractal.ComputeReturnsAndVol(data, fractal.DefaultVolWindow)
+	dir := t.TempDir()
+	csvPath, parquetPath := filepath.Join(dir, "in.csv"), filepath.Join(dir, "in.parquet")
+	if err := fractal.WriteMarketCSV(data, csvPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := fractal.WriteMarketParquet(data, parquetPath); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{csvPath, parquetPath} {
+		all, err := readCandles(path, fractal.MarketCSVReader{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := slices.Clone(all[:100])
+		fractal.ComputeReturnsAndVol(want, 5)
+		got, err := readCandles(path, fractal.MarketCSVReader{Limit: 100, VolWindow: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: %d candles, want %d", filepath.Base(path), len(got), len(want))
+		}
+		for i := range got {
+			if got[i].Volatility != want[i].Volatility {
+				t.Errorf("%s candle %d: volatility %v, want %v over 5 candles", filepath.Base(path), i, got[i].Volatility, want[i].Volatility)
+				break
+			}
+		}
+	}
+}
+
+// -summary-only writes scan.csv alone, one row per -input in order, with a
+// file that fails to load reported in its row rather than ending the scan.
+func TestSummaryOnly(t *testing.T) {
+	var rising strings.Builder
+	rising.WriteString("Timestamp,Price\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&rising, "2024-01-01 %02d:%02d:00,%d\n", i/60, i%60, 100+i)
+	}
+	a := writeTemp(t, "a.csv", rising.String())
+	b := filepath.Join(t.TempDir(), "b.csv")
+	data := fractal.GenerateSeries(fractal.NewRand(93, 0), 600, 100)
+	fractal.ComputeReturnsAndVol(data, fractal.DefaultVolWindow)
+	if err := fractal.WriteMarketCSV(data, b); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(t.TempDir(), "missing.csv")
+
+	dir := t.TempDir()
+	if _, _, err := runCommand(t, "-summary-only", "-input", a, "-input", b+","+missing, "-outdir", dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := listDir(t, dir); !slices.Equal(got, []string{"scan.csv"}) {
+		t.Errorf("%s holds %v, want only scan.csv", dir, got)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "scan.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if want := "Input,Points,TotalReturn,MaxDrawdown,Dimension,R2,Hurst,Error"; lines[0] != want {
+		t.Errorf("header %q, want %q", lines[0], want)
+	}
+	if len(lines) != 4 {
+		t.Fatalf("%d rows, want 3: %q", len(lines)-1, lines[1:])
+	}
+	rows := make([][]string, 3)
+	for i, line := range lines[1:] {
+		rows[i] = strings.Split(line, ",")
+	}
+	if r := rows[0]; r[0] != a || r[1] != "100" || r[2] != "0.990000" || r[3] != "0.000000" || r[4] == "" || r[7] != "" {
+		t.Errorf("rising row %v, want 100 points, 0.99 return, no drawdown and a dimension", r)
+	}
+	if r := rows[1]; r[0] != b || r[1] != "600" || r[4] == "" || r[7] != "" {
+		t.Errorf("generated row %v, want 600 points and a dimension", r)
+	}
+	if r := rows[2]; r[0] != missing || r[4] != "" || r[len(r)-1] == "" {
+		t.Errorf("missing file row %v, want only an error", r)
+	}
+}
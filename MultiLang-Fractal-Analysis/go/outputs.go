@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// A file the run writes: its name within the output directory, how many
+// data rows it holds and the writer that creates it.
+type output struct {
+	name  string
+	rows  int
+	write func(filename string) error
+}
+
+// Writes each output under dir, or with dryRun only logs the filename and
+// row count each would have. A failed write doesn't stop the rest; the
+// failures are returned together.
+func writeOutputs(dir string, outputs []output, dryRun bool) error {
+	if dryRun {
+		for _, o := range outputs {
+			slog.Info("dry run", "file", filepath.Join(dir, o.name), "rows", o.rows)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var errs []error
+	for _, o := range outputs {
+		if err := o.write(filepath.Join(dir, o.name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Creates dir if needed and checks a file can be created in it, so an
+// unusable -outdir fails before the analysis rather than after it.
+func checkOutputDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The files a directory holds, sorted.
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// A dry run creates nothing and logs the name and row count of every file
+// the same run would write, with -format and -gzip applied.
+func TestDryRun(t *testing.T) {
+	args := []string{"-n", "600", "-format", "csv,json", "-gzip"}
+	written := t.TempDir()
+	if _, _, err := runCommand(t, append(args, "-outdir", written)...); err != nil {
+		t.Fatal(err)
+	}
+	want := listDir(t, written)
+
+	dir := filepath.Join(t.TempDir(), "out")
+	_, stderr, err := runCommand(t, append(args, "-dry-run", "-log-format", "json", "-outdir", dir)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("dry run created %s: %v", dir, err)
+	}
+	var got []string
+	rows := map[string]float64{}
+	for _, line := range strings.Split(strings.TrimSpace(stderr), "\n") {
+		var rec struct {
+			Msg  string
+			File string
+			Rows float64
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("record %q: %v", line, err)
+		}
+		if rec.Msg == "dry run" {
+			if filepath.Dir(rec.File) != dir {
+				t.Errorf("dry run file %s outside %s", rec.File, dir)
+			}
+			got = append(got, filepath.Base(rec.File))
+			rows[filepath.Base(rec.File)] = rec.Rows
+		}
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("dry run logged %v, want the files a run writes: %v", got, want)
+	}
+	if rows["market_data.csv.gz"] != 600 {
+		t.Errorf("market_data.csv.gz logged with %v rows, want 600", rows["market_data.csv.gz"])
+	}
+}
+
+// -outdir is created with its parents and receives every file; without it
+// the files go to out-go in the working directory.
+func TestOutputDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "runs", "a")
+	if _, _, err := runCommand(t, "-n", "600", "-outdir", dir); err != nil {
+		t.Fatal(err)
+	}
+	got := listDir(t, dir)
+	for _, name := range []string{"market_data.csv", "fractal_patterns.csv", "session_summary.csv", "run.json"} {
+		if !slices.Contains(got, name) {
+			t.Errorf("%s holds %v, want %s", dir, got, name)
+		}
+	}
+
+	t.Chdir(t.TempDir())
+	if _, _, err := runCommand(t, "-n", "600"); err != nil {
+		t.Fatal(err)
+	}
+	if def := listDir(t, "out-go"); !slices.Equal(def, got) {
+		t.Errorf("out-go holds %v, want %v", def, got)
+	}
+}
+
+// An -outdir that can't be created is turned away before the analysis, and
+// a file that can't be written fails the run without stopping the others.
+func TestUnwritableOutputs(t *testing.T) {
+	blocker := writeTemp(t, "file", "not a directory")
+	_, _, err := runCommand(t, "-n", "600", "-outdir", filepath.Join(blocker, "out"))
+	var usage usageError
+	if !errors.As(err, &usage) || !strings.Contains(err.Error(), "-outdir") {
+		t.Errorf("outdir under a file: error %v, want an -outdir usage error", err)
+	}
+
+	// A directory where market_data.csv belongs
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "market_data.csv"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = runCommand(t, "-n", "600", "-outdir", dir)
+	if err == nil || errors.As(err, &usage) || !strings.Contains(err.Error(), "market_data.csv") {
+		t.Errorf("unwritable market_data.csv: error %v, want a write error naming it", err)
+	}
+	if got := listDir(t, dir); !slices.Contains(got, "fractal_patterns.csv") {
+		t.Errorf("after the failed write %s holds %v, want the other outputs", dir, got)
+	}
+
+	failed := errors.New("disk full")
+	err = writeOutputs(t.TempDir(), []output{
+		{"a.csv", 1, func(string) error { return failed }},
+		{"b.csv", 1, func(name string) error { return os.WriteFile(name, nil, 0644) }},
+		{"c.csv", 1, func(string) error { return failed }},
+	}, false)
+	if !errors.Is(err, failed) || strings.Count(err.Error(), "disk full") != 2 {
+		t.Errorf("writeOutputs: error %v, want both failures", err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"fractal-analysis/fractal"
+)
+
+// Pipeline phases timed by -repeat, in reporting order.
+var repeatPhases = []string{"generate", "analyse", "write"}
+
+// Condenses a pass's results to the precision the outputs are written at,
+// so passes can be checked for determinism without last-bit noise.
+func fingerprint(results []fractal.FractalResult, summary []fractal.Metric) string {
+	var b strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&b, "%d:%d:%.6f:%.6f:%.6f;", r.WindowStart, r.WindowEnd, r.Dimension, r.R2, r.Hurst)
+	}
+	for _, m := range summary {
+		fmt.Fprintf(&b, "%s=%s;", m.Name, m.String())
+	}
+	return b.String()
+}
+
+// Prints the median and 95th percentile of each phase's timings over the
+// passes of a -repeat run, and whether every pass gave the same results.
+func printRepeatStats(w io.Writer, passes int, timings map[string][]time.Duration, fingerprints []string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Phase\tRuns\tP50\tP95\t")
+	for _, phase := range repeatPhases {
+		ms := make([]float64, len(timings[phase]))
+		for i, d := range timings[phase] {
+			ms[i] = float64(d) / float64(time.Millisecond)
+		}
+		sort.Float64s(ms)
+		fmt.Fprintf(tw, "%s\t%d\t%.1fms\t%.1fms\t\n", phase, len(ms), fractal.Percentile(ms, 50), fractal.Percentile(ms, 95))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	deterministic := true
+	for _, f := range fingerprints[1:] {
+		deterministic = deterministic && f == fingerprints[0]
+	}
+	_, err := fmt.Fprintf(w, "\npasses: %d, deterministic: %t\n", passes, deterministic)
+	return err
+}
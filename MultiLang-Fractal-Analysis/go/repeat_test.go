@@ -0,0 +1,50 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// -repeat 3 makes three passes, timing generation and analysis on each and
+// the writes of the first, and reports them deterministic.
+func TestRepeat(t *testing.T) {
+	stdout, _, err := runCommand(t, "-n", "600", "-repeat", "3", "-outdir", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for phase, runs := range map[string]string{"generate": "3", "analyse": "3", "write": "1"} {
+		row := regexp.MustCompile(`(?m)^\s*` + phase + `\s+(\d+)\s+\d+\.\dms\s+\d+\.\dms$`).FindStringSubmatch(stdout)
+		if row == nil || row[1] != runs {
+			t.Errorf("%s: timing row %q, want %s runs with P50 and P95 in\n%s", phase, row, runs, stdout)
+		}
+	}
+	if !strings.Contains(stdout, "passes: 3, deterministic: true") {
+		t.Errorf("output %q, want three deterministic passes", stdout)
+	}
+}
+
+func TestPrintRepeatStats(t *testing.T) {
+	ms := func(v ...int) []time.Duration {
+		var d []time.Duration
+		for _, x := range v {
+			d = append(d, time.Duration(x)*time.Millisecond)
+		}
+		return d
+	}
+	timings := map[string][]time.Duration{
+		"generate": ms(3, 1, 2),
+		"analyse":  ms(10, 30, 20),
+		"write":    ms(5),
+	}
+	var b strings.Builder
+	if err := printRepeatStats(&b, 3, timings, []string{"a", "a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"generate     3   2.0ms   2.9ms", "analyse     3  20.0ms  29.0ms", "write     1   5.0ms   5.0ms", "passes: 3, deterministic: false"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("stats %q, want %q", b.String(), want)
+		}
+	}
+}
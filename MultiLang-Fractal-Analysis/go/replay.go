@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"fractal-analysis/fractal"
+)
+
+// Parameters of a batch run, written to run.json so -replay can rebuild
+// the same series. Seed, N and Generator repeat the effective values for
+// readers; Flags holds exactly what was given on the command line, and
+// Start pins the generated timestamps, which otherwise follow the clock.
+type runRecord struct {
+	Seed      int64             `json:"seed"`
+	N         int               `json:"n"`
+	Generator string            `json:"generator"`
+	Start     *time.Time        `json:"start,omitempty"`
+	Flags     map[string]string `json:"flags"`
+}
+
+// Captures the explicitly set flags, except -replay and -config, whose
+// settings are recorded as the flags they set, so that a replayed run
+// records the flags it ended up with and needs no config file.
+func newRunRecord(seed int64, n int, generator string) runRecord {
+	rec := runRecord{Seed: seed, N: n, Generator: generator, Flags: map[string]string{}}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "replay" && f.Name != "config" {
+			rec.Flags[f.Name] = f.Value.String()
+		}
+	})
+	return rec
+}
+
+// Every flag's effective value, given or default, for documents such as
+// -export that describe a run on their own.
+func allFlags() map[string]string {
+	flags := map[string]string{}
+	flag.VisitAll(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+}
+
+func writeRunRecord(rec runRecord, filename string) error {
+	raw, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(raw, '\n'), 0644)
+}
+
+func loadRunRecord(filename string) (runRecord, error) {
+	var rec runRecord
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return rec, err
+	}
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return rec, fmt.Errorf("%s: %w", filename, err)
+	}
+	return rec, nil
+}
+
+// Applies a recorded run's flags, leaving any given on this command line
+// in place so a replay can still change, say, -v or -format.
+func applyRunRecord(rec runRecord) error {
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for name, value := range rec.Flags {
+		if given[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("flag -%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Moves generated candles so the first falls at start, keeping spacing.
+func shiftTimestamps(data []fractal.MarketCandle, start time.Time) {
+	if len(data) == 0 {
+		return
+	}
+	offset := start.Sub(data[0].Timestamp)
+	for i := range data {
+		data[i].Timestamp = data[i].Timestamp.Add(offset)
+	}
+}
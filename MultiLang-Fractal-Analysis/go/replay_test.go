@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Replaying a run's run.json regenerates byte-identical market data, and
+// records the same flags, with only what this command line changed.
+func TestReplayReproducesRun(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	if _, _, err := runCommand(t, "-n", "1500", "-seed", "58", "-generator", "gbm", "-vol", "0.02", "-outdir", first); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runCommand(t, "-replay", filepath.Join(first, "run.json"), "-outdir", second); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile(filepath.Join(first, "market_data.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(second, "market_data.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("replayed market_data.csv differs from the original")
+	}
+
+	orig, err := loadRunRecord(filepath.Join(first, "run.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := loadRunRecord(filepath.Join(second, "run.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Seed != 58 || replayed.N != 1500 || replayed.Generator != "gbm" || !replayed.Start.Equal(*orig.Start) {
+		t.Errorf("replayed record %+v, want seed 58, n 1500, gbm from %v", replayed, orig.Start)
+	}
+	for name, value := range orig.Flags {
+		if name != "outdir" && replayed.Flags[name] != value {
+			t.Errorf("replayed -%s %q, want %q", name, replayed.Flags[name], value)
+		}
+	}
+	if replayed.Flags["outdir"] != second {
+		t.Errorf("replayed -outdir %q, want the command line's %q", replayed.Flags["outdir"], second)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+
+	"fractal-analysis/fractal"
+)
+
+// Upper bound on points per request so one client can't exhaust memory.
+const maxServePrices = 1_000_000
+
+type fractalRequest struct {
+	Prices []float64 `json:"prices"`
+	Method string    `json:"method"`
+}
+
+type fractalResponse struct {
+	Dimension float64  `json:"dimension"`
+	R2        *float64 `json:"r2"` // null for estimators without a regression
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newServeMux(counter fractal.BoxCounter) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fractal", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"use POST"})
+			return
+		}
+
+		// Roughly 25 bytes per encoded price
+		r.Body = http.MaxBytesReader(w, r.Body, maxServePrices*25)
+		var req fractalRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+		if len(req.Prices) < 4 {
+			writeJSON(w, http.StatusBadRequest, errorResponse{"prices needs at least 4 points"})
+			return
+		}
+		if len(req.Prices) > maxServePrices {
+			writeJSON(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("prices is limited to %d points", maxServePrices)})
+			return
+		}
+
+		var resp fractalResponse
+		switch req.Method {
+		case "", "box":
+			d, r2 := counter.Fit(req.Prices)
+			resp = fractalResponse{d, &r2}
+		case "higuchi":
+			d, r2 := fractal.HiguchiFit(req.Prices, fractal.DefaultHiguchiKMax)
+			resp = fractalResponse{d, &r2}
+		case "katz":
+			resp = fractalResponse{Dimension: fractal.KatzFractalDimension(req.Prices)}
+		case "petrosian":
+			resp = fractalResponse{Dimension: fractal.PetrosianFractalDimension(req.Prices)}
+		case "variogram":
+			d, r2 := fractal.VariogramFit(req.Prices, fractal.DefaultVariogramMaxLag)
+			resp = fractalResponse{d, &r2}
+		case "wavelet":
+			d, r2 := fractal.WaveletFit(req.Prices)
+			resp = fractalResponse{d, &r2}
+		default:
+			// Estimators registered by other packages have no R²
+			e, ok := fractal.LookupEstimator(req.Method)
+			if !ok {
+				writeJSON(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("unknown method %q (want %s)", req.Method, strings.Join(fractal.EstimatorNames(), ", "))})
+				return
+			}
+			d, err := e.Dimension(req.Prices)
+			if err != nil {
+				writeJSON(w, http.StatusUnprocessableEntity, errorResponse{err.Error()})
+				return
+			}
+			resp = fractalResponse{Dimension: d}
+		}
+		if math.IsNaN(resp.Dimension) || math.IsInf(resp.Dimension, 0) {
+			writeJSON(w, http.StatusUnprocessableEntity, errorResponse{"prices do not yield a finite dimension"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	})
+	return mux
+}
+
+// Marshals v before writing the header, so a value that can't be encoded
+// (a NaN R², say) becomes a 500 rather than a 200 with an empty body.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("encoding response", "error", err)
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(errorResponse{"response could not be encoded"})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		slog.Warn("writing response", "error", err)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fractal-analysis/fractal"
+)
+
+// Registered estimators reach the server's generic path, which reports
+// their errors and non-finite dimensions as unprocessable; test-length
+// reports the window length for checking -method columns.
+func init() {
+	fractal.Register(fractal.EstimatorFunc("test-nan", func([]float64) float64 { return math.NaN() }))
+	fractal.Register(failingEstimator{})
+	fractal.Register(fractal.EstimatorFunc("test-length", func(s []float64) float64 { return float64(len(s)) }))
+}
+
+type failingEstimator struct{}
+
+func (failingEstimator) Name() string { return "test-error" }
+
+func (failingEstimator) Dimension([]float64) (float64, error) {
+	return 0, errors.New("series rejected")
+}
+
+// Posts body to /fractal and returns the status and decoded JSON reply.
+func postFractal(t *testing.T, mux http.Handler, method, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, "/fractal", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type %q, want application/json", ct)
+	}
+	reply := map[string]any{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("reply %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, reply
+}
+
+func TestServeMethods(t *testing.T) {
+	rng := fractal.NewRand(26, 0)
+	prices := make([]float64, 500)
+	p := 100.0
+	for i := range prices {
+		p += rng.NormFloat64()
+		prices[i] = p
+	}
+	body, _ := json.Marshal(prices)
+
+	counter := fractal.BoxCounter{}
+	boxD, _ := counter.Fit(prices)
+	higuchiD, _ := fractal.HiguchiFit(prices, fractal.DefaultHiguchiKMax)
+	variogramD, _ := fractal.VariogramFit(prices, fractal.DefaultVariogramMaxLag)
+	waveletD, _ := fractal.WaveletFit(prices)
+	tests := []struct {
+		method string
+		want   float64
+		withR2 bool
+	}{
+		{"", boxD, true},
+		{"box", boxD, true},
+		{"higuchi", higuchiD, true},
+		{"katz", fractal.KatzFractalDimension(prices), false},
+		{"petrosian", fractal.PetrosianFractalDimension(prices), false},
+		{"variogram", variogramD, true},
+		{"wavelet", waveletD, true},
+	}
+	mux := newServeMux(counter)
+	for _, tt := range tests {
+		status, reply := postFractal(t, mux, http.MethodPost, `{"prices": `+string(body)+`, "method": "`+tt.method+`"}`)
+		if status != http.StatusOK {
+			t.Errorf("method %q: status %d (%v), want 200", tt.method, status, reply)
+			continue
+		}
+		if d := reply["dimension"]; d != tt.want {
+			t.Errorf("method %q: dimension %v, want %v", tt.method, d, tt.want)
+		}
+		if r2, ok := reply["r2"].(float64); ok != tt.withR2 || tt.withR2 && !(r2 > 0 && r2 <= 1) {
+			t.Errorf("method %q: r2 %v, want one %v", tt.method, reply["r2"], tt.withR2)
+		}
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		errors string // part of the error message
+	}{
+		{"GET", http.MethodGet, "", http.StatusMethodNotAllowed, "use POST"},
+		{"bad JSON", http.MethodPost, `{"prices": [1, 2,`, http.StatusBadRequest, "invalid request body"},
+		{"too few prices", http.MethodPost, `{"prices": [1, 2, 3]}`, http.StatusBadRequest, "at least 4 points"},
+		{"unknown method", http.MethodPost, `{"prices": [1, 2, 3, 4], "method": "fourier"}`, http.StatusBadRequest, `unknown method "fourier"`},
+		{"no finite dimension", http.MethodPost, `{"prices": [1, 2, 3, 4], "method": "test-nan"}`, http.StatusUnprocessableEntity, "finite dimension"},
+		{"estimator error", http.MethodPost, `{"prices": [1, 2, 3, 4], "method": "test-error"}`, http.StatusUnprocessableEntity, "series rejected"},
+	}
+	mux := newServeMux(fractal.BoxCounter{})
+	for _, tt := range tests {
+		status, reply := postFractal(t, mux, tt.method, tt.body)
+		msg, _ := reply["error"].(string)
+		if status != tt.status || !strings.Contains(msg, tt.errors) {
+			t.Errorf("%s: status %d error %q, want %d and %q", tt.name, status, msg, tt.status, tt.errors)
+		}
+	}
+}
+
+// A reply encoding/json refuses is reported as a 500 with an error body,
+// not a 200 with none.
+func TestWriteJSONUnencodable(t *testing.T) {
+	saved := slog.Default()
+	defer slog.SetDefault(saved)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	r2 := math.NaN()
+	rec := httptest.NewRecorder()
+	writeJSON(rec, http.StatusOK, fractalResponse{1.5, &r2})
+	reply := errorResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatalf("reply %q: %v", rec.Body.String(), err)
+	}
+	if rec.Code != http.StatusInternalServerError || reply.Error == "" {
+		t.Errorf("status %d error %q, want 500 and a message", rec.Code, reply.Error)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"fractal-analysis/fractal"
+)
+
+// Prints the window results and the session summary as aligned tables,
+// the -stdout counterpart of fractal_patterns.csv and session_summary.csv.
+func printTables(w io.Writer, results []fractal.FractalResult, summary []fractal.Metric, n int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Window\tStart\tEnd\tSize\tDimension\tR2\tHurst\tHurstAV\tDFA\tSampEn\tApEn\tPermEn\tLZ\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t\n",
+			windowName(r, n), r.WindowStart, r.WindowEnd, r.WindowEnd-r.WindowStart+1,
+			r.Dimension, r.R2, r.Hurst, r.HurstAggVar, r.DFA,
+			r.SampleEntropy, r.ApproxEntropy, r.PermEntropy, r.LempelZiv)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Metric\tValue")
+	for _, m := range summary {
+		fmt.Fprintf(tw, "%s\t%s\n", m.Name, m.String())
+	}
+	return tw.Flush()
+}
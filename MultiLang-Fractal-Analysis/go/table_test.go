@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// -stdout prints one table row per window and the summary, and writes no
+// files at all.
+func TestStdoutTables(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	stdout, _, err := runCommand(t, "-n", "2000", "-windows", "0:500,500:500,1000:1000", "-stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := strings.SplitN(stdout, "\n\n", 2)
+	if len(tables) != 2 {
+		t.Fatalf("stdout %q, want a window table and a summary table", stdout)
+	}
+	rows := strings.Split(strings.TrimSpace(tables[0]), "\n")
+	if header := strings.Fields(rows[0]); len(header) != 13 || header[0] != "Window" || header[4] != "Dimension" {
+		t.Errorf("window header %q", rows[0])
+	}
+	if len(rows) != 4 {
+		t.Errorf("%d window rows, want 3:\n%s", len(rows)-1, tables[0])
+	}
+	for i, want := range []string{"0", "500", "1000"} {
+		if fields := strings.Fields(rows[i+1]); len(fields) != 13 || fields[1] != want {
+			t.Errorf("row %d %q, want 13 columns starting at %s", i, rows[i+1], want)
+		}
+	}
+
+	summary := strings.Split(strings.TrimSpace(tables[1]), "\n")
+	if strings.Fields(summary[0])[0] != "Metric" || len(summary) < 10 {
+		t.Errorf("summary table %q", tables[1])
+	}
+	if !strings.Contains(tables[1], "SpectralDimension") {
+		t.Errorf("summary table has no SpectralDimension:\n%s", tables[1])
+	}
+
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("-stdout left %v in the working directory", files)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"fractal-analysis/fractal"
+)
+
+// How often -watch checks the input for new rows.
+const watchPoll = 500 * time.Millisecond
+
+// Follows a market CSV as rows are appended, like tail -f, pushing each
+// candle's price into a StreamingFractal of the given window and printing
+// the updated dimension to w as CSV rows. Rows already in the file warm
+// the window up and only the last is printed. A file that shrinks or is
+// replaced, as by log rotation, is reopened from its header and read the
+// same way, its rows continuing the window. Returns nil when ctx is
+// cancelled.
+func watchCSV(ctx context.Context, path, timeFormat string, window int, counter fractal.BoxCounter, w io.Writer) error {
+	stream := fractal.NewStreamingFractal(window, counter)
+	out := csv.NewWriter(w)
+	out.Write([]string{"Timestamp", "Price", "FractalDimension", "R2"})
+	report := func(c fractal.MarketCandle) error {
+		d, r2 := stream.Fit()
+		out.Write([]string{
+			c.Timestamp.Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%.6f", c.Price),
+			fmt.Sprintf("%.6f", d),
+			fmt.Sprintf("%.6f", r2),
+		})
+		out.Flush()
+		return out.Error()
+	}
+
+	tail, err := openTail(path, timeFormat)
+	if err != nil {
+		return err
+	}
+	defer func() { tail.file.Close() }()
+
+	warm := true // still reading what was in the file at the start
+	ticker := time.NewTicker(watchPoll)
+	defer ticker.Stop()
+	for {
+		candles, err := tail.next()
+		if err != nil {
+			return err
+		}
+		for i, c := range candles {
+			stream.Push(c.Price)
+			if !warm || i == len(candles)-1 {
+				if err := report(c); err != nil {
+					return err
+				}
+			}
+		}
+		warm = false
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		reopen, err := tail.replaced()
+		if err != nil {
+			return err
+		}
+		if reopen {
+			slog.Warn("input truncated or replaced, reopening", "input", path)
+			tail.file.Close()
+			if tail, err = openTail(path, timeFormat); err != nil {
+				return err
+			}
+			warm = true
+		}
+	}
+}
+
+// An open input being followed: the decoder built from its header and the
+// bytes consumed so far, which a shorter file size or a different file
+// at the path shows to be stale.
+type tailFile struct {
+	path    string
+	file    *os.File
+	reader  *bufio.Reader
+	decoder *fractal.CandleDecoder
+	format  string
+	offset  int64
+	partial string // an unterminated last line, held until it completes
+}
+
+func openTail(path, timeFormat string) (*tailFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &tailFile{path: path, file: file, reader: bufio.NewReader(file), format: timeFormat}, nil
+}
+
+// Candles from the complete lines appended since the last call. The header
+// is taken from the first complete line.
+func (t *tailFile) next() ([]fractal.MarketCandle, error) {
+	var candles []fractal.MarketCandle
+	for {
+		chunk, err := t.reader.ReadString('\n')
+		t.offset += int64(len(chunk))
+		if errors.Is(err, io.EOF) {
+			t.partial += chunk
+			return candles, nil
+		}
+		if err != nil {
+			return candles, err
+		}
+		line := strings.TrimRight(t.partial+chunk, "\r\n")
+		t.partial = ""
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		record, err := csv.NewReader(strings.NewReader(line)).Read()
+		if err != nil {
+			return candles, fmt.Errorf("%s: %w", t.path, err)
+		}
+		if t.decoder == nil {
+			if t.decoder, err = fractal.NewCandleDecoder(record, t.format); err != nil {
+				return candles, fmt.Errorf("%s: %w", t.path, err)
+			}
+			continue
+		}
+		c, err := t.decoder.Decode(record)
+		if err != nil {
+			// A bad row from the writer shouldn't end a long-running watch
+			slog.Warn("skipping row", "input", t.path, "error", err)
+			continue
+		}
+		candles = append(candles, c)
+	}
+}
+
+// Reports whether the path now holds a different or shorter file than the
+// one being read. A path that is briefly missing mid-rotation is not yet
+// replaced.
+func (t *tailFile) replaced() (bool, error) {
+	current, err := os.Stat(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	open, err := t.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	return !os.SameFile(open, current) || current.Size() < t.offset, nil
+}
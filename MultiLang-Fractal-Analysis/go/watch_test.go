@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"fractal-analysis/fractal"
+)
+
+// Appends rows of prices, one candle an hour from hour, to the CSV at path.
+func appendRows(t *testing.T, path string, hour int, prices ...float64) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for i, p := range prices {
+		at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(hour+i) * time.Hour)
+		fmt.Fprintf(f, "%s,%.6f\n", at.Format("2006-01-02 15:04:05"), p)
+	}
+}
+
+// Rows appended to a followed file each print an updated dimension, as
+// do the rows of a file rotated in its place.
+func TestWatchCSV(t *testing.T) {
+	path := writeTemp(t, "live.csv", "Timestamp,Price\n")
+	walk := fractal.GenerateSeries(fractal.NewRand(86, 0), 200, 100)
+	prices := make([]float64, len(walk))
+	for i, c := range walk {
+		prices[i] = c.Price
+	}
+	appendRows(t, path, 0, prices[:100]...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- watchCSV(ctx, path, "", 64, fractal.BoxCounter{}, w)
+		w.Close()
+	}()
+	lines := bufio.NewScanner(r)
+	var dims []string
+	read := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if !lines.Scan() {
+				t.Fatalf("watch output ended after %d rows: %v", len(dims), lines.Err())
+			}
+			fields := strings.Split(lines.Text(), ",")
+			if len(fields) != 4 {
+				t.Fatalf("row %q, want 4 fields", lines.Text())
+			}
+			dims = append(dims, fields[2])
+		}
+	}
+
+	read(2) // the header and the warmed-up window's last row
+	if dims[0] != "FractalDimension" {
+		t.Fatalf("header field %q, want FractalDimension", dims[0])
+	}
+	appendRows(t, path, 100, prices[100:103]...)
+	read(3)
+	for i := 2; i < len(dims); i++ {
+		if dims[i] == dims[i-1] {
+			t.Errorf("dimension %s unchanged after a new row", dims[i])
+		}
+	}
+
+	// Rotated: a new, shorter file takes its place, with the reopening
+	// warning kept out of the test output
+	saved := slog.Default()
+	defer slog.SetDefault(saved)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	rotated := path + ".new"
+	if err := os.WriteFile(rotated, []byte("Timestamp,Price\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	appendRows(t, rotated, 103, prices[103:106]...)
+	if err := os.Rename(rotated, path); err != nil {
+		t.Fatal(err)
+	}
+	read(1) // only the last of the new file's rows
+	if dims[len(dims)-1] == dims[len(dims)-2] {
+		t.Errorf("dimension %s unchanged after rotation", dims[len(dims)-1])
+	}
+
+	cancel()
+	go io.Copy(io.Discard, r)
+	if err := <-done; err != nil {
+		t.Errorf("watch returned %v after cancellation, want nil", err)
+	}
+}